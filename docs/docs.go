@@ -267,7 +267,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/delete/:id": {
+        "/api/pekerjaan/:id/purge": {
             "delete": {
                 "consumes": [
                     "application/json"
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey adalah satu kunci verifikasi (dan opsional penandatanganan) JWT.
+type JWTKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	private crypto.PrivateKey
+}
+
+// KeySet berisi kunci aktif untuk menandatangani token dan semua kunci yang
+// masih diterima saat verifikasi. Tanpa JWT_SIGNING_KEY_FILE, KeySet jatuh
+// ke mode HS256 memakai JWT_SECRET.
+type KeySet struct {
+	signing *JWTKey
+	keys    map[string]*JWTKey
+	secret  []byte
+}
+
+// JWK adalah representasi kunci publik sesuai RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keySetMu sync.RWMutex
+	keySet   *KeySet
+)
+
+var errNoJWTSecret = errors.New("JWT_SIGNING_KEY_FILE atau JWT_SECRET wajib diisi")
+
+// LoadKeySet membaca kunci dari env:
+//   - JWT_SIGNING_KEY_FILE: private key PEM (RSA atau Ed25519) untuk menandatangani
+//   - JWT_SIGNING_KEY_ID: kid kunci tersebut (default: sidik jari kunci publik)
+//   - JWT_VERIFICATION_KEYS: daftar "kid=path.pem" dipisah koma untuk kunci
+//     lama yang masih diterima selama rotasi
+//
+// Tanpa JWT_SIGNING_KEY_FILE, JWT_SECRET wajib diisi; jika keduanya kosong
+// LoadKeySet mengembalikan error agar server tidak jalan dengan secret
+// yang bisa ditebak.
+func LoadKeySet() (*KeySet, error) {
+	path := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if path == "" {
+		secret := GetJWTSecret()
+		if secret == "" {
+			return nil, errNoJWTSecret
+		}
+		log.Println("PERINGATAN: JWT_SIGNING_KEY_FILE kosong, token ditandatangani HS256 dengan JWT_SECRET")
+		return &KeySet{secret: []byte(secret)}, nil
+	}
+
+	signing, err := loadPrivateKey(path, os.Getenv("JWT_SIGNING_KEY_ID"))
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{signing: signing, keys: map[string]*JWTKey{signing.ID: signing}}
+
+	for _, entry := range strings.Split(os.Getenv("JWT_VERIFICATION_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, file, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || file == "" {
+			return nil, fmt.Errorf("JWT_VERIFICATION_KEYS tidak valid: %q", entry)
+		}
+		key, err := loadPublicKey(file, kid)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.keys[kid]; exists {
+			return nil, fmt.Errorf("kid %q terdaftar lebih dari sekali", kid)
+		}
+		ks.keys[kid] = key
+	}
+
+	return ks, nil
+}
+
+// SetKeySet memasang KeySet global yang dipakai saat menerbitkan dan
+// memverifikasi token.
+func SetKeySet(ks *KeySet) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	keySet = ks
+}
+
+// GetKeySet mengembalikan KeySet global. Jika belum dipasang, mode HS256
+// dengan JWT_SECRET dipakai agar test dan tool kecil tetap jalan; tanpa
+// JWT_SECRET, Sign dan Keyfunc selalu gagal.
+func GetKeySet() *KeySet {
+	keySetMu.RLock()
+	ks := keySet
+	keySetMu.RUnlock()
+	if ks != nil {
+		return ks
+	}
+	return &KeySet{secret: []byte(GetJWTSecret())}
+}
+
+// Sign menandatangani claims dengan kunci aktif dan mengisi header kid.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signing == nil {
+		if len(ks.secret) == 0 {
+			return "", errNoJWTSecret
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.private)
+}
+
+// Keyfunc memilih kunci verifikasi berdasarkan header kid.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if ks.signing == nil {
+		if len(ks.secret) == 0 {
+			return nil, errNoJWTSecret
+		}
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("kid tidak dikenal: %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("algoritma token tidak sesuai dengan kunci")
+	}
+	return key.Public, nil
+}
+
+// ValidMethods adalah algoritma yang diterima saat parsing token.
+func (ks *KeySet) ValidMethods() []string {
+	if ks.signing == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range ks.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS mengembalikan semua kunci publik verifikasi. Mode HS256 tidak
+// mempublikasikan apa pun.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if ks.signing == nil {
+		return set
+	}
+
+	// Kunci aktif di urutan pertama, sisanya urut kid agar respons stabil
+	set.Keys = append(set.Keys, ks.signing.jwk())
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		if kid != ks.signing.ID {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, ks.keys[kid].jwk())
+	}
+	return set
+}
+
+func (k *JWTKey) jwk() JWK {
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}
+	}
+	return JWK{Kid: k.ID}
+}
+
+func loadPrivateKey(path, kid string) (*JWTKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	if parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("private key %s tidak dikenali: %w", path, err)
+		}
+	}
+
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		return newJWTKey(kid, &priv.PublicKey, priv)
+	case ed25519.PrivateKey:
+		return newJWTKey(kid, priv.Public(), priv)
+	default:
+		return nil, fmt.Errorf("private key %s harus RSA atau Ed25519", path)
+	}
+}
+
+func loadPublicKey(path, kid string) (*JWTKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	if parsed, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		if parsed, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("public key %s tidak dikenali: %w", path, err)
+		}
+	}
+
+	return newJWTKey(kid, parsed, nil)
+}
+
+func newJWTKey(kid string, pub crypto.PublicKey, priv crypto.PrivateKey) (*JWTKey, error) {
+	var method jwt.SigningMethod
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("kunci harus RSA atau Ed25519")
+	}
+
+	if kid == "" {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(der)
+		kid = base64.RawURLEncoding.EncodeToString(sum[:12])
+	}
+
+	return &JWTKey{ID: kid, Method: method, Public: pub, private: priv}, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca kunci %s: %w", path, err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("kunci %s bukan format PEM", path)
+	}
+	return block, nil
+}
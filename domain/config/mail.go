@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// MailConfig menentukan ke mana email keluar dikirim. Driver "smtp" memakai
+// server SMTP, selain itu email ditulis ke folder outbox (untuk development).
+type MailConfig struct {
+	Driver    string
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	From      string
+	OutboxDir string
+}
+
+func GetMailConfig() MailConfig {
+	cfg := MailConfig{
+		Driver:    os.Getenv("MAIL_DRIVER"),
+		Host:      os.Getenv("SMTP_HOST"),
+		Port:      587,
+		Username:  os.Getenv("SMTP_USERNAME"),
+		Password:  os.Getenv("SMTP_PASSWORD"),
+		From:      os.Getenv("MAIL_FROM"),
+		OutboxDir: os.Getenv("MAIL_OUTBOX_DIR"),
+	}
+
+	if cfg.Driver == "" {
+		cfg.Driver = "file"
+	}
+	if p, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && p > 0 {
+		cfg.Port = p
+	}
+	if cfg.From == "" {
+		cfg.From = "no-reply@localhost"
+	}
+	if cfg.OutboxDir == "" {
+		cfg.OutboxDir = "./outbox"
+	}
+
+	return cfg
+}
+
+// GetAppBaseURL dipakai untuk menyusun tautan di dalam email
+// (APP_BASE_URL, default http://localhost:3000).
+func GetAppBaseURL() string {
+	url := os.Getenv("APP_BASE_URL")
+	if url == "" {
+		url = "http://localhost:3000"
+	}
+	return url
+}
+
+// GetPasswordResetExpiry mengembalikan masa berlaku token reset password
+// (PASSWORD_RESET_EXPIRE_MINUTES, default 60 menit).
+func GetPasswordResetExpiry() time.Duration {
+	return durationFromEnv("PASSWORD_RESET_EXPIRE_MINUTES", time.Minute, time.Hour)
+}
+
+// GetEmailVerificationExpiry mengembalikan masa berlaku tautan verifikasi email
+// (EMAIL_VERIFICATION_EXPIRE_HOURS, default 48 jam).
+func GetEmailVerificationExpiry() time.Duration {
+	return durationFromEnv("EMAIL_VERIFICATION_EXPIRE_HOURS", time.Hour, 48*time.Hour)
+}
@@ -3,18 +3,28 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+// GetJWTSecret mengembalikan JWT_SECRET. Tidak ada secret bawaan: string
+// kosong berarti mode HS256 tidak dikonfigurasi.
 func GetJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default_secret_change_me"
-	}
-	return secret
+	return os.Getenv("JWT_SECRET")
 }
 
+// GetJWTExpiry mengembalikan masa berlaku access token. JWT_EXPIRE_MINUTES
+// dipakai lebih dulu agar access token bisa dibuat singkat, lalu
+// JWT_EXPIRE_HOURS, dan default 24 jam.
 func GetJWTExpiry() time.Duration {
+	if m := os.Getenv("JWT_EXPIRE_MINUTES"); m != "" {
+		if mi, err := strconv.Atoi(m); err == nil && mi > 0 {
+			return time.Duration(mi) * time.Minute
+		}
+	}
+
 	h := os.Getenv("JWT_EXPIRE_HOURS")
 	if h == "" {
 		return 24 * time.Hour
@@ -25,3 +35,155 @@ func GetJWTExpiry() time.Duration {
 	}
 	return time.Duration(hi) * time.Hour
 }
+
+// GetRefreshTokenExpiry mengembalikan masa berlaku refresh token
+// (REFRESH_TOKEN_EXPIRE_HOURS, default 7 hari).
+func GetRefreshTokenExpiry() time.Duration {
+	return durationFromEnv("REFRESH_TOKEN_EXPIRE_HOURS", time.Hour, 7*24*time.Hour)
+}
+
+// GetInviteExpiry mengembalikan masa berlaku undangan admin
+// (INVITE_EXPIRE_HOURS, default 72 jam).
+func GetInviteExpiry() time.Duration {
+	return durationFromEnv("INVITE_EXPIRE_HOURS", time.Hour, 72*time.Hour)
+}
+
+// GetImpersonationExpiry mengembalikan masa berlaku token impersonasi
+// (IMPERSONATION_EXPIRE_MINUTES, default 15 menit).
+func GetImpersonationExpiry() time.Duration {
+	return durationFromEnv("IMPERSONATION_EXPIRE_MINUTES", time.Minute, 15*time.Minute)
+}
+
+// GetAPIKeyExpiry mengembalikan masa berlaku bawaan API key
+// (API_KEY_EXPIRE_DAYS, default 90 hari).
+func GetAPIKeyExpiry() time.Duration {
+	return durationFromEnv("API_KEY_EXPIRE_DAYS", 24*time.Hour, 90*24*time.Hour)
+}
+
+// durationFromEnv membaca bilangan bulat positif dari env dan mengalikannya
+// dengan unit. Nilai kosong atau tidak valid memakai def.
+func durationFromEnv(key string, unit, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return def
+	}
+	return time.Duration(n) * unit
+}
+
+// LoginThrottle mengatur batas percobaan login yang gagal.
+type LoginThrottle struct {
+	// MaxAccountFailures dan MaxIPFailures adalah jumlah kegagalan sebelum
+	// email atau IP dikunci sementara.
+	MaxAccountFailures int
+	MaxIPFailures      int
+	// Window adalah rentang waktu sebelum hitungan kegagalan dimulai ulang.
+	Window time.Duration
+	// Lockout adalah lama penguncian setelah batas terlampaui.
+	Lockout time.Duration
+	// BaseDelay adalah jeda setelah kegagalan pertama; jeda berikutnya
+	// berlipat dua sampai MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func GetLoginThrottle() LoginThrottle {
+	return LoginThrottle{
+		MaxAccountFailures: intFromEnv("LOGIN_MAX_ATTEMPTS", 5),
+		MaxIPFailures:      intFromEnv("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		Window:             durationFromEnv("LOGIN_ATTEMPT_WINDOW_MINUTES", time.Minute, 15*time.Minute),
+		Lockout:            durationFromEnv("LOGIN_LOCKOUT_MINUTES", time.Minute, 15*time.Minute),
+		BaseDelay:          durationFromEnv("LOGIN_BASE_DELAY_SECONDS", time.Second, time.Second),
+		MaxDelay:           durationFromEnv("LOGIN_MAX_DELAY_SECONDS", time.Second, 30*time.Second),
+	}
+}
+
+// TwoFactorConfig mengatur TOTP dan tiket langkah kedua login.
+type TwoFactorConfig struct {
+	// Issuer tampil di aplikasi authenticator.
+	Issuer string
+	// RequiredForAdmin mewajibkan 2FA untuk role admin; admin yang belum
+	// mendaftar harus menyelesaikan setup sebelum mendapat token.
+	RequiredForAdmin bool
+	// ChallengeExpiry adalah masa berlaku mfa_token dari langkah pertama.
+	ChallengeExpiry time.Duration
+}
+
+func GetTwoFactorConfig() TwoFactorConfig {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "Alumni Management"
+	}
+	required, _ := strconv.ParseBool(os.Getenv("TWO_FACTOR_REQUIRED_FOR_ADMIN"))
+
+	return TwoFactorConfig{
+		Issuer:           issuer,
+		RequiredForAdmin: required,
+		ChallengeExpiry:  durationFromEnv("TWO_FACTOR_CHALLENGE_MINUTES", time.Minute, 5*time.Minute),
+	}
+}
+
+// OIDCConfig mengatur login SSO lewat identity provider kampus. SSO aktif
+// jika IssuerURL dan ClientID diisi.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL adalah URL callback yang didaftarkan di identity provider.
+	RedirectURL string
+	Scopes      []string
+	// StateExpiry adalah batas waktu antara redirect ke provider dan callback.
+	StateExpiry time.Duration
+}
+
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != "" && c.ClientID != ""
+}
+
+func GetOIDCConfig() OIDCConfig {
+	scopes := []string{"openid", "email", "profile"}
+	if v := os.Getenv("OIDC_SCOPES"); v != "" {
+		scopes = strings.Fields(strings.ReplaceAll(v, ",", " "))
+	}
+
+	return OIDCConfig{
+		IssuerURL:    os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       scopes,
+		StateExpiry:  durationFromEnv("OIDC_STATE_EXPIRE_MINUTES", time.Minute, 10*time.Minute),
+	}
+}
+
+// PasswordPolicy mengatur syarat password yang dipilih user dan biaya
+// bcrypt untuk hash baru.
+type PasswordPolicy struct {
+	MinLength int
+	// BcryptCost dipakai untuk hash baru; hash lama dengan cost lebih rendah
+	// diperbarui saat user berhasil login.
+	BcryptCost int
+}
+
+func GetPasswordPolicy() PasswordPolicy {
+	cost := intFromEnv("BCRYPT_COST", bcrypt.DefaultCost)
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+
+	return PasswordPolicy{
+		MinLength:  intFromEnv("PASSWORD_MIN_LENGTH", 8),
+		BcryptCost: cost,
+	}
+}
+
+func intFromEnv(key string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
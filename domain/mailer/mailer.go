@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"Mongo/domain/config"
+	"fmt"
+)
+
+// Message adalah email teks sederhana yang dikirim aplikasi.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email keluar. Implementasinya bisa SMTP atau outbox file.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New memilih implementasi Mailer berdasarkan cfg.Driver.
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.Host == "" {
+			return nil, fmt.Errorf("SMTP_HOST wajib diisi untuk MAIL_DRIVER=smtp")
+		}
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileOutbox(cfg.OutboxDir, cfg.From)
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER tidak dikenal: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileOutbox menulis setiap email sebagai file .eml di satu folder, dipakai
+// saat development dan pengujian agar tidak perlu server SMTP.
+type FileOutbox struct {
+	dir  string
+	from string
+	mu   sync.Mutex
+	seq  int
+}
+
+func NewFileOutbox(dir, from string) (*FileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("gagal membuat folder outbox: %w", err)
+	}
+	return &FileOutbox{dir: dir, from: from}, nil
+}
+
+func (o *FileOutbox) Send(msg Message) error {
+	o.mu.Lock()
+	o.seq++
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102T150405.000000000"), o.seq)
+	o.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(o.dir, name), formatMessage(o.from, msg), 0o600)
+}
+
+// Messages mengembalikan path semua email di outbox, terurut dari yang tertua.
+func (o *FileOutbox) Messages() ([]string, error) {
+	return filepath.Glob(filepath.Join(o.dir, "*.eml"))
+}
@@ -2,16 +2,41 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
 	"Mongo/domain/config"
 	"Mongo/domain/model"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func JWTAuth(userRepo *model.UserRepository) fiber.Handler {
+// AuthDeps berisi repository yang dibutuhkan JWTAuth untuk membangun principal.
+type AuthDeps struct {
+	Users model.UserRepository
+	Roles model.RoleRepository
+	// Sessions opsional; jika diisi, token wajib membawa klaim sid yang
+	// menunjuk sesi yang belum dicabut.
+	Sessions model.SessionRepository
+	// APIKeys opsional; jika diisi, header X-API-Key diterima sebagai
+	// pengganti bearer token.
+	APIKeys model.APIKeyRepository
+	// Audit wajib diisi agar token impersonasi diterima; setiap request
+	// impersonasi dicatat di sini.
+	Audit model.AuditRepository
+}
+
+// touchInterval membatasi seberapa sering last_seen_at sesi dan
+// last_used_at API key ditulis.
+const touchInterval = time.Minute
+
+func JWTAuth(auth *AuthDeps) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get(model.APIKeyHeader); apiKey != "" && auth.APIKeys != nil {
+			return apiKeyAuth(c, auth.APIKeys, apiKey)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authorization header required"})
@@ -25,42 +50,177 @@ func JWTAuth(userRepo *model.UserRepository) fiber.Handler {
 
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-
-			return []byte(config.GetJWTSecret()), nil
-		})
+		// 2. Parse ke claims bertipe; kunci dipilih lewat header kid dan hanya
+		// algoritma dari key set yang diterima
+		keys := config.GetKeySet()
+		claims := new(model.Claims)
+		token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc, jwt.WithValidMethods(keys.ValidMethods()))
 		if err != nil || !token.Valid {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		// 3. Subject wajib berupa ObjectID user
+		userID, err := primitive.ObjectIDFromHex(claims.Subject)
+		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token claims"})
 		}
 
-		var userID float64
-		var role string
-		var username string
+		// 4. Akun harus masih ada, aktif, dan sudah terverifikasi
+		user, err := auth.Users.FindByID(userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat user"})
+		}
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+		}
+		if user.IsSuspended() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+		}
+		if !user.IsVerified() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "email belum diverifikasi"})
+		}
+		// Token lama tidak berlaku lagi setelah password diganti
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		if user.TokenPredatesPasswordChange(issuedAt) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+		}
+
+		// 5. Token impersonasi tidak terikat sesi; admin aslinya harus masih
+		// aktif dan masih memegang users:impersonate
+		var impersonator *model.Users
+		if claims.Actor != nil {
+			if auth.Audit == nil {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "impersonasi tidak tersedia"})
+			}
+			impersonator, err = loadImpersonator(auth, claims.Actor)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat user"})
+			}
+			if impersonator == nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+			}
+		}
 
-		if v, ok := claims["sub"].(float64); ok {
-			userID = v
-		} else if v, ok := claims["id"].(float64); ok {
-			userID = v
+		// 6. Sesi yang dicabut dari daftar sesi langsung mematikan access token-nya
+		if impersonator == nil && auth.Sessions != nil {
+			session, err := auth.Sessions.FindByID(claims.SessionID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat sesi"})
+			}
+			if session == nil || !session.IsActive() || session.UserID != userID {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "sesi sudah berakhir"})
+			}
+			if now := time.Now(); now.Sub(session.LastSeenAt) > touchInterval {
+				_ = auth.Sessions.Touch(session.ID, now, c.IP())
+			}
 		}
 
-		if r, exists := claims["role"].(string); exists {
-			role = r
+		// 7. Role diambil dari data user, bukan dari klaim token, dan
+		// permission-nya dari koleksi roles agar perubahan role langsung berlaku
+		var permissions []string
+		role, err := auth.Roles.FindByName(user.Role)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat role"})
 		}
-		if u, exists := claims["username"].(string); exists {
-			username = u
+		if role != nil {
+			permissions = role.Permissions
 		}
 
-		if userID != 0 {
-			c.Locals("id", int(userID))
+		principal := &model.Principal{
+			UserID:      userID,
+			Role:        user.Role,
+			Username:    claims.Username,
+			NIM:         claims.NIM,
+			SessionID:   claims.SessionID,
+			Permissions: permissions,
+		}
+		if impersonator != nil {
+			principal.ImpersonatorID = impersonator.ID
+			return impersonatedRequest(c, auth.Audit, principal)
 		}
-		c.Locals("role", role)
-		c.Locals("username", username)
 
+		SetPrincipal(c, principal)
 		return c.Next()
 	}
 }
+
+// loadImpersonator memuat admin di klaim act. Mengembalikan nil jika admin
+// tidak ada, dinonaktifkan, atau sudah tidak memegang users:impersonate.
+func loadImpersonator(auth *AuthDeps, actor *model.ActorClaim) (*model.Users, error) {
+	actorID, err := primitive.ObjectIDFromHex(actor.Subject)
+	if err != nil {
+		return nil, nil
+	}
+
+	user, err := auth.Users.FindByID(actorID)
+	if err != nil || user == nil || user.IsSuspended() {
+		return nil, err
+	}
+
+	role, err := auth.Roles.FindByName(user.Role)
+	if err != nil || role == nil {
+		return nil, err
+	}
+	if !(&model.Principal{Permissions: role.Permissions}).HasPermission(model.PermUsersImpersonate) {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// impersonatedRequest hanya meneruskan request baca dan mencatat setiap
+// request ke audit log sebelum dijalankan. Jika audit gagal ditulis,
+// request ditolak.
+func impersonatedRequest(c *fiber.Ctx, audit model.AuditRepository, principal *model.Principal) error {
+	readOnly := c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead
+
+	entry := &model.AuditLog{
+		ActorID:    principal.ImpersonatorID,
+		Action:     model.AuditImpersonatedRequest,
+		TargetType: "user",
+		TargetID:   principal.UserID.Hex(),
+		Details: map[string]interface{}{
+			"method":  c.Method(),
+			"path":    c.OriginalURL(),
+			"allowed": readOnly,
+		},
+		IP: c.IP(),
+	}
+	if err := audit.Create(entry); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menulis audit log"})
+	}
+
+	if !readOnly {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "aksi ini tidak diizinkan selama impersonasi"})
+	}
+
+	SetPrincipal(c, principal)
+	return c.Next()
+}
+
+// apiKeyAuth mengautentikasi integrasi mesin. Permission principal hanya
+// yang tercantum di key, tidak mengikuti role pembuatnya.
+func apiKeyAuth(c *fiber.Ctx, repo model.APIKeyRepository, rawKey string) error {
+	key, err := repo.FindByHash(model.HashAPIKey(rawKey))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat API key"})
+	}
+	now := time.Now()
+	if key == nil || !key.IsUsable(now) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "API key tidak valid atau sudah kedaluwarsa"})
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval {
+		_ = repo.TouchLastUsed(key.ID, now)
+	}
+
+	SetPrincipal(c, &model.Principal{
+		Role:        "api_key",
+		Username:    key.Name,
+		APIKeyID:    key.ID,
+		Permissions: model.APIKeyPermissions(key.Permissions),
+	})
+
+	return c.Next()
+}
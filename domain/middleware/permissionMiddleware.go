@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission mengizinkan request hanya jika principal memiliki
+// semua permission yang diminta.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, ok := GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+
+		for _, perm := range permissions {
+			if !p.HasPermission(perm) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden: missing permission " + perm})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// RequireUser menolak pemanggil yang masuk lewat API key, untuk route yang
+// hanya boleh dijalankan oleh user.
+func RequireUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, ok := GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		if p.IsAPIKey() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden: API key tidak dapat mengakses route ini"})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"Mongo/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const principalKey = "principal"
+
+// SetPrincipal menyimpan identitas pemanggil ke context request.
+func SetPrincipal(c *fiber.Ctx, p *model.Principal) {
+	c.Locals(principalKey, p)
+}
+
+// GetPrincipal adalah satu-satunya cara handler membaca identitas pemanggil.
+func GetPrincipal(c *fiber.Ctx) (*model.Principal, bool) {
+	p, ok := c.Locals(principalKey).(*model.Principal)
+	if !ok || p == nil || (p.UserID.IsZero() && !p.IsAPIKey()) {
+		return nil, false
+	}
+	return p, true
+}
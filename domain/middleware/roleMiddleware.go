@@ -6,14 +6,16 @@ import (
 
 func RequireRole(allowedRoles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		r := c.Locals("role")
-		roleStr, _ := r.(string)
+		p, ok := GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
 
 		for _, ar := range allowedRoles {
-			if roleStr == ar {
+			if p.Role == ar {
 				return c.Next()
 			}
 		}
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden: insufficient role"})
 	}
-}
\ No newline at end of file
+}
@@ -9,11 +9,14 @@ import (
 type Uploads struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	UploadsName  string             `json:"Uploads_name" bson:"Uploads_name"`
-	OriginalName string             `json:"original_name"bson:"original_name"`
+	OriginalName string             `json:"original_name" bson:"original_name"`
 	UploadsPath  string             `json:"Uploads_path" bson:"Uploads_path"`
 	UploadsSize  int64              `json:"Uploads_size" bson:"Uploads_size"`
 	UploadsType  string             `json:"Uploads_type" bson:"Uploads_type"`
 	UploadedAt   time.Time          `json:"uploaded_at" bson:"uploaded_at"`
+	// UploadedBy adalah user pengunggah; file hanya bisa dibaca pemiliknya
+	// dan peninjau klaim alumni.
+	UploadedBy primitive.ObjectID `json:"uploaded_by" bson:"uploaded_by,omitempty"`
 }
 
 type UploadsResponse struct {
@@ -24,4 +27,5 @@ type UploadsResponse struct {
 	UploadsSize  int64     `json:"Uploads_size"`
 	UploadsType  string    `json:"Uploads_type"`
 	UploadedAt   time.Time `json:"uploaded_at"`
+	UploadedBy   string    `json:"uploaded_by,omitempty"`
 }
@@ -20,10 +20,79 @@ type Alumni struct {
 	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
+// SaveAlumni adalah body untuk membuat atau mengubah alumni. user_id
+// sengaja tidak ada: tautan ke akun hanya ditulis lewat LinkUser setelah
+// klaim alumni disetujui.
+type SaveAlumni struct {
+	NIM        string  `json:"nim"`
+	Nama       string  `json:"nama"`
+	Angkatan   *int    `json:"angkatan"`
+	TahunLulus *int    `json:"tahun_lulus"`
+	IDFakultas *int    `json:"id_fakultas"`
+	IDProdi    *int    `json:"id_prodi"`
+	IDSumber   *int    `json:"id_sumber"`
+	Sumber     *string `json:"sumber"`
+}
+
+func (b SaveAlumni) Alumni() Alumni {
+	return Alumni{
+		NIM:        b.NIM,
+		Nama:       b.Nama,
+		Angkatan:   b.Angkatan,
+		TahunLulus: b.TahunLulus,
+		IDFakultas: b.IDFakultas,
+		IDProdi:    b.IDProdi,
+		IDSumber:   b.IDSumber,
+		Sumber:     b.Sumber,
+	}
+}
+
+// AlumniFilter adalah filter daftar alumni. Slice kosong dan pointer nil
+// berarti tidak difilter; rentang tahun bersifat inklusif.
+type AlumniFilter struct {
+	Search         string
+	Angkatan       []int
+	AngkatanFrom   *int
+	AngkatanTo     *int
+	TahunLulus     []int
+	TahunLulusFrom *int
+	TahunLulusTo   *int
+	IDFakultas     []int
+	IDProdi        []int
+	IDSumber       []int
+	Sumber         []string
+}
+
 type AlumniRepository interface {
 	CheckAlumniByNim(nim string) (*Alumni, error)
+	FindByUserID(userID primitive.ObjectID) (*Alumni, error)
+	// LinkUser menautkan alumni ke akun user secara atomik. Mengembalikan
+	// false jika NIM tidak ada atau sudah tertaut ke akun lain.
+	LinkUser(nim string, userID primitive.ObjectID) (bool, error)
+	// UnlinkUser melepas tautan alumni dari akun user yang dihapus agar NIM
+	// bisa diklaim lagi.
+	UnlinkUser(userID primitive.ObjectID) error
+	// RenameSumber menyamakan nama sumber yang tersimpan di data alumni
+	// setelah master sumber diganti namanya.
+	RenameSumber(idSumber int, nama string) error
 	CreateAlumni(alumni *Alumni) error
+	// UpdateAlumni mengubah data alumni dengan NIM tersebut tanpa mengganti
+	// NIM-nya. Mengembalikan mongo.ErrNoDocuments jika NIM tidak ada.
 	UpdateAlumni(nim string, alumni *Alumni) error
 	DeleteAlumni(nim string) error
-	GetAllAlumni() ([]Alumni, error)
+	// GetAllAlumni mengambil satu halaman alumni yang cocok dengan filter.
+	// CountAlumni menghitung total dengan filter yang sama.
+	GetAllAlumni(filter AlumniFilter, sortBy, order string, limit, offset int) ([]Alumni, error)
+	CountAlumni(filter AlumniFilter) (int, error)
+	// OpenAlumniCursor membuka cursor dengan filter dan urutan yang sama
+	// seperti GetAllAlumni, tanpa paginasi. Pemanggil wajib menutupnya.
+	OpenAlumniCursor(filter AlumniFilter, sortBy, order string) (Cursor, error)
+	// ExistingNIMs mengembalikan NIM dari daftar yang sudah ada di database.
+	ExistingNIMs(nims []string) ([]string, error)
+	// UpsertAlumni membuat alumni baru atau mengubah alumni dengan NIM yang
+	// sama. Hanya field di columns yang ditulis agar kolom yang tidak ada di
+	// file import tidak terhapus. created bernilai true jika dokumen baru.
+	UpsertAlumni(alumni *Alumni, columns []string) (created bool, err error)
+	// EnsureIndexes membuat index yang dipakai query daftar alumni.
+	EnsureIndexes() error
 }
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status klaim NIM.
+const (
+	ClaimStatusPending  = "pending"
+	ClaimStatusApproved = "approved"
+	ClaimStatusRejected = "rejected"
+)
+
+// AlumniClaim adalah permintaan user untuk dihubungkan ke data alumni
+// dengan NIM tertentu. Tautan Alumni.UserID baru dibuat setelah admin
+// menyetujui klaim.
+type AlumniClaim struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID primitive.ObjectID `bson:"user_id" json:"user_id"`
+	NIM    string             `bson:"nim" json:"nim"`
+	// TahunLulus dan DocumentID adalah bukti opsional dari user; DocumentID
+	// menunjuk file yang diunggah lewat /api/upload.
+	TahunLulus   *int                `bson:"tahun_lulus,omitempty" json:"tahun_lulus,omitempty"`
+	DocumentID   *primitive.ObjectID `bson:"document_id,omitempty" json:"document_id,omitempty"`
+	Note         string              `bson:"note,omitempty" json:"note,omitempty"`
+	Status       string              `bson:"status" json:"status"`
+	ReviewedBy   *primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time          `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	RejectReason string              `bson:"reject_reason,omitempty" json:"reject_reason,omitempty"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type CreateAlumniClaim struct {
+	NIM        string `json:"nim"`
+	TahunLulus *int   `json:"tahun_lulus"`
+	DocumentID string `json:"document_id"`
+	Note       string `json:"note"`
+}
+
+type ReviewAlumniClaim struct {
+	Reason string `json:"reason"`
+}
+
+type AlumniClaimRepository interface {
+	Create(claim *AlumniClaim) error
+	FindByID(id primitive.ObjectID) (*AlumniClaim, error)
+	// FindLatestByUser mengembalikan klaim terbaru milik user, atau nil.
+	FindLatestByUser(userID primitive.ObjectID) (*AlumniClaim, error)
+	// FindByStatus mengembalikan klaim dengan status tersebut, terlama
+	// dulu; status kosong berarti semua klaim.
+	FindByStatus(status string) ([]AlumniClaim, error)
+	// Review memindahkan klaim pending ke approved atau rejected secara
+	// atomik. Mengembalikan false jika klaim sudah tidak pending.
+	Review(id primitive.ObjectID, status string, reviewer primitive.ObjectID, reason string) (bool, error)
+	// Reopen mengembalikan klaim ke pending jika penautan gagal setelah
+	// Review.
+	Reopen(id primitive.ObjectID) error
+}
@@ -0,0 +1,25 @@
+package model
+
+// AlumniImportColumns adalah kolom file import yang dikenali. Nama kolom
+// sama dengan nama field alumni di database.
+var AlumniImportColumns = []string{"nim", "nama", "angkatan", "tahun_lulus", "id_fakultas", "id_prodi", "id_sumber", "sumber"}
+
+// AlumniImportRowError berisi kesalahan satu baris. Row adalah nomor baris
+// di file, dengan baris header sebagai baris 1.
+type AlumniImportRowError struct {
+	Row    int      `json:"row"`
+	NIM    string   `json:"nim,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+// AlumniImportResult adalah ringkasan import. Pada dry run, Created dan
+// Updated berisi jumlah yang akan dibuat atau diubah.
+type AlumniImportResult struct {
+	DryRun         bool                   `json:"dry_run"`
+	TotalRows      int                    `json:"total_rows"`
+	Created        int                    `json:"created"`
+	Updated        int                    `json:"updated"`
+	Skipped        int                    `json:"skipped"`
+	IgnoredColumns []string               `json:"ignored_columns,omitempty"`
+	Errors         []AlumniImportRowError `json:"errors"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKeyHeader adalah header tempat integrasi mesin mengirim API key.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey adalah kredensial untuk integrasi antar-sistem. Key asli hanya
+// ditampilkan sekali saat dibuat; database menyimpan hash-nya dan Prefix
+// untuk mengenali key di daftar.
+type APIKey struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string              `bson:"name" json:"name"`
+	Prefix      string              `bson:"prefix" json:"prefix"`
+	KeyHash     string              `bson:"key_hash" json:"-"`
+	Permissions []string            `bson:"permissions" json:"permissions"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	ExpiresAt   time.Time           `bson:"expires_at" json:"expires_at"`
+	LastUsedAt  *time.Time          `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time          `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	RevokedBy   *primitive.ObjectID `bson:"revoked_by,omitempty" json:"revoked_by,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// IsUsable bernilai true jika key belum dicabut dan belum kedaluwarsa.
+func (k *APIKey) IsUsable(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+// HashAPIKey menghasilkan hash yang disimpan dan dicari di database.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type CreateAPIKey struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	// ExpiresInDays opsional; kosong berarti memakai API_KEY_EXPIRE_DAYS.
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	FindByHash(hash string) (*APIKey, error)
+	FindAll() ([]APIKey, error)
+	// Revoke mencabut key secara atomik. Mengembalikan false jika key tidak
+	// ada atau sudah dicabut.
+	Revoke(id, revokedBy primitive.ObjectID) (bool, error)
+	TouchLastUsed(id primitive.ObjectID, usedAt time.Time) error
+}
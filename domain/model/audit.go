@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Aksi yang dicatat di audit log.
+const (
+	AuditUserUpdated         = "user.updated"
+	AuditUserRoleChanged     = "user.role_changed"
+	AuditUserSuspended       = "user.suspended"
+	AuditUserReactivated     = "user.reactivated"
+	AuditUserDeleted         = "user.deleted"
+	AuditUserSessionsRevoked = "user.sessions_revoked"
+	// AuditImpersonationStarted dicatat saat token impersonasi diterbitkan,
+	// AuditImpersonatedRequest untuk setiap request yang memakai token itu.
+	AuditImpersonationStarted = "user.impersonation_started"
+	AuditImpersonatedRequest  = "user.impersonated_request"
+)
+
+// AuditLog mencatat siapa melakukan perubahan apa terhadap data siapa.
+type AuditLog struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id,omitempty"`
+	ActorID    primitive.ObjectID     `bson:"actor_id" json:"actor_id"`
+	Action     string                 `bson:"action" json:"action"`
+	TargetType string                 `bson:"target_type" json:"target_type"`
+	TargetID   string                 `bson:"target_id" json:"target_id"`
+	Details    map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	IP         string                 `bson:"ip,omitempty" json:"ip,omitempty"`
+	CreatedAt  time.Time              `bson:"created_at" json:"created_at"`
+}
+
+type AuditRepository interface {
+	Create(entry *AuditLog) error
+}
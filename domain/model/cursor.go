@@ -0,0 +1,12 @@
+package model
+
+import "context"
+
+// Cursor membaca hasil query satu dokumen per iterasi sehingga data besar
+// tidak perlu dimuat sekaligus. Dipenuhi oleh *mongo.Cursor.
+type Cursor interface {
+	Next(ctx context.Context) bool
+	Decode(val interface{}) error
+	Err() error
+	Close(ctx context.Context) error
+}
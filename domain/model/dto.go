@@ -0,0 +1,196 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DTO di file ini adalah bentuk data yang boleh keluar dari API. Handler
+// tidak mengirim struct model secara langsung agar field sensitif (hash
+// password, relasi akun, gaji) tidak ikut terserialisasi.
+
+// PublicUser adalah tampilan user yang aman untuk pemanggil mana pun.
+type PublicUser struct {
+	ID       primitive.ObjectID `json:"id"`
+	Username string             `json:"username"`
+	Role     string             `json:"role"`
+}
+
+// AdminUser adalah tampilan lengkap user untuk admin dan untuk pemilik
+// akun itu sendiri. Hash password tidak pernah disertakan.
+type AdminUser struct {
+	ID           primitive.ObjectID `json:"id"`
+	Email        string             `json:"email"`
+	PendingEmail string             `json:"pending_email,omitempty"`
+	Username     string             `json:"username"`
+	Role         string             `json:"role"`
+	Status       string             `json:"status,omitempty"`
+	VerifiedAt   *time.Time         `json:"verified_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+func NewPublicUser(u *Users) PublicUser {
+	return PublicUser{ID: u.ID, Username: u.Username, Role: u.Role}
+}
+
+func NewAdminUser(u *Users) AdminUser {
+	return AdminUser{
+		ID:           u.ID,
+		Email:        u.Email,
+		PendingEmail: u.PendingEmail,
+		Username:     u.Username,
+		Role:         u.Role,
+		Status:       u.Status,
+		VerifiedAt:   u.VerifiedAt,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func NewAdminUsers(users []Users) []AdminUser {
+	result := make([]AdminUser, 0, len(users))
+	for i := range users {
+		result = append(result, NewAdminUser(&users[i]))
+	}
+	return result
+}
+
+// PublicAlumni menyembunyikan relasi ke akun user dan sumber data.
+type PublicAlumni struct {
+	NIM        string `json:"nim"`
+	Nama       string `json:"nama"`
+	Angkatan   *int   `json:"angkatan"`
+	TahunLulus *int   `json:"tahun_lulus"`
+	IDFakultas *int   `json:"id_fakultas"`
+	IDProdi    *int   `json:"id_prodi"`
+	// Nama master data hanya terisi jika diminta lewat ?expand=true.
+	NamaFakultas string `json:"nama_fakultas,omitempty"`
+	NamaProdi    string `json:"nama_prodi,omitempty"`
+}
+
+// AdminAlumni adalah tampilan lengkap alumni untuk pemegang alumni:write.
+type AdminAlumni struct {
+	UserID     primitive.ObjectID `json:"user_id,omitempty"`
+	NIM        string             `json:"nim"`
+	Nama       string             `json:"nama"`
+	Angkatan   *int               `json:"angkatan"`
+	TahunLulus *int               `json:"tahun_lulus"`
+	IDFakultas *int               `json:"id_fakultas"`
+	IDProdi    *int               `json:"id_prodi"`
+	IDSumber   *int               `json:"id_sumber"`
+	Sumber     *string            `json:"sumber"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	// Nama master data hanya terisi jika diminta lewat ?expand=true.
+	NamaFakultas string `json:"nama_fakultas,omitempty"`
+	NamaProdi    string `json:"nama_prodi,omitempty"`
+	NamaSumber   string `json:"nama_sumber,omitempty"`
+}
+
+func NewPublicAlumni(a *Alumni) PublicAlumni {
+	return PublicAlumni{
+		NIM:        a.NIM,
+		Nama:       a.Nama,
+		Angkatan:   a.Angkatan,
+		TahunLulus: a.TahunLulus,
+		IDFakultas: a.IDFakultas,
+		IDProdi:    a.IDProdi,
+	}
+}
+
+// Expand mengisi nama fakultas dan prodi dari master data.
+func (p *PublicAlumni) Expand(names *MasterNames) {
+	p.NamaFakultas = lookupName(names.Fakultas, p.IDFakultas)
+	p.NamaProdi = lookupName(names.Prodi, p.IDProdi)
+}
+
+// Expand mengisi nama fakultas, prodi, dan sumber dari master data.
+func (a *AdminAlumni) Expand(names *MasterNames) {
+	a.NamaFakultas = lookupName(names.Fakultas, a.IDFakultas)
+	a.NamaProdi = lookupName(names.Prodi, a.IDProdi)
+	a.NamaSumber = lookupName(names.Sumber, a.IDSumber)
+}
+
+func NewAdminAlumni(a *Alumni) AdminAlumni {
+	return AdminAlumni{
+		UserID:     a.UserID,
+		NIM:        a.NIM,
+		Nama:       a.Nama,
+		Angkatan:   a.Angkatan,
+		TahunLulus: a.TahunLulus,
+		IDFakultas: a.IDFakultas,
+		IDProdi:    a.IDProdi,
+		IDSumber:   a.IDSumber,
+		Sumber:     a.Sumber,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+// PublicPekerjaan menyembunyikan gaji dari alumni lain.
+type PublicPekerjaan struct {
+	ID            primitive.ObjectID `json:"id"`
+	NimAlumni     string             `json:"nim_alumni"`
+	StatusKerja   string             `json:"status_kerja"`
+	JenisIndustri string             `json:"jenis_industri"`
+	Jabatan       string             `json:"jabatan"`
+	Pekerjaan     string             `json:"pekerjaan"`
+	LamaBekerja   int                `json:"lama_bekerja"`
+}
+
+// AdminPekerjaan adalah tampilan lengkap untuk pemilik data dan pemegang
+// pekerjaan:manage_all.
+type AdminPekerjaan struct {
+	ID            primitive.ObjectID `json:"id"`
+	NimAlumni     string             `json:"nim_alumni"`
+	StatusKerja   string             `json:"status_kerja"`
+	JenisIndustri string             `json:"jenis_industri"`
+	Jabatan       string             `json:"jabatan"`
+	Pekerjaan     string             `json:"pekerjaan"`
+	Gaji          int                `json:"gaji"`
+	LamaBekerja   int                `json:"lama_bekerja"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+func NewPublicPekerjaan(p *PekerjaanAlumni) PublicPekerjaan {
+	return PublicPekerjaan{
+		ID:            p.ID,
+		NimAlumni:     p.NimAlumni,
+		StatusKerja:   p.StatusKerja,
+		JenisIndustri: p.JenisIndustri,
+		Jabatan:       p.Jabatan,
+		Pekerjaan:     p.Pekerjaan,
+		LamaBekerja:   p.LamaBekerja,
+	}
+}
+
+func NewAdminPekerjaan(p *PekerjaanAlumni) AdminPekerjaan {
+	return AdminPekerjaan{
+		ID:            p.ID,
+		NimAlumni:     p.NimAlumni,
+		StatusKerja:   p.StatusKerja,
+		JenisIndustri: p.JenisIndustri,
+		Jabatan:       p.Jabatan,
+		Pekerjaan:     p.Pekerjaan,
+		Gaji:          p.Gaji,
+		LamaBekerja:   p.LamaBekerja,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+// SessionInfo adalah sesi aktif beserta penanda sesi yang sedang dipakai
+// pemanggil.
+type SessionInfo struct {
+	Session
+	Current bool `json:"current"`
+}
+
+func NewSessionInfos(sessions []Session, currentID string) []SessionInfo {
+	result := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionInfo{Session: session, Current: currentID != "" && session.ID == currentID})
+	}
+	return result
+}
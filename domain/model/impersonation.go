@@ -0,0 +1,7 @@
+package model
+
+// StartImpersonation adalah body opsional saat admin mulai bertindak
+// sebagai user lain. Reason ikut dicatat di audit log.
+type StartImpersonation struct {
+	Reason string `json:"reason"`
+}
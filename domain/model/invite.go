@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invite adalah undangan sekali pakai untuk membuat akun admin. Token asli
+// hanya ditampilkan sekali saat dibuat; database menyimpan hash-nya.
+type Invite struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	TokenHash string              `bson:"token_hash" json:"-"`
+	Email     string              `bson:"email,omitempty" json:"email,omitempty"`
+	Role      string              `bson:"role" json:"role"`
+	CreatedBy primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	ExpiresAt time.Time           `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time          `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	UsedBy    *primitive.ObjectID `bson:"used_by,omitempty" json:"used_by,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type CreateInvite struct {
+	Email string `json:"email"`
+}
+
+type RedeemInvite struct {
+	Token    string `json:"token"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type InviteRepository interface {
+	Create(invite *Invite) error
+	FindByHash(hash string) (*Invite, error)
+	FindAll() ([]Invite, error)
+	// MarkUsed menandai undangan terpakai secara atomik. Mengembalikan false
+	// jika undangan sudah dipakai lebih dulu.
+	MarkUsed(id, userID primitive.ObjectID) (bool, error)
+	// Release membatalkan MarkUsed jika pembuatan akun gagal.
+	Release(id primitive.ObjectID) error
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// LoginAttempt mencatat kegagalan login berturut-turut untuk satu kunci,
+// yaitu email akun ("email:<email>") atau alamat IP ("ip:<ip>").
+type LoginAttempt struct {
+	Key           string     `bson:"_id" json:"key"`
+	Failures      int        `bson:"failures" json:"failures"`
+	LastFailureAt time.Time  `bson:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+}
+
+type UnlockLogin struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+type LoginAttemptRepository interface {
+	// Reserve menambah hitungan secara atomik sebelum password dicek, lalu
+	// mengembalikan data terbaru. Hitungan dimulai ulang jika jendela waktu
+	// atau masa kunci sudah lewat.
+	Reserve(key string, window time.Duration) (*LoginAttempt, error)
+	// Release mengembalikan satu hitungan dari Reserve yang ternyata tidak
+	// gagal.
+	Release(key string) error
+	// RecordFailure menandai percobaan yang sudah dihitung Reserve sebagai
+	// gagal dan mengembalikan data terbaru.
+	RecordFailure(key string) (*LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
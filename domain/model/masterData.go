@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Jenis master data yang dirujuk oleh data alumni.
+const (
+	MasterFakultas = "fakultas"
+	MasterProdi    = "prodi"
+	MasterSumber   = "sumber"
+)
+
+// MasterDataKinds adalah daftar jenis master data yang dikenal.
+var MasterDataKinds = []string{MasterFakultas, MasterProdi, MasterSumber}
+
+// MasterData adalah satu entri fakultas, prodi, atau sumber. ID berupa
+// angka karena dirujuk oleh Alumni.IDFakultas, IDProdi, dan IDSumber.
+type MasterData struct {
+	ID   int    `bson:"_id" json:"id"`
+	Nama string `bson:"nama" json:"nama"`
+	// IDFakultas hanya terisi untuk prodi.
+	IDFakultas *int      `bson:"id_fakultas,omitempty" json:"id_fakultas,omitempty"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SaveMasterData adalah body untuk membuat atau mengubah master data. ID
+// hanya dipakai saat membuat.
+type SaveMasterData struct {
+	ID         int    `json:"id"`
+	Nama       string `json:"nama"`
+	IDFakultas *int   `json:"id_fakultas,omitempty"`
+}
+
+// MasterNames memetakan ID master data ke namanya untuk respons alumni
+// dengan ?expand=true.
+type MasterNames struct {
+	Fakultas map[int]string
+	Prodi    map[int]string
+	Sumber   map[int]string
+}
+
+func lookupName(names map[int]string, id *int) string {
+	if id == nil {
+		return ""
+	}
+	return names[*id]
+}
+
+type MasterDataRepository interface {
+	FindAll(kind string, idFakultas *int) ([]MasterData, error)
+	FindByID(kind string, id int) (*MasterData, error)
+	FindByIDs(kind string, ids []int) ([]MasterData, error)
+	// FindByName mencocokkan nama tanpa membedakan huruf besar/kecil.
+	FindByName(kind, nama string) (*MasterData, error)
+	Create(kind string, item *MasterData) error
+	// Update mengembalikan false jika ID tidak ditemukan.
+	Update(kind string, item *MasterData) (bool, error)
+	Delete(kind string, id int) (bool, error)
+	CountProdiByFakultas(idFakultas int) (int, error)
+	EnsureIndexes() error
+}
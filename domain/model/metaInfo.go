@@ -10,7 +10,14 @@ type MetaInfo struct {
 	Search      string `json:"search"`
 }
 
+type AlumniResponse struct {
+	Message  string        `json:"message"`
+	Success  bool          `json:"success"`
+	Alumni   []interface{} `json:"alumni"`
+	MetaInfo MetaInfo      `json:"meta_info"`
+}
+
 type UserResponse struct {
-	Data     []Users  `json:"data"`
-	MetaInfo MetaInfo `json:"meta_info"`
+	Data     []AdminUser `json:"data"`
+	MetaInfo MetaInfo    `json:"meta_info"`
 }
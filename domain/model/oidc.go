@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCState menyimpan parameter satu percobaan login SSO antara redirect ke
+// identity provider dan callback. State disimpan sebagai hash; code
+// verifier PKCE dan nonce dibutuhkan lagi saat callback.
+type OIDCState struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	StateHash    string             `bson:"state_hash" json:"-"`
+	CodeVerifier string             `bson:"code_verifier" json:"-"`
+	Nonce        string             `bson:"nonce" json:"-"`
+	ExpiresAt    time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// OIDCIdentity adalah klaim ID token yang dipakai untuk mencocokkan user.
+type OIDCIdentity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Username      string `json:"preferred_username"`
+}
+
+type OIDCStateRepository interface {
+	Create(state *OIDCState) error
+	// Consume mengambil sekaligus menghapus state sehingga satu state hanya
+	// bisa dipakai sekali. Mengembalikan nil jika tidak ada.
+	Consume(hash string) (*OIDCState, error)
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tujuan token sekali pakai. Satu koleksi dipakai bersama, dibedakan lewat
+// field purpose agar token reset tidak bisa dipakai untuk keperluan lain.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	// TokenPurposeMFA adalah tiket langkah kedua login; tidak dikirim lewat
+	// email melainkan dikembalikan oleh endpoint login.
+	TokenPurposeMFA = "mfa_login"
+)
+
+// OneTimeToken adalah token sekali pakai yang dikirim lewat email. Database
+// hanya menyimpan hash token.
+type OneTimeToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Purpose   string             `bson:"purpose" json:"purpose"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	// Email adalah alamat tujuan token verifikasi email. Token perubahan
+	// email hanya berlaku selama alamat ini masih menjadi pending_email.
+	Email string `bson:"email,omitempty" json:"-"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token"`
+}
+
+type OneTimeTokenRepository interface {
+	Create(token *OneTimeToken) error
+	FindByHash(purpose, hash string) (*OneTimeToken, error)
+	// Consume menandai token terpakai secara atomik. Mengembalikan false jika
+	// token sudah dipakai lebih dulu.
+	Consume(id primitive.ObjectID) (bool, error)
+	// InvalidateForUser menandai semua token aktif milik user untuk tujuan
+	// tersebut sebagai terpakai, dipanggil sebelum token baru diterbitkan.
+	InvalidateForUser(userID primitive.ObjectID, purpose string) error
+}
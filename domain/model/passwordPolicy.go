@@ -0,0 +1,18 @@
+package model
+
+// Kode pelanggaran kebijakan password, stabil untuk dipakai klien.
+const (
+	PasswordRequired         = "required"
+	PasswordTooShort         = "too_short"
+	PasswordTooLong          = "too_long"
+	PasswordTooCommon        = "too_common"
+	PasswordContainsEmail    = "contains_email"
+	PasswordContainsUsername = "contains_username"
+)
+
+// PasswordViolation adalah satu syarat kebijakan password yang tidak
+// terpenuhi.
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -23,3 +23,18 @@ type Trash struct {
 	PekerjaanAlumni `bson:",inline"`
 	IsDeleted       time.Time `bson:"is_deleted" json:"is_deleted"`
 }
+
+type PekerjaanAlumniRepository interface {
+	FindByID(id string) (*PekerjaanAlumni, error)
+	Create(pekerjaan *PekerjaanAlumni) error
+	Update(id string, pekerjaan *PekerjaanAlumni) error
+	FindAll() ([]PekerjaanAlumni, error)
+	// OpenCursor membuka cursor atas data yang sama dengan FindAll.
+	// Pemanggil wajib menutupnya.
+	OpenCursor() (Cursor, error)
+	SoftDeleteByNIM(nim string) error
+	FindTrash(nim string) ([]*Trash, error)
+	RestoreByNIM(nim string) error
+	// Delete menghapus permanen data yang sudah berada di trash.
+	Delete(id string) error
+}
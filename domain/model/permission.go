@@ -0,0 +1,142 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PermAll = "*"
+
+	PermAlumniRead   = "alumni:read"
+	PermAlumniWrite  = "alumni:write"
+	PermAlumniDelete = "alumni:delete"
+	// PermAlumniClaimsReview mengizinkan menyetujui atau menolak klaim NIM.
+	PermAlumniClaimsReview = "alumni:claims_review"
+
+	PermPekerjaanRead    = "pekerjaan:read"
+	PermPekerjaanWrite   = "pekerjaan:write"
+	PermPekerjaanDelete  = "pekerjaan:delete"
+	PermPekerjaanRestore = "pekerjaan:restore"
+	PermPekerjaanPurge   = "pekerjaan:purge"
+	// PermPekerjaanManageAll mengizinkan aksi pada data pekerjaan milik alumni lain.
+	PermPekerjaanManageAll = "pekerjaan:manage_all"
+
+	PermFilesRead   = "files:read"
+	PermFilesWrite  = "files:write"
+	PermFilesDelete = "files:delete"
+
+	PermUsersRead   = "users:read"
+	PermUsersManage = "users:manage"
+	PermRolesManage = "roles:manage"
+	// PermUsersImpersonate mengizinkan menerbitkan token yang bertindak
+	// sebagai user lain (hanya-baca).
+	PermUsersImpersonate = "users:impersonate"
+
+	PermInvitesManage = "invites:manage"
+	PermAPIKeysManage = "api_keys:manage"
+	// PermMasterDataManage mengizinkan mengelola fakultas, prodi, dan sumber.
+	PermMasterDataManage = "master_data:manage"
+)
+
+// AllPermissions adalah daftar permission yang dikenal sistem. Role kustom
+// hanya boleh memakai permission dari daftar ini.
+var AllPermissions = []string{
+	PermAlumniRead, PermAlumniWrite, PermAlumniDelete, PermAlumniClaimsReview,
+	PermPekerjaanRead, PermPekerjaanWrite, PermPekerjaanDelete,
+	PermPekerjaanRestore, PermPekerjaanPurge, PermPekerjaanManageAll,
+	PermFilesRead, PermFilesWrite, PermFilesDelete,
+	PermUsersRead, PermUsersManage, PermRolesManage, PermUsersImpersonate,
+	PermInvitesManage, PermAPIKeysManage, PermMasterDataManage,
+}
+
+func IsKnownPermission(p string) bool {
+	if p == PermAll {
+		return true
+	}
+	for _, known := range AllPermissions {
+		if known == p {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyDenied berisi permission pengelolaan akun, role, undangan, dan API
+// key. Permission ini tidak pernah berlaku untuk API key, karena key yang
+// memegangnya bisa menerbitkan key atau akun baru tanpa campur tangan user.
+var apiKeyDenied = map[string]bool{
+	PermUsersManage:      true,
+	PermRolesManage:      true,
+	PermUsersImpersonate: true,
+	PermInvitesManage:    true,
+	PermAPIKeysManage:    true,
+}
+
+// AllowedForAPIKey bernilai false untuk wildcard dan permission di
+// apiKeyDenied.
+func AllowedForAPIKey(p string) bool {
+	return p != PermAll && !apiKeyDenied[p]
+}
+
+// APIKeyPermissions membuang permission yang tidak berlaku untuk API key,
+// termasuk dari key lama yang dibuat sebelum batasan ini ada.
+func APIKeyPermissions(perms []string) []string {
+	allowed := make([]string, 0, len(perms))
+	for _, p := range perms {
+		if AllowedForAPIKey(p) {
+			allowed = append(allowed, p)
+		}
+	}
+	return allowed
+}
+
+type Role struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	Permissions []string           `bson:"permissions" json:"permissions"`
+	BuiltIn     bool               `bson:"built_in" json:"built_in"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// DefaultRoles adalah role bawaan yang dibuat saat aplikasi start jika belum ada.
+func DefaultRoles() []Role {
+	return []Role{
+		{
+			Name:        "admin",
+			Description: "Akses penuh",
+			Permissions: []string{PermAll},
+			BuiltIn:     true,
+		},
+		{
+			Name:        "user",
+			Description: "Alumni yang mengelola datanya sendiri",
+			Permissions: []string{
+				PermAlumniRead,
+				PermPekerjaanRead, PermPekerjaanWrite, PermPekerjaanDelete, PermPekerjaanRestore, PermPekerjaanPurge,
+				PermFilesRead, PermFilesWrite,
+			},
+			BuiltIn: true,
+		},
+	}
+}
+
+type RoleRepository interface {
+	FindByName(name string) (*Role, error)
+	FindAll() ([]Role, error)
+	Create(role *Role) error
+	Update(role *Role) error
+	Delete(name string) error
+	// EnsureDefaults membuat role bawaan yang belum ada tanpa menimpa
+	// perubahan yang sudah dibuat admin.
+	EnsureDefaults(roles []Role) error
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Claims adalah isi JWT yang diterbitkan authService. Subject berisi
+// ObjectID user dalam bentuk hex.
+type Claims struct {
+	Role     string `json:"role"`
+	Username string `json:"username"`
+	NIM      string `json:"nim,omitempty"`
+	// SessionID menautkan token ke dokumen sesi agar bisa dicabut dari jauh.
+	SessionID string `json:"sid,omitempty"`
+	// Actor terisi pada token impersonasi dan menunjuk admin yang
+	// sebenarnya (klaim "act", RFC 8693).
+	Actor *ActorClaim `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActorClaim adalah isi klaim "act": pihak yang bertindak atas nama subject.
+type ActorClaim struct {
+	Subject  string `json:"sub"`
+	Username string `json:"username,omitempty"`
+}
+
+// Principal adalah identitas pemanggil yang sudah terautentikasi dan
+// disimpan di context request oleh middleware JWTAuth.
+type Principal struct {
+	UserID   primitive.ObjectID `json:"user_id"`
+	Role     string             `json:"role"`
+	Username string             `json:"username"`
+	NIM      string             `json:"nim,omitempty"`
+	// SessionID kosong untuk token tanpa klaim sid.
+	SessionID string `json:"sid,omitempty"`
+	// APIKeyID terisi jika pemanggil masuk lewat API key; UserID kosong
+	// karena tidak ada akun manusia di baliknya.
+	APIKeyID primitive.ObjectID `json:"api_key_id,omitempty"`
+	// ImpersonatorID terisi jika admin sedang bertindak sebagai user ini.
+	ImpersonatorID primitive.ObjectID `json:"impersonator_id,omitempty"`
+	// Permissions diisi dari koleksi roles saat request diautentikasi.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// IsAPIKey bernilai true untuk pemanggil yang masuk lewat API key.
+func (p *Principal) IsAPIKey() bool {
+	return !p.APIKeyID.IsZero()
+}
+
+// IsImpersonated bernilai true untuk request dengan token impersonasi.
+func (p *Principal) IsImpersonated() bool {
+	return !p.ImpersonatorID.IsZero()
+}
+
+// HasPermission bernilai true jika role pemanggil memiliki permission
+// tersebut atau wildcard "*".
+func (p *Principal) HasPermission(permission string) bool {
+	for _, granted := range p.Permissions {
+		if granted == PermAll || granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingPermission mengembalikan permission pertama yang tidak dimiliki
+// pemanggil, atau string kosong jika semuanya dimiliki. Dipakai agar
+// pemanggil tidak bisa memberikan permission melebihi miliknya sendiri.
+func (p *Principal) MissingPermission(permissions []string) string {
+	for _, permission := range permissions {
+		if !p.HasPermission(permission) {
+			return permission
+		}
+	}
+	return ""
+}
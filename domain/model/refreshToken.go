@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken disimpan dalam bentuk hash. Semua token hasil rotasi dari satu
+// login berbagi FamilyID yang sama, sehingga satu family bisa dicabut sekaligus.
+type RefreshToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	FamilyID   string             `bson:"family_id" json:"family_id"`
+	TokenHash  string             `bson:"token_hash" json:"-"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	ReplacedBy string             `bson:"replaced_by,omitempty" json:"-"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	FindByHash(hash string) (*RefreshToken, error)
+	// Rotate menandai token sebagai sudah dipakai. Mengembalikan false jika
+	// token ternyata sudah dicabut atau dirotasi lebih dulu.
+	Rotate(id primitive.ObjectID, replacedBy string) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID primitive.ObjectID) error
+}
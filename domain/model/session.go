@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session adalah satu login aktif. ID sesi sama dengan FamilyID refresh
+// token sehingga rotasi token tetap tercatat sebagai sesi yang sama, dan
+// disimpan di klaim "sid" access token.
+type Session struct {
+	ID         string             `bson:"_id" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"-"`
+	IP         string             `bson:"ip" json:"ip"`
+	UserAgent  string             `bson:"user_agent" json:"user_agent"`
+	IssuedAt   time.Time          `bson:"issued_at" json:"issued_at"`
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// IsActive bernilai false setelah sesi dicabut.
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil
+}
+
+type SessionRepository interface {
+	Create(session *Session) error
+	FindByID(id string) (*Session, error)
+	// ListActiveByUser mengembalikan sesi yang belum dicabut, terbaru dulu.
+	ListActiveByUser(userID primitive.ObjectID) ([]Session, error)
+	Touch(id string, seenAt time.Time, ip string) error
+	Revoke(id string) error
+	RevokeAllForUser(userID primitive.ObjectID) error
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwoFactor menyimpan pendaftaran TOTP (RFC 6238) satu user. Dokumen dibuat
+// saat setup dan baru berlaku setelah dikonfirmasi dengan kode pertama.
+type TwoFactor struct {
+	UserID primitive.ObjectID `bson:"_id" json:"-"`
+	Secret string             `bson:"secret" json:"-"`
+	// Enabled bernilai false selama pendaftaran belum dikonfirmasi.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// RecoveryCodes berisi hash sha256 kode pemulihan yang belum dipakai.
+	RecoveryCodes []string `bson:"recovery_codes" json:"-"`
+	// LastStep adalah time step TOTP terakhir yang diterima, agar kode yang
+	// sama tidak bisa dipakai dua kali.
+	LastStep    int64      `bson:"last_step" json:"-"`
+	ConfirmedAt *time.Time `bson:"confirmed_at,omitempty" json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+}
+
+type TwoFactorCode struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// LoginTwoFactor adalah langkah kedua login untuk user yang memakai 2FA.
+type LoginTwoFactor struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorRepository interface {
+	FindByUserID(userID primitive.ObjectID) (*TwoFactor, error)
+	// Save membuat atau mengganti seluruh dokumen 2FA milik user.
+	Save(tf *TwoFactor) error
+	Delete(userID primitive.ObjectID) error
+	// MarkStepUsed mencatat time step secara atomik dan bernilai false jika
+	// step tersebut (atau yang lebih baru) sudah pernah dipakai.
+	MarkStepUsed(userID primitive.ObjectID, step int64) (bool, error)
+	// UseRecoveryCode menghapus hash kode pemulihan dan bernilai false jika
+	// kode tidak ada atau sudah dipakai.
+	UseRecoveryCode(userID primitive.ObjectID, codeHash string) (bool, error)
+}
@@ -1,18 +1,76 @@
 package model
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Users struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Email     string             `bson:"email" json:"email"`
-	Username  string             `bson:"username" json:"username"`
-	Password  string             `bson:"password" json:"password"`
-	Role      string             `bson:"role" json:"role"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Email      string             `bson:"email" json:"email"`
+	Username   string             `bson:"username" json:"username"`
+	Password   string             `bson:"password" json:"-"`
+	Role       string             `bson:"role" json:"role"`
+	Status     string             `bson:"status,omitempty" json:"status,omitempty"`
+	VerifiedAt *time.Time         `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+	// PendingEmail menampung email baru dari PATCH /api/me sampai tautan
+	// verifikasi di alamat itu dibuka; Email tetap dipakai untuk login.
+	PendingEmail string `bson:"pending_email,omitempty" json:"pending_email,omitempty"`
+	// PasswordChangedAt dipakai JWTAuth untuk menolak access token yang
+	// diterbitkan sebelum password terakhir diganti.
+	PasswordChangedAt *time.Time `bson:"password_changed_at,omitempty" json:"-"`
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`
+}
+
+const (
+	UserStatusActive     = "active"
+	UserStatusUnverified = "unverified"
+	UserStatusSuspended  = "suspended"
+)
+
+// IsVerified bernilai false selama email akun belum diverifikasi. Status
+// kosong dianggap aktif agar akun lama tetap bisa login.
+func (u *Users) IsVerified() bool {
+	return u.Status != UserStatusUnverified
+}
+
+// TokenPredatesPasswordChange bernilai true jika token diterbitkan sebelum
+// password terakhir diganti. iat JWT berpresisi detik sehingga waktu
+// pergantian dibulatkan ke bawah.
+func (u *Users) TokenPredatesPasswordChange(issuedAt time.Time) bool {
+	if u.PasswordChangedAt == nil {
+		return false
+	}
+	return issuedAt.Before(u.PasswordChangedAt.Truncate(time.Second))
+}
+
+// NormalizeEmail memangkas spasi dan mengecilkan huruf email. Semua
+// penulisan dan pencarian email memakai bentuk ini agar unique index email
+// tidak bisa diakali dengan beda huruf besar-kecil.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// IsSuspended bernilai true jika akun dinonaktifkan admin.
+func (u *Users) IsSuspended() bool {
+	return u.Status == UserStatusSuspended
+}
+
+type UpdateUser struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangeRole struct {
+	Role string `json:"role"`
 }
 
 type Login struct {
@@ -32,13 +90,37 @@ type ErrorResponse struct {
 	Error string `json:"error" example:"Failed to fetch users"`
 }
 
+// ErrAdminChangeBusy dikembalikan LockAdminChanges saat perubahan admin lain
+// sedang berjalan.
+var ErrAdminChangeBusy = errors.New("perubahan admin lain sedang diproses, coba lagi")
+
 type UserRepository interface {
 	FindByID(id primitive.ObjectID) (*Users, error)
 	FindByEmail(email string) (*Users, error)
 	FindAll() ([]Users, error)
 	Create(user *Users) error
 	Update(user *Users) error
+	// UpdatePasswordHash hanya mengganti hash jika password tersimpan masih
+	// oldHash, agar tidak menimpa password yang baru saja diganti.
+	UpdatePasswordHash(id primitive.ObjectID, oldHash, newHash string) error
+	// UpdateStatus dan UpdateRole hanya mengganti satu field, agar perubahan
+	// admin tidak menimpa data yang diubah request lain sejak user dibaca.
+	UpdateStatus(id primitive.ObjectID, status string) error
+	UpdateRole(id primitive.ObjectID, role string) error
 	Delete(id primitive.ObjectID) error
 	Count(search string) (int, error)
+	CountByRole(role string) (int, error)
+	// CountActiveByRole hanya menghitung akun yang bisa login (tidak
+	// dinonaktifkan dan sudah terverifikasi).
+	CountActiveByRole(role string) (int, error)
+	// LockAdminChanges menyerialkan perubahan yang bisa mengurangi jumlah
+	// admin aktif, agar pengecekan admin terakhir dan perubahannya tidak
+	// diselingi request lain. Mengembalikan ErrAdminChangeBusy jika kunci
+	// sedang dipegang; fungsi yang dikembalikan melepas kunci.
+	LockAdminChanges() (func(), error)
+	// EnsureIndexes menormalkan email lama ke huruf kecil lalu membuat unique
+	// index email. Create dan Update mengembalikan duplicate key error jika
+	// email sudah dipakai.
+	EnsureIndexes() error
 }
 
@@ -4,15 +4,84 @@ import (
 	. "Mongo/domain/config"
 	"Mongo/domain/model"
 	"context"
+	"errors"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func getAlumniCollection() *mongo.Collection {
 	return DB.Database("alumni_management_db").Collection("alumni")
 }
 
+type alumniRepoStruct struct{}
+
+// NewAlumniRepository membungkus fungsi-fungsi alumni di bawah agar bisa
+// dipakai lewat interface model.AlumniRepository.
+func NewAlumniRepository() model.AlumniRepository {
+	return &alumniRepoStruct{}
+}
+
+func (r *alumniRepoStruct) CheckAlumniByNim(nim string) (*model.Alumni, error) {
+	return CheckAlumniByNim(nim)
+}
+
+func (r *alumniRepoStruct) FindByUserID(userID primitive.ObjectID) (*model.Alumni, error) {
+	return FindAlumniByUserID(userID)
+}
+
+func (r *alumniRepoStruct) LinkUser(nim string, userID primitive.ObjectID) (bool, error) {
+	return LinkAlumniUser(nim, userID)
+}
+
+func (r *alumniRepoStruct) UnlinkUser(userID primitive.ObjectID) error {
+	return UnlinkAlumniUser(userID)
+}
+
+func (r *alumniRepoStruct) RenameSumber(idSumber int, nama string) error {
+	return RenameAlumniSumber(idSumber, nama)
+}
+
+func (r *alumniRepoStruct) CreateAlumni(alumni *model.Alumni) error {
+	return CreateAlumni(alumni)
+}
+
+func (r *alumniRepoStruct) UpdateAlumni(nim string, alumni *model.Alumni) error {
+	return UpdateAlumni(nim, alumni)
+}
+
+func (r *alumniRepoStruct) DeleteAlumni(nim string) error {
+	return DeleteAlumni(nim)
+}
+
+func (r *alumniRepoStruct) GetAllAlumni(filter model.AlumniFilter, sortBy, order string, limit, offset int) ([]model.Alumni, error) {
+	return GetAllAlumni(filter, sortBy, order, limit, offset)
+}
+
+func (r *alumniRepoStruct) CountAlumni(filter model.AlumniFilter) (int, error) {
+	return CountAlumni(filter)
+}
+
+func (r *alumniRepoStruct) OpenAlumniCursor(filter model.AlumniFilter, sortBy, order string) (model.Cursor, error) {
+	return OpenAlumniCursor(filter, sortBy, order)
+}
+
+func (r *alumniRepoStruct) ExistingNIMs(nims []string) ([]string, error) {
+	return ExistingAlumniNIMs(nims)
+}
+
+func (r *alumniRepoStruct) UpsertAlumni(alumni *model.Alumni, columns []string) (bool, error) {
+	return UpsertAlumni(alumni, columns)
+}
+
+func (r *alumniRepoStruct) EnsureIndexes() error {
+	return EnsureAlumniIndexes()
+}
+
 func CheckAlumniByNim(nim string) (*model.Alumni, error) {
 	alumni := new(model.Alumni)
 	ctx := context.TODO()
@@ -27,14 +96,77 @@ func CheckAlumniByNim(nim string) (*model.Alumni, error) {
 	return alumni, nil
 }
 
+// FindAlumniByUserID mencari data alumni yang terhubung ke akun user.
+// Mengembalikan nil tanpa error jika user belum terhubung ke NIM mana pun.
+func FindAlumniByUserID(userID primitive.ObjectID) (*model.Alumni, error) {
+	alumni := new(model.Alumni)
+	ctx := context.TODO()
+	collection := getAlumniCollection()
+
+	filter := bson.M{"user_id": userID}
+
+	err := collection.FindOne(ctx, filter).Decode(alumni)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return alumni, nil
+}
+
+// LinkAlumniUser mengisi user_id hanya jika alumni belum tertaut, sehingga
+// dua persetujuan bersamaan tidak saling menimpa.
+func LinkAlumniUser(nim string, userID primitive.ObjectID) (bool, error) {
+	ctx := context.TODO()
+	collection := getAlumniCollection()
+
+	filter := bson.M{"nim": nim, "user_id": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"user_id": userID, "updated_at": time.Now()}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func UnlinkAlumniUser(userID primitive.ObjectID) error {
+	ctx := context.TODO()
+	collection := getAlumniCollection()
+
+	filter := bson.M{"user_id": userID}
+	update := bson.M{"$unset": bson.M{"user_id": ""}, "$set": bson.M{"updated_at": time.Now()}}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func RenameAlumniSumber(idSumber int, nama string) error {
+	ctx := context.TODO()
+	collection := getAlumniCollection()
+
+	filter := bson.M{"id_sumber": idSumber}
+	update := bson.M{"$set": bson.M{"sumber": nama, "updated_at": time.Now()}}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
 func CreateAlumni(alumni *model.Alumni) error {
 	ctx := context.TODO()
 	collection := getAlumniCollection()
 
+	now := time.Now()
+	alumni.CreatedAt = now
+	alumni.UpdatedAt = now
+
 	_, err := collection.InsertOne(ctx, alumni)
 	return err
 }
 
+// UpdateAlumni hanya menulis field yang boleh diubah; NIM, user_id dan
+// created_at tidak disentuh. Dokumen hasil update ditulis balik ke alumni.
 func UpdateAlumni(nim string, alumni *model.Alumni) error {
 	ctx := context.TODO()
 	collection := getAlumniCollection()
@@ -42,11 +174,20 @@ func UpdateAlumni(nim string, alumni *model.Alumni) error {
 	filter := bson.M{"nim": nim}
 
 	update := bson.M{
-		"$set": alumni,
+		"$set": bson.M{
+			"nama":        alumni.Nama,
+			"angkatan":    alumni.Angkatan,
+			"tahun_lulus": alumni.TahunLulus,
+			"id_fakultas": alumni.IDFakultas,
+			"id_prodi":    alumni.IDProdi,
+			"id_sumber":   alumni.IDSumber,
+			"sumber":      alumni.Sumber,
+			"updated_at":  time.Now(),
+		},
 	}
 
-	_, err := collection.UpdateOne(ctx, filter, update)
-	return err
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	return collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(alumni)
 }
 
 func DeleteAlumni(nim string) error {
@@ -59,11 +200,137 @@ func DeleteAlumni(nim string) error {
 	return err
 }
 
-func GetAllAlumni() ([]model.Alumni, error) {
-	ctx := context.TODO()
+// alumniIndexes berisi unique index NIM dan index untuk filter daftar
+// alumni: field kesamaan (prodi, fakultas, sumber) di depan, rentang tahun
+// di belakang.
+var alumniIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "nim", Value: 1}}, Options: options.Index().SetName("nim_unique").SetUnique(true)},
+	{Keys: bson.D{{Key: "id_prodi", Value: 1}, {Key: "tahun_lulus", Value: 1}}, Options: options.Index().SetName("prodi_tahun_lulus")},
+	{Keys: bson.D{{Key: "id_prodi", Value: 1}, {Key: "angkatan", Value: 1}}, Options: options.Index().SetName("prodi_angkatan")},
+	{Keys: bson.D{{Key: "id_fakultas", Value: 1}, {Key: "tahun_lulus", Value: 1}}, Options: options.Index().SetName("fakultas_tahun_lulus")},
+	{Keys: bson.D{{Key: "id_fakultas", Value: 1}, {Key: "angkatan", Value: 1}}, Options: options.Index().SetName("fakultas_angkatan")},
+	{Keys: bson.D{{Key: "sumber", Value: 1}, {Key: "tahun_lulus", Value: 1}}, Options: options.Index().SetName("sumber_tahun_lulus")},
+	{Keys: bson.D{{Key: "id_sumber", Value: 1}, {Key: "tahun_lulus", Value: 1}}, Options: options.Index().SetName("id_sumber_tahun_lulus")},
+}
+
+func ExistingAlumniNIMs(nims []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, err := getAlumniCollection().Distinct(ctx, "nim", bson.M{"nim": bson.M{"$in": nims}})
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]string, 0, len(values))
+	for _, v := range values {
+		if nim, ok := v.(string); ok {
+			existing = append(existing, nim)
+		}
+	}
+	return existing, nil
+}
+
+func UpsertAlumni(alumni *model.Alumni, columns []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Field dipilih lewat tag bson agar nama kolom import langsung dipakai
+	doc, err := bson.Marshal(alumni)
+	if err != nil {
+		return false, err
+	}
+	var fields bson.M
+	if err := bson.Unmarshal(doc, &fields); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	for _, column := range columns {
+		if column != "nim" {
+			set[column] = fields[column]
+		}
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	result, err := getAlumniCollection().UpdateOne(ctx, bson.M{"nim": alumni.NIM}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+func EnsureAlumniIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := getAlumniCollection().Indexes().CreateMany(ctx, alumniIndexes)
+	return err
+}
+
+// alumniQuery menerjemahkan AlumniFilter ke filter MongoDB. Search
+// dicocokkan ke NIM atau nama dan di-escape agar tidak dibaca sebagai regex.
+func alumniQuery(f model.AlumniFilter) bson.M {
+	filter := bson.M{}
+	if f.Search != "" {
+		searchPattern := primitive.Regex{Pattern: regexp.QuoteMeta(f.Search), Options: "i"}
+		filter["$or"] = []bson.M{
+			{"nim": searchPattern},
+			{"nama": searchPattern},
+		}
+	}
+
+	addYearFilter(filter, "angkatan", f.Angkatan, f.AngkatanFrom, f.AngkatanTo)
+	addYearFilter(filter, "tahun_lulus", f.TahunLulus, f.TahunLulusFrom, f.TahunLulusTo)
+	if len(f.IDFakultas) > 0 {
+		filter["id_fakultas"] = bson.M{"$in": f.IDFakultas}
+	}
+	if len(f.IDProdi) > 0 {
+		filter["id_prodi"] = bson.M{"$in": f.IDProdi}
+	}
+	if len(f.IDSumber) > 0 {
+		filter["id_sumber"] = bson.M{"$in": f.IDSumber}
+	}
+	if len(f.Sumber) > 0 {
+		filter["sumber"] = bson.M{"$in": f.Sumber}
+	}
+
+	return filter
+}
+
+// addYearFilter menggabungkan daftar tahun dan rentang from/to pada satu
+// field.
+func addYearFilter(filter bson.M, field string, values []int, from, to *int) {
+	cond := bson.M{}
+	if len(values) > 0 {
+		cond["$in"] = values
+	}
+	if from != nil {
+		cond["$gte"] = *from
+	}
+	if to != nil {
+		cond["$lte"] = *to
+	}
+	if len(cond) > 0 {
+		filter[field] = cond
+	}
+}
+
+func GetAllAlumni(f model.AlumniFilter, sortBy, order string, limit, offset int) ([]model.Alumni, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 	collection := getAlumniCollection()
 
-	cursor, err := collection.Find(ctx, bson.D{})
+	findOptions := options.Find().
+		SetSort(alumniSort(sortBy, order)).
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset))
+
+	cursor, err := collection.Find(ctx, alumniQuery(f), findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +343,44 @@ func GetAllAlumni() ([]model.Alumni, error) {
 
 	return alumniList, nil
 }
+
+// alumniSort mengurutkan berdasarkan sortBy dengan NIM sebagai penentu
+// urutan kedua agar halaman tidak saling tumpang tindih.
+func alumniSort(sortBy, order string) bson.D {
+	sortDirection := 1
+	if order == "desc" {
+		sortDirection = -1
+	}
+	sort := bson.D{{Key: sortBy, Value: sortDirection}}
+	if sortBy != "nim" {
+		sort = append(sort, bson.E{Key: "nim", Value: 1})
+	}
+	return sort
+}
+
+func OpenAlumniCursor(f model.AlumniFilter, sortBy, order string) (model.Cursor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetSort(alumniSort(sortBy, order)).
+		SetBatchSize(500)
+
+	cursor, err := getAlumniCollection().Find(ctx, alumniQuery(f), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func CountAlumni(f model.AlumniFilter) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := getAlumniCollection().CountDocuments(ctx, alumniQuery(f))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -19,6 +19,50 @@ func getCollectionPekerjaan() *mongo.Collection {
 	return config.DB.Database("alumni_management_db").Collection(CollectionPekerjaan)
 }
 
+type pekerjaanRepoStruct struct{}
+
+// NewPekerjaanAlumniRepository membungkus fungsi-fungsi pekerjaan di bawah
+// agar bisa dipakai lewat interface model.PekerjaanAlumniRepository.
+func NewPekerjaanAlumniRepository() model.PekerjaanAlumniRepository {
+	return &pekerjaanRepoStruct{}
+}
+
+func (r *pekerjaanRepoStruct) FindByID(id string) (*model.PekerjaanAlumni, error) {
+	return CheckpekerjaanAlumniByID(id)
+}
+
+func (r *pekerjaanRepoStruct) Create(pekerjaan *model.PekerjaanAlumni) error {
+	return CreatepekerjaanAlumni(pekerjaan)
+}
+
+func (r *pekerjaanRepoStruct) Update(id string, pekerjaan *model.PekerjaanAlumni) error {
+	return UpdatepekerjaanAlumni(id, pekerjaan)
+}
+
+func (r *pekerjaanRepoStruct) FindAll() ([]model.PekerjaanAlumni, error) {
+	return GetAllpekerjaanAlumni()
+}
+
+func (r *pekerjaanRepoStruct) OpenCursor() (model.Cursor, error) {
+	return OpenPekerjaanCursor()
+}
+
+func (r *pekerjaanRepoStruct) SoftDeleteByNIM(nim string) error {
+	return SoftDeleteBynim(nim)
+}
+
+func (r *pekerjaanRepoStruct) FindTrash(nim string) ([]*model.Trash, error) {
+	return GetAllTrash(nim)
+}
+
+func (r *pekerjaanRepoStruct) RestoreByNIM(nim string) error {
+	return RestoreTrashBynim(nim)
+}
+
+func (r *pekerjaanRepoStruct) Delete(id string) error {
+	return DeletePekerjaanByid(id)
+}
+
 func CheckpekerjaanAlumniByID(id string) (*model.PekerjaanAlumni, error) {
 	pekerjaan := new(model.PekerjaanAlumni)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -61,12 +105,17 @@ func CreatepekerjaanAlumni(pekerjaan *model.PekerjaanAlumni) error {
 	return nil
 }
 
-func UpdatepekerjaanAlumni(NimAlumni string, pekerjaan *model.PekerjaanAlumni) error {
+func UpdatepekerjaanAlumni(id string, pekerjaan *model.PekerjaanAlumni) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	collection := getCollectionPekerjaan()
 
-	filter := bson.M{"nim_alumni": NimAlumni}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid job ID format")
+	}
+
+	filter := bson.M{"_id": objID, "is_deleted": bson.M{"$exists": false}}
 
 	update := bson.M{
 		"$set": bson.M{
@@ -87,8 +136,8 @@ func UpdatepekerjaanAlumni(NimAlumni string, pekerjaan *model.PekerjaanAlumni) e
 		return err
 	}
 
-	if result.ModifiedCount == 0 && result.UpsertedCount == 0 {
-		return errors.New("no document found or updated for the given nim")
+	if result.MatchedCount == 0 {
+		return errors.New("no active job record found for the given ID")
 	}
 
 	return nil
@@ -114,6 +163,22 @@ func GetAllpekerjaanAlumni() ([]model.PekerjaanAlumni, error) {
 	return pekerjaanList, nil
 }
 
+func OpenPekerjaanCursor() (model.Cursor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"is_deleted": bson.M{"$exists": false}}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "nim_alumni", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(500)
+
+	cursor, err := getCollectionPekerjaan().Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
 func SoftDeleteBynim(NimAlumni string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -13,6 +13,7 @@ import (
 type UploadsRepository interface {
 	Create(Uploads *model.Uploads) error
 	FindAll() ([]model.Uploads, error)
+	FindByUploader(userID primitive.ObjectID) ([]model.Uploads, error)
 	FindByID(id string) (*model.Uploads, error)
 	Delete(id string) error
 }
@@ -50,6 +51,20 @@ func (r *upRepository) FindAll() ([]model.Uploads, error) {
 	}
 	return Uploadss, nil
 }
+func (r *upRepository) FindByUploader(userID primitive.ObjectID) ([]model.Uploads, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var Uploadss []model.Uploads
+	cursor, err := r.collection.Find(ctx, bson.M{"uploaded_by": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &Uploadss); err != nil {
+		return nil, err
+	}
+	return Uploadss, nil
+}
 func (r *upRepository) FindByID(id string) (*model.Uploads, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionAlumniClaims = "alumni_claims"
+
+type alumniClaimRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewAlumniClaimRepository(client *mongo.Client) model.AlumniClaimRepository {
+	return &alumniClaimRepoStruct{client}
+}
+
+func (r *alumniClaimRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionAlumniClaims)
+}
+
+func (r *alumniClaimRepoStruct) Create(claim *model.AlumniClaim) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	claim.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, claim)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		claim.ID = oid
+	}
+
+	return nil
+}
+
+func (r *alumniClaimRepoStruct) FindByID(id primitive.ObjectID) (*model.AlumniClaim, error) {
+	return r.findOne(bson.M{"_id": id}, nil)
+}
+
+func (r *alumniClaimRepoStruct) FindLatestByUser(userID primitive.ObjectID) (*model.AlumniClaim, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return r.findOne(bson.M{"user_id": userID}, opts)
+}
+
+func (r *alumniClaimRepoStruct) findOne(filter bson.M, opts *options.FindOneOptions) (*model.AlumniClaim, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	claim := new(model.AlumniClaim)
+	err := r.getCollection().FindOne(ctx, filter, opts).Decode(claim)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+func (r *alumniClaimRepoStruct) FindByStatus(status string) ([]model.AlumniClaim, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.getCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	claims := []model.AlumniClaim{}
+	if err = cursor.All(ctx, &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (r *alumniClaimRepoStruct) Review(id primitive.ObjectID, status string, reviewer primitive.ObjectID, reason string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "status": model.ClaimStatusPending}
+	set := bson.M{"status": status, "reviewed_by": reviewer, "reviewed_at": time.Now()}
+	if reason != "" {
+		set["reject_reason"] = reason
+	}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *alumniClaimRepoStruct) Reopen(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set":   bson.M{"status": model.ClaimStatusPending},
+		"$unset": bson.M{"reviewed_by": "", "reviewed_at": "", "reject_reason": ""},
+	}
+
+	_, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
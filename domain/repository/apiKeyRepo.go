@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionAPIKeys = "api_keys"
+
+type apiKeyRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewAPIKeyRepository(client *mongo.Client) model.APIKeyRepository {
+	return &apiKeyRepoStruct{client}
+}
+
+func (r *apiKeyRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionAPIKeys)
+}
+
+func (r *apiKeyRepoStruct) Create(key *model.APIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		key.ID = oid
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepoStruct) FindByHash(hash string) (*model.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := new(model.APIKey)
+	err := r.getCollection().FindOne(ctx, bson.M{"key_hash": hash}).Decode(key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepoStruct) FindAll() ([]model.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.getCollection().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []model.APIKey{}
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepoStruct) Revoke(id, revokedBy primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoked_by": revokedBy}}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *apiKeyRepoStruct) TouchLastUsed(id primitive.ObjectID, usedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	return err
+}
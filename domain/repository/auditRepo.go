@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const CollectionAuditLogs = "audit_logs"
+
+type auditRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewAuditRepository(client *mongo.Client) model.AuditRepository {
+	return &auditRepoStruct{client}
+}
+
+func (r *auditRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionAuditLogs)
+}
+
+func (r *auditRepoStruct) Create(entry *model.AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entry.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		entry.ID = oid
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionInvites = "invites"
+
+type inviteRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewInviteRepository(client *mongo.Client) model.InviteRepository {
+	return &inviteRepoStruct{client}
+}
+
+func (r *inviteRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionInvites)
+}
+
+func (r *inviteRepoStruct) Create(invite *model.Invite) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invite.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, invite)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		invite.ID = oid
+	}
+
+	return nil
+}
+
+func (r *inviteRepoStruct) FindByHash(hash string) (*model.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invite := new(model.Invite)
+	err := r.getCollection().FindOne(ctx, bson.M{"token_hash": hash}).Decode(invite)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+func (r *inviteRepoStruct) FindAll() ([]model.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.getCollection().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invites []model.Invite
+	if err = cursor.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+func (r *inviteRepoStruct) MarkUsed(id, userID primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "used_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"used_at": time.Now(), "used_by": userID}}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *inviteRepoStruct) Release(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$unset": bson.M{"used_at": "", "used_by": ""}}
+
+	_, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionLoginAttempts = "login_attempts"
+
+type loginAttemptRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewLoginAttemptRepository(client *mongo.Client) model.LoginAttemptRepository {
+	return &loginAttemptRepoStruct{client}
+}
+
+func (r *loginAttemptRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionLoginAttempts)
+}
+
+func (r *loginAttemptRepoStruct) Reserve(key string, window time.Duration) (*model.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	// Hitungan dimulai ulang jika masa kunci sudah lewat, atau jika tidak
+	// dikunci dan kegagalan terakhir sudah di luar jendela waktu. Dokumen
+	// baru tidak punya last_failure_at sehingga ikut dimulai dari satu.
+	expired := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{"$locked_until", nil}},
+		bson.M{"$lte": bson.A{"$locked_until", now}},
+		bson.M{"$lt": bson.A{"$last_failure_at", now.Add(-window)}},
+	}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures":        bson.M{"$cond": bson.A{expired, 1, bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}}}},
+		"last_failure_at": bson.M{"$cond": bson.A{expired, now, "$last_failure_at"}},
+		"locked_until":    bson.M{"$cond": bson.A{expired, "$$REMOVE", "$locked_until"}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	attempt := new(model.LoginAttempt)
+	if err := r.getCollection().FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(attempt); err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+func (r *loginAttemptRepoStruct) Release(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": key, "failures": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"failures": -1}})
+	return err
+}
+
+func (r *loginAttemptRepoStruct) RecordFailure(key string) (*model.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"last_failure_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	attempt := new(model.LoginAttempt)
+	if err := r.getCollection().FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(attempt); err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+func (r *loginAttemptRepoStruct) Lock(key string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"locked_until": until}})
+	return err
+}
+
+func (r *loginAttemptRepoStruct) Reset(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
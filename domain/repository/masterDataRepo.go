@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type masterDataRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewMasterDataRepository(client *mongo.Client) model.MasterDataRepository {
+	return &masterDataRepoStruct{client}
+}
+
+// getCollection memakai nama jenis master data sebagai nama koleksi.
+func (r *masterDataRepoStruct) getCollection(kind string) *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(kind)
+}
+
+func (r *masterDataRepoStruct) FindAll(kind string, idFakultas *int) ([]model.MasterData, error) {
+	filter := bson.M{}
+	if idFakultas != nil {
+		filter["id_fakultas"] = *idFakultas
+	}
+	return r.find(kind, filter)
+}
+
+func (r *masterDataRepoStruct) FindByIDs(kind string, ids []int) ([]model.MasterData, error) {
+	return r.find(kind, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (r *masterDataRepoStruct) find(kind string, filter bson.M) ([]model.MasterData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.getCollection(kind).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []model.MasterData{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *masterDataRepoStruct) FindByID(kind string, id int) (*model.MasterData, error) {
+	return r.findOne(kind, bson.M{"_id": id})
+}
+
+func (r *masterDataRepoStruct) FindByName(kind, nama string) (*model.MasterData, error) {
+	pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(nama) + "$", Options: "i"}
+	return r.findOne(kind, bson.M{"nama": pattern})
+}
+
+func (r *masterDataRepoStruct) findOne(kind string, filter bson.M) (*model.MasterData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	item := new(model.MasterData)
+	err := r.getCollection(kind).FindOne(ctx, filter).Decode(item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (r *masterDataRepoStruct) Create(kind string, item *model.MasterData) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	_, err := r.getCollection(kind).InsertOne(ctx, item)
+	return err
+}
+
+func (r *masterDataRepoStruct) Update(kind string, item *model.MasterData) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	item.UpdatedAt = time.Now()
+	set := bson.M{"nama": item.Nama, "updated_at": item.UpdatedAt}
+	if item.IDFakultas != nil {
+		set["id_fakultas"] = *item.IDFakultas
+	}
+
+	result, err := r.getCollection(kind).UpdateOne(ctx, bson.M{"_id": item.ID}, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *masterDataRepoStruct) Delete(kind string, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.getCollection(kind).DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+func (r *masterDataRepoStruct) CountProdiByFakultas(idFakultas int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := r.getCollection(model.MasterProdi).CountDocuments(ctx, bson.M{"id_fakultas": idFakultas})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (r *masterDataRepoStruct) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection(model.MasterProdi).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id_fakultas", Value: 1}},
+		Options: options.Index().SetName("id_fakultas"),
+	})
+	return err
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const CollectionOIDCStates = "oidc_states"
+
+type oidcStateRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewOIDCStateRepository(client *mongo.Client) model.OIDCStateRepository {
+	return &oidcStateRepoStruct{client}
+}
+
+func (r *oidcStateRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionOIDCStates)
+}
+
+func (r *oidcStateRepoStruct) Create(state *model.OIDCState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		state.ID = oid
+	}
+
+	return nil
+}
+
+func (r *oidcStateRepoStruct) Consume(hash string) (*model.OIDCState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state := new(model.OIDCState)
+	err := r.getCollection().FindOneAndDelete(ctx, bson.M{"state_hash": hash}).Decode(state)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return state, nil
+}
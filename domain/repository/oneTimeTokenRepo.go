@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const CollectionOneTimeTokens = "one_time_tokens"
+
+type oneTimeTokenRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewOneTimeTokenRepository(client *mongo.Client) model.OneTimeTokenRepository {
+	return &oneTimeTokenRepoStruct{client}
+}
+
+func (r *oneTimeTokenRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionOneTimeTokens)
+}
+
+func (r *oneTimeTokenRepoStruct) Create(token *model.OneTimeToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		token.ID = oid
+	}
+
+	return nil
+}
+
+func (r *oneTimeTokenRepoStruct) FindByHash(purpose, hash string) (*model.OneTimeToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token := new(model.OneTimeToken)
+	err := r.getCollection().FindOne(ctx, bson.M{"purpose": purpose, "token_hash": hash}).Decode(token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *oneTimeTokenRepoStruct) Consume(id primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "used_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"used_at": time.Now()}}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *oneTimeTokenRepoStruct) InvalidateForUser(userID primitive.ObjectID, purpose string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"used_at": time.Now()}}
+
+	_, err := r.getCollection().UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const CollectionRefreshTokens = "refresh_tokens"
+
+type refreshTokenRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewRefreshTokenRepository(client *mongo.Client) model.RefreshTokenRepository {
+	return &refreshTokenRepoStruct{client}
+}
+
+func (r *refreshTokenRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionRefreshTokens)
+}
+
+func (r *refreshTokenRepoStruct) Create(token *model.RefreshToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token.CreatedAt = time.Now()
+
+	result, err := r.getCollection().InsertOne(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		token.ID = oid
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepoStruct) FindByHash(hash string) (*model.RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token := new(model.RefreshToken)
+	err := r.getCollection().FindOne(ctx, bson.M{"token_hash": hash}).Decode(token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *refreshTokenRepoStruct) Rotate(id primitive.ObjectID, replacedBy string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Filter revoked_at memastikan hanya satu request yang berhasil merotasi
+	// token yang sama; request kedua dianggap reuse.
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now(), "replaced_by": replacedBy}}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *refreshTokenRepoStruct) RevokeFamily(familyID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	_, err := r.getCollection().UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (r *refreshTokenRepoStruct) RevokeAllForUser(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	_, err := r.getCollection().UpdateMany(ctx, filter, update)
+	return err
+}
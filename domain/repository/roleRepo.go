@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionRoles = "roles"
+
+type roleRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewRoleRepository(client *mongo.Client) model.RoleRepository {
+	return &roleRepoStruct{client}
+}
+
+func (r *roleRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionRoles)
+}
+
+func (r *roleRepoStruct) FindByName(name string) (*model.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	role := new(model.Role)
+	err := r.getCollection().FindOne(ctx, bson.M{"name": name}).Decode(role)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *roleRepoStruct) FindAll() ([]model.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.getCollection().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var roles []model.Role
+	if err = cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepoStruct) Create(role *model.Role) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = role.CreatedAt
+
+	result, err := r.getCollection().InsertOne(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		role.ID = oid
+	}
+
+	return nil
+}
+
+func (r *roleRepoStruct) Update(role *model.Role) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	role.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"description": role.Description,
+			"permissions": role.Permissions,
+			"updated_at":  role.UpdatedAt,
+		},
+	}
+
+	result, err := r.getCollection().UpdateOne(ctx, bson.M{"name": role.Name}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *roleRepoStruct) Delete(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.getCollection().DeleteOne(ctx, bson.M{"name": name, "built_in": bson.M{"$ne": true}})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *roleRepoStruct) EnsureDefaults(roles []model.Role) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	for _, role := range roles {
+		update := bson.M{
+			"$setOnInsert": bson.M{
+				"name":        role.Name,
+				"description": role.Description,
+				"permissions": role.Permissions,
+				"built_in":    role.BuiltIn,
+				"created_at":  now,
+				"updated_at":  now,
+			},
+		}
+
+		opts := options.Update().SetUpsert(true)
+		if _, err := r.getCollection().UpdateOne(ctx, bson.M{"name": role.Name}, update, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionSessions = "sessions"
+
+type sessionRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewSessionRepository(client *mongo.Client) model.SessionRepository {
+	return &sessionRepoStruct{client}
+}
+
+func (r *sessionRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionSessions)
+}
+
+func (r *sessionRepoStruct) Create(session *model.Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if session.IssuedAt.IsZero() {
+		session.IssuedAt = time.Now()
+	}
+	if session.LastSeenAt.IsZero() {
+		session.LastSeenAt = session.IssuedAt
+	}
+
+	_, err := r.getCollection().InsertOne(ctx, session)
+	return err
+}
+
+func (r *sessionRepoStruct) FindByID(id string) (*model.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session := new(model.Session)
+	err := r.getCollection().FindOne(ctx, bson.M{"_id": id}).Decode(session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r *sessionRepoStruct) ListActiveByUser(userID primitive.ObjectID) ([]model.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	cursor, err := r.getCollection().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []model.Session{}
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepoStruct) Touch(id string, seenAt time.Time, ip string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_seen_at": seenAt, "ip": ip}})
+	return err
+}
+
+func (r *sessionRepoStruct) Revoke(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	_, err := r.getCollection().UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
+
+func (r *sessionRepoStruct) RevokeAllForUser(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	_, err := r.getCollection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
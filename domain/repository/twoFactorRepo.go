@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CollectionTwoFactor = "two_factor"
+
+type twoFactorRepoStruct struct {
+	client *mongo.Client
+}
+
+func NewTwoFactorRepository(client *mongo.Client) model.TwoFactorRepository {
+	return &twoFactorRepoStruct{client}
+}
+
+func (r *twoFactorRepoStruct) getCollection() *mongo.Collection {
+	return r.client.Database("alumni_management_db").Collection(CollectionTwoFactor)
+}
+
+func (r *twoFactorRepoStruct) FindByUserID(userID primitive.ObjectID) (*model.TwoFactor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tf := new(model.TwoFactor)
+	err := r.getCollection().FindOne(ctx, bson.M{"_id": userID}).Decode(tf)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+func (r *twoFactorRepoStruct) Save(tf *model.TwoFactor) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if tf.CreatedAt.IsZero() {
+		tf.CreatedAt = time.Now()
+	}
+
+	_, err := r.getCollection().ReplaceOne(ctx, bson.M{"_id": tf.UserID}, tf, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *twoFactorRepoStruct) Delete(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.getCollection().DeleteOne(ctx, bson.M{"_id": userID})
+	return err
+}
+
+func (r *twoFactorRepoStruct) MarkStepUsed(userID primitive.ObjectID, step int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": userID, "last_step": bson.M{"$lt": step}}
+	update := bson.M{"$set": bson.M{"last_step": step}}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *twoFactorRepoStruct) UseRecoveryCode(userID primitive.ObjectID, codeHash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": userID, "recovery_codes": codeHash}
+	update := bson.M{"$pull": bson.M{"recovery_codes": codeHash}}
+
+	result, err := r.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
@@ -5,7 +5,9 @@ import (
 	"Mongo/domain/model"
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,6 +26,10 @@ func NewUserRepository(client *mongo.Client) model.UserRepository {
 	return &userRepoStruct{client}
 }
 
+// withoutPassword adalah projection untuk query daftar user. Hash password
+// hanya dibaca oleh FindByID/FindByEmail yang memang membutuhkannya.
+var withoutPassword = bson.M{"password": 0}
+
 func (r *userRepoStruct) getCollection() *mongo.Collection {
 	return r.client.Database("alumni_management_db").Collection(CollectionUsers)
 }
@@ -55,7 +61,7 @@ func (r *userRepoStruct) FindByEmail(email string) (*model.Users, error) {
 	collection := r.getCollection()
 	user := new(model.Users)
 
-	filter := bson.M{"email": email}
+	filter := bson.M{"email": model.NormalizeEmail(email)}
 
 	err := collection.FindOne(ctx, filter).Decode(user)
 	if err != nil {
@@ -73,7 +79,7 @@ func (r *userRepoStruct) FindAll() ([]model.Users, error) {
 	defer cancel()
 
 	collection := r.getCollection()
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(withoutPassword))
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +99,7 @@ func (r *userRepoStruct) Create(user *model.Users) error {
 
 	collection := r.getCollection()
 
+	user.Email = model.NormalizeEmail(user.Email)
 	user.CreatedAt = time.Now()
 
 	result, err := collection.InsertOne(ctx, user)
@@ -115,12 +122,18 @@ func (r *userRepoStruct) Update(user *model.Users) error {
 
 	filter := bson.M{"_id": user.ID}
 
+	user.Email = model.NormalizeEmail(user.Email)
+	user.PendingEmail = model.NormalizeEmail(user.PendingEmail)
 	update := bson.M{
 		"$set": bson.M{
-			"email":    user.Email,
-			"username": user.Username,
-			"password": user.Password,
-			"role":     user.Role,
+			"email":               user.Email,
+			"pending_email":       user.PendingEmail,
+			"username":            user.Username,
+			"password":            user.Password,
+			"role":                user.Role,
+			"status":              user.Status,
+			"verified_at":         user.VerifiedAt,
+			"password_changed_at": user.PasswordChangedAt,
 		},
 	}
 
@@ -128,6 +141,37 @@ func (r *userRepoStruct) Update(user *model.Users) error {
 	return err
 }
 
+func (r *userRepoStruct) UpdatePasswordHash(id primitive.ObjectID, oldHash, newHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "password": oldHash}
+	_, err := r.getCollection().UpdateOne(ctx, filter, bson.M{"$set": bson.M{"password": newHash}})
+	return err
+}
+
+func (r *userRepoStruct) UpdateStatus(id primitive.ObjectID, status string) error {
+	return r.setField(id, "status", status)
+}
+
+func (r *userRepoStruct) UpdateRole(id primitive.ObjectID, role string) error {
+	return r.setField(id, "role", role)
+}
+
+func (r *userRepoStruct) setField(id primitive.ObjectID, field string, value interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{field: value}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
 func (r *userRepoStruct) Delete(id primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -171,6 +215,135 @@ func (r *userRepoStruct) Count(search string) (int, error) {
 	return int(count), nil
 }
 
+func (r *userRepoStruct) CountByRole(role string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := r.getCollection().CountDocuments(ctx, bson.M{"role": role})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (r *userRepoStruct) CountActiveByRole(role string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"role":   role,
+		"status": bson.M{"$nin": []string{model.UserStatusSuspended, model.UserStatusUnverified}},
+	}
+
+	count, err := r.getCollection().CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// adminLockTTL membatasi umur kunci agar proses yang mati di tengah jalan
+// tidak mengunci perubahan admin selamanya.
+const adminLockTTL = 10 * time.Second
+
+func (r *userRepoStruct) LockAdminChanges() (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	locks := r.client.Database("alumni_management_db").Collection("locks")
+	owner := primitive.NewObjectID()
+	now := time.Now()
+
+	// Kunci yang masih berlaku tidak cocok dengan filter, sehingga upsert
+	// mencoba insert _id yang sama dan gagal dengan duplicate key
+	_, err := locks.UpdateOne(ctx,
+		bson.M{"_id": "admin_changes", "expires_at": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(adminLockTTL)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, model.ErrAdminChangeBusy
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := locks.DeleteOne(ctx, bson.M{"_id": "admin_changes", "owner": owner}); err != nil {
+			log.Printf("gagal melepas kunci perubahan admin: %v", err)
+		}
+	}
+	return unlock, nil
+}
+
+func (r *userRepoStruct) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := r.normalizeEmails(ctx); err != nil {
+		return err
+	}
+
+	_, err := r.getCollection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName("email_unique").SetUnique(true),
+	})
+	return err
+}
+
+// normalizedEmail adalah ekspresi agregasi yang setara dengan
+// model.NormalizeEmail.
+var normalizedEmail = bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$email"}}}
+
+// normalizeEmails menyamakan email lama yang tersimpan dengan huruf besar
+// atau spasi agar pencarian dengan email ternormalisasi tetap menemukannya.
+// Jika dua akun hanya berbeda huruf besar/kecil, migrasi dihentikan dan
+// akun tersebut harus digabung manual lebih dulu.
+func (r *userRepoStruct) normalizeEmails(ctx context.Context) error {
+	collection := r.getCollection()
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"email": bson.M{"$type": "string"}}}},
+		{{Key: "$group", Value: bson.M{"_id": normalizedEmail, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	var collisions []struct {
+		Email string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &collisions); err != nil {
+		return err
+	}
+	if len(collisions) > 0 {
+		emails := make([]string, len(collisions))
+		for i, c := range collisions {
+			emails[i] = c.Email
+		}
+		return fmt.Errorf("email ganda setelah dinormalisasi: %s", strings.Join(emails, ", "))
+	}
+
+	result, err := collection.UpdateMany(ctx,
+		bson.M{
+			"email": bson.M{"$type": "string"},
+			"$expr": bson.M{"$ne": bson.A{"$email", normalizedEmail}},
+		},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"email": normalizedEmail}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Menormalisasi %d email user", result.ModifiedCount)
+	}
+	return nil
+}
+
 func GetUsersRepo(search, sortBy, order string, limit, offset int) ([]model.Users, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -197,7 +370,8 @@ func GetUsersRepo(search, sortBy, order string, limit, offset int) ([]model.User
 	findOptions := options.Find().
 		SetSort(sort).
 		SetLimit(int64(limit)).
-		SetSkip(int64(offset))
+		SetSkip(int64(offset)).
+		SetProjection(withoutPassword)
 
 	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
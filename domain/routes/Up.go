@@ -1,14 +1,17 @@
 package routes
 
 import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
 	"Mongo/domain/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupFileRoutes(api fiber.Router, service service.UploadsService) {
-	api.Post("/upload", service.UploadFile)
-	api.Get("/Files", service.GetAllFiles)
-	api.Get("/Files/:id", service.GetFileByID)
-	api.Delete("deleted/:id", service.DeleteFile)
+func SetupFileRoutes(api fiber.Router, auth *AuthDeps, service service.UploadsService) {
+	api.Post("/upload", JWTAuth(auth), RequirePermission(model.PermFilesWrite), service.UploadFile)
+	api.Get("/Files", JWTAuth(auth), RequirePermission(model.PermFilesRead), service.GetAllFiles)
+	api.Get("/Files/:id", JWTAuth(auth), RequirePermission(model.PermFilesRead), service.GetFileByID)
+	api.Get("/Files/:id/download", JWTAuth(auth), RequirePermission(model.PermFilesRead), service.DownloadFile)
+	api.Delete("deleted/:id", JWTAuth(auth), RequirePermission(model.PermFilesDelete), service.DeleteFile)
 }
@@ -8,11 +8,12 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func Alumni(api fiber.Router, userRepo *model.UserRepository, alumniService *service.AlumniService) {
-    api.Get("/alumni", JWTAuth(userRepo), RequireRole("admin", "user"), alumniService.GetAllAlumniService)
-    api.Get("/alumni/:nim", JWTAuth(userRepo), RequireRole("admin", "user"), alumniService.CheckAlumniService)
-    api.Post("/alumni", JWTAuth(userRepo), RequireRole("admin"), alumniService.CreateAlumniService)
-    api.Put("/alumni/:nim", JWTAuth(userRepo), RequireRole("admin"), alumniService.UpdateAlumniService)
-    api.Delete("/alumni/:nim", JWTAuth(userRepo), RequireRole("admin"), alumniService.DeleteAlumniService)
+func Alumni(api fiber.Router, auth *AuthDeps, alumniService *service.AlumniService) {
+    api.Get("/alumni", JWTAuth(auth), RequirePermission(model.PermAlumniRead), alumniService.GetAllAlumniService)
+    api.Get("/alumni/export", JWTAuth(auth), RequirePermission(model.PermAlumniRead), alumniService.ExportAlumniService)
+    api.Get("/alumni/:nim", JWTAuth(auth), RequirePermission(model.PermAlumniRead), alumniService.CheckAlumniService)
+    api.Post("/alumni", JWTAuth(auth), RequirePermission(model.PermAlumniWrite), alumniService.CreateAlumniService)
+    api.Put("/alumni/:nim", JWTAuth(auth), RequirePermission(model.PermAlumniWrite), alumniService.UpdateAlumniService)
+    api.Delete("/alumni/:nim", JWTAuth(auth), RequirePermission(model.PermAlumniDelete), alumniService.DeleteAlumniService)
 }
 
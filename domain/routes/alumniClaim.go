@@ -0,0 +1,17 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AlumniClaimRoutes(api fiber.Router, auth *AuthDeps, claimService service.AlumniClaimService) {
+	api.Post("/me/alumni-claim", JWTAuth(auth), claimService.CreateClaimHandler())
+	api.Get("/me/alumni-claim", JWTAuth(auth), claimService.GetMyClaimHandler())
+	api.Get("/admin/alumni-claims", JWTAuth(auth), RequirePermission(model.PermAlumniClaimsReview), claimService.ListClaimsHandler())
+	api.Post("/admin/alumni-claims/:id/approve", JWTAuth(auth), RequirePermission(model.PermAlumniClaimsReview), claimService.ApproveClaimHandler())
+	api.Post("/admin/alumni-claims/:id/reject", JWTAuth(auth), RequirePermission(model.PermAlumniClaimsReview), claimService.RejectClaimHandler())
+}
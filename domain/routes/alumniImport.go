@@ -0,0 +1,13 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AlumniImportRoutes(api fiber.Router, auth *AuthDeps, alumniImportService service.AlumniImportService) {
+	api.Post("/admin/alumni/import", JWTAuth(auth), RequirePermission(model.PermAlumniWrite), alumniImportService.ImportAlumniHandler())
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func APIKeyRoutes(api fiber.Router, auth *AuthDeps, apiKeyService service.APIKeyService) {
+	api.Post("/admin/api-keys", JWTAuth(auth), RequireUser(), RequirePermission(model.PermAPIKeysManage), apiKeyService.CreateAPIKeyHandler())
+	api.Get("/admin/api-keys", JWTAuth(auth), RequireUser(), RequirePermission(model.PermAPIKeysManage), apiKeyService.ListAPIKeysHandler())
+	api.Delete("/admin/api-keys/:id", JWTAuth(auth), RequireUser(), RequirePermission(model.PermAPIKeysManage), apiKeyService.RevokeAPIKeyHandler())
+}
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
 	"Mongo/domain/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,9 +11,27 @@ import (
 func AuthRoutes(api fiber.Router, authService service.AuthService) {
 	api.Post("/register", authService.RegisterHandler())
 	api.Post("/login", authService.LoginHandler())
+	api.Post("/login/2fa", authService.LoginTwoFactorHandler())
+	api.Post("/login/2fa/setup", authService.LoginTwoFactorSetupHandler())
+	api.Post("/token/refresh", authService.RefreshHandler())
+	api.Post("/logout", authService.LogoutHandler())
+	api.Get("/auth/oidc/login", authService.OIDCLoginHandler())
+	api.Get("/auth/oidc/callback", authService.OIDCCallbackHandler())
 }
 
-func UserRoutes(api fiber.Router) {
-	api.Get("/users", service.GetUsersService)
+func LoginAttemptRoutes(api fiber.Router, auth *AuthDeps, authService service.AuthService) {
+	api.Post("/admin/login-attempts/unlock", JWTAuth(auth), RequirePermission(model.PermUsersManage), authService.UnlockLoginHandler())
+}
+
+func RoleRoutes(api fiber.Router, auth *AuthDeps, roleService service.RoleService) {
+	api.Get("/admin/permissions", JWTAuth(auth), RequirePermission(model.PermRolesManage), roleService.ListPermissionsHandler())
+	api.Get("/admin/roles", JWTAuth(auth), RequirePermission(model.PermRolesManage), roleService.ListRolesHandler())
+	api.Post("/admin/roles", JWTAuth(auth), RequirePermission(model.PermRolesManage), roleService.CreateRoleHandler())
+	api.Put("/admin/roles/:name", JWTAuth(auth), RequirePermission(model.PermRolesManage), roleService.UpdateRoleHandler())
+	api.Delete("/admin/roles/:name", JWTAuth(auth), RequirePermission(model.PermRolesManage), roleService.DeleteRoleHandler())
+}
+
+func UserRoutes(api fiber.Router, auth *AuthDeps) {
+	api.Get("/users", JWTAuth(auth), RequirePermission(model.PermUsersRead), service.GetUsersService)
 }
 
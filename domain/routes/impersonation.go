@@ -0,0 +1,13 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ImpersonationRoutes(api fiber.Router, auth *AuthDeps, impersonationService service.ImpersonationService) {
+	api.Post("/admin/users/:id/impersonate", JWTAuth(auth), RequirePermission(model.PermUsersImpersonate), impersonationService.StartImpersonationHandler())
+}
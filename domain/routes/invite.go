@@ -0,0 +1,15 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func InviteRoutes(api fiber.Router, auth *AuthDeps, inviteService service.InviteService) {
+	api.Post("/admin/invites", JWTAuth(auth), RequirePermission(model.PermInvitesManage), inviteService.CreateInviteHandler())
+	api.Get("/admin/invites", JWTAuth(auth), RequirePermission(model.PermInvitesManage), inviteService.ListInvitesHandler())
+	api.Post("/register/invite", inviteService.RedeemInviteHandler())
+}
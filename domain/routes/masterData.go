@@ -0,0 +1,17 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MasterDataRoutes(api fiber.Router, auth *AuthDeps, masterDataService service.MasterDataService) {
+	api.Get("/master/:kind", JWTAuth(auth), RequirePermission(model.PermAlumniRead), masterDataService.ListMasterDataHandler())
+	api.Get("/master/:kind/:id", JWTAuth(auth), RequirePermission(model.PermAlumniRead), masterDataService.GetMasterDataHandler())
+	api.Post("/admin/master/:kind", JWTAuth(auth), RequirePermission(model.PermMasterDataManage), masterDataService.CreateMasterDataHandler())
+	api.Put("/admin/master/:kind/:id", JWTAuth(auth), RequirePermission(model.PermMasterDataManage), masterDataService.UpdateMasterDataHandler())
+	api.Delete("/admin/master/:kind/:id", JWTAuth(auth), RequirePermission(model.PermMasterDataManage), masterDataService.DeleteMasterDataHandler())
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PasswordRoutes(api fiber.Router, passwordService service.PasswordService) {
+	api.Post("/password/forgot", passwordService.ForgotPasswordHandler())
+	api.Post("/password/reset", passwordService.ResetPasswordHandler())
+}
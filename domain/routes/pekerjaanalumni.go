@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"Mongo/domain/middleware"
 	. "Mongo/domain/middleware"
 	"Mongo/domain/model"
 	"Mongo/domain/service"
@@ -9,12 +8,14 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func PekerjaanAlumni(api fiber.Router, userRepo *model.UserRepository) {
-api.Get("/pekerjaan", JWTAuth(userRepo), RequireRole("admin", "user"), service.GetAllpekerjaanAlumniService)
-	api.Get("/pekerjaan/:id", JWTAuth(userRepo), RequireRole("admin", "user"), service.CheckpekerjaanAlumniService)
-	api.Post("/pekerjaan", JWTAuth(userRepo), RequireRole("admin"), service.CreatepekerjaanAlumniService)
-	api.Put("/softdeleted/:id", middleware.JWTAuth(userRepo), service.SoftDeleteBynimService)
-	api.Get("/trash", middleware.JWTAuth(userRepo), service.GetAllTrashService)
-	api.Put("/restore/:id", middleware.JWTAuth(userRepo), service.RestoreBynimService)
-	api.Delete("/deleted/:id", middleware.JWTAuth(userRepo), service.DeletePekerjaanAlumniService)
+func PekerjaanAlumni(api fiber.Router, auth *AuthDeps, pekerjaanService *service.PekerjaanAlumniService) {
+	api.Get("/pekerjaan", JWTAuth(auth), RequirePermission(model.PermPekerjaanRead), pekerjaanService.GetAllpekerjaanAlumniService)
+	api.Get("/pekerjaan/export", JWTAuth(auth), RequirePermission(model.PermPekerjaanRead), pekerjaanService.ExportPekerjaanAlumniService)
+	api.Get("/pekerjaan/:id", JWTAuth(auth), RequirePermission(model.PermPekerjaanRead), pekerjaanService.CheckpekerjaanAlumniService)
+	api.Post("/pekerjaan", JWTAuth(auth), RequirePermission(model.PermPekerjaanWrite), pekerjaanService.CreatepekerjaanAlumniService)
+	api.Put("/pekerjaan/:id", JWTAuth(auth), RequirePermission(model.PermPekerjaanWrite), pekerjaanService.UpdatepekerjaanAlumniService)
+	api.Put("/softdeleted/:id", JWTAuth(auth), RequirePermission(model.PermPekerjaanDelete), pekerjaanService.SoftDeleteBynimService)
+	api.Get("/trash", JWTAuth(auth), RequirePermission(model.PermPekerjaanRead), pekerjaanService.GetAllTrashService)
+	api.Put("/restore/:id", JWTAuth(auth), RequirePermission(model.PermPekerjaanRestore), pekerjaanService.RestoreBynimService)
+	api.Delete("/pekerjaan/:id/purge", JWTAuth(auth), RequirePermission(model.PermPekerjaanPurge), pekerjaanService.DeletePekerjaanAlumniService)
 }
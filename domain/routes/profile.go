@@ -0,0 +1,21 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ProfileRoutes(api fiber.Router, auth *AuthDeps, profileService service.ProfileService, twoFactorService service.TwoFactorService, sessionService service.SessionService) {
+	api.Get("/me", JWTAuth(auth), profileService.GetMeHandler())
+	api.Patch("/me", JWTAuth(auth), profileService.UpdateMeHandler())
+	api.Post("/me/password", JWTAuth(auth), profileService.ChangePasswordHandler())
+	api.Get("/me/sessions", JWTAuth(auth), sessionService.ListMySessionsHandler())
+	api.Delete("/me/sessions/:id", JWTAuth(auth), sessionService.RevokeMySessionHandler())
+	api.Get("/me/2fa", JWTAuth(auth), twoFactorService.StatusHandler())
+	api.Post("/me/2fa/setup", JWTAuth(auth), twoFactorService.SetupHandler())
+	api.Post("/me/2fa/confirm", JWTAuth(auth), twoFactorService.ConfirmHandler())
+	api.Post("/me/2fa/disable", JWTAuth(auth), twoFactorService.DisableHandler())
+	api.Post("/me/2fa/recovery-codes", JWTAuth(auth), twoFactorService.RecoveryCodesHandler())
+}
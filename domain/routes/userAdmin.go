@@ -0,0 +1,20 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func UserAdminRoutes(api fiber.Router, auth *AuthDeps, userAdminService service.UserAdminService) {
+	api.Get("/admin/users/:id", JWTAuth(auth), RequirePermission(model.PermUsersRead), userAdminService.GetUserHandler())
+	api.Put("/admin/users/:id", JWTAuth(auth), RequirePermission(model.PermUsersManage), userAdminService.UpdateUserHandler())
+	api.Put("/admin/users/:id/role", JWTAuth(auth), RequirePermission(model.PermUsersManage, model.PermRolesManage), userAdminService.ChangeRoleHandler())
+	api.Post("/admin/users/:id/suspend", JWTAuth(auth), RequirePermission(model.PermUsersManage), userAdminService.SuspendUserHandler())
+	api.Post("/admin/users/:id/reactivate", JWTAuth(auth), RequirePermission(model.PermUsersManage), userAdminService.ReactivateUserHandler())
+	api.Delete("/admin/users/:id", JWTAuth(auth), RequirePermission(model.PermUsersManage), userAdminService.DeleteUserHandler())
+	api.Get("/admin/users/:id/sessions", JWTAuth(auth), RequirePermission(model.PermUsersRead), userAdminService.ListSessionsHandler())
+	api.Delete("/admin/users/:id/sessions", JWTAuth(auth), RequirePermission(model.PermUsersManage), userAdminService.RevokeSessionsHandler())
+}
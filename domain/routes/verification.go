@@ -0,0 +1,16 @@
+package routes
+
+import (
+	. "Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func VerificationRoutes(api fiber.Router, auth *AuthDeps, verificationService service.VerificationService) {
+	api.Get("/email/verify", verificationService.VerifyEmailHandler())
+	api.Post("/email/verify", verificationService.VerifyEmailHandler())
+	api.Post("/admin/users/:id/verification", JWTAuth(auth), RequirePermission(model.PermUsersManage), verificationService.ResendVerificationHandler())
+	api.Post("/admin/users/:id/verify", JWTAuth(auth), RequirePermission(model.PermUsersManage), verificationService.VerifyUserHandler())
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func WellKnownRoutes(app fiber.Router) {
+	app.Get("/.well-known/jwks.json", service.JWKSHandler)
+}
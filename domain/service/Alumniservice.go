@@ -1,25 +1,88 @@
 package service
 
 import (
+	"Mongo/domain/middleware"
 	"Mongo/domain/model"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AlumniService struct {
-	repo model.AlumniRepository
+	repo       model.AlumniRepository
+	masterRepo model.MasterDataRepository
 }
 
-func NewAlumniService(repo model.AlumniRepository) *AlumniService {
+func NewAlumniService(repo model.AlumniRepository, masterRepo model.MasterDataRepository) *AlumniService {
     return &AlumniService{
-        repo: repo,
+        repo:       repo,
+        masterRepo: masterRepo,
     }
 }
 
+// alumniSortWhitelist adalah field yang boleh dipakai di sortBy.
+var alumniSortWhitelist = map[string]bool{"nim": true, "nama": true, "angkatan": true, "tahun_lulus": true, "created_at": true}
+
+// alumniMaxLimit membatasi ukuran satu halaman daftar alumni.
+const alumniMaxLimit = 100
+
+// alumniFilterMaxValues membatasi jumlah nilai dalam satu filter daftar.
+const alumniFilterMaxValues = 50
+
+// @Summary Dapatkan daftar alumni
+// @Description Mengambil daftar alumni per halaman dengan pencarian NIM/nama dan pengurutan
+// @Tags Alumni
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman (maks 100)" default(10)
+// @Param sortBy query string false "nim, nama, angkatan, tahun_lulus, created_at" default(nim)
+// @Param order query string false "asc atau desc" default(asc)
+// @Param search query string false "Cari berdasarkan NIM atau nama"
+// @Param angkatan query string false "Daftar angkatan dipisah koma, mis. 2017,2018"
+// @Param angkatan_from query int false "Angkatan minimal (inklusif)"
+// @Param angkatan_to query int false "Angkatan maksimal (inklusif)"
+// @Param tahun_lulus query string false "Daftar tahun lulus dipisah koma"
+// @Param tahun_lulus_from query int false "Tahun lulus minimal (inklusif)"
+// @Param tahun_lulus_to query int false "Tahun lulus maksimal (inklusif)"
+// @Param id_fakultas query string false "Daftar ID fakultas dipisah koma"
+// @Param id_prodi query string false "Daftar ID prodi dipisah koma"
+// @Param id_sumber query string false "Daftar ID sumber dipisah koma"
+// @Param sumber query string false "Daftar sumber dipisah koma, mis. SNMPTN"
+// @Param expand query bool false "Sertakan nama fakultas, prodi, dan sumber"
+// @Success 200 {object} model.AlumniResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/alumni [get]
 func (s *AlumniService) GetAllAlumniService(c *fiber.Ctx) error {
-    // Panggil method dari interface repo
-    alumniList, err := s.repo.GetAllAlumni()
+    page, _ := strconv.Atoi(c.Query("page", "1"))
+    limit, _ := strconv.Atoi(c.Query("limit", "10"))
+    sortBy, order := parseAlumniSort(c)
+
+    filter, err := parseAlumniFilter(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "message": err.Error(),
+            "success": false,
+        })
+    }
+
+    if page < 1 {
+        page = 1
+    }
+    if limit < 1 {
+        limit = 10
+    }
+    if limit > alumniMaxLimit {
+        limit = alumniMaxLimit
+    }
+    offset := (page - 1) * limit
+
+    alumniList, err := s.repo.GetAllAlumni(filter, sortBy, order, limit, offset)
     if err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "message": "Gagal mendapatkan daftar alumni karena " + err.Error(),
@@ -27,13 +90,147 @@ func (s *AlumniService) GetAllAlumniService(c *fiber.Ctx) error {
         })
     }
 
-    return c.Status(fiber.StatusOK).JSON(fiber.Map{
-        "message": "Berhasil mendapatkan daftar alumni",
-        "success": true,
-        "alumni":  alumniList,
+    total, err := s.repo.CountAlumni(filter)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "message": "Gagal menghitung jumlah alumni karena " + err.Error(),
+            "success": false,
+        })
+    }
+
+    names, err := s.masterNames(c, alumniList)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "message": "Gagal memuat master data karena " + err.Error(),
+            "success": false,
+        })
+    }
+
+    return c.Status(fiber.StatusOK).JSON(model.AlumniResponse{
+        Message: "Berhasil mendapatkan daftar alumni",
+        Success: true,
+        Alumni:  alumniViews(c, alumniList, names),
+        MetaInfo: model.MetaInfo{
+            CurrentPage: page,
+            Limit:       limit,
+            Total:       total,
+            Pages:       (total + limit - 1) / limit,
+            SortBy:      sortBy,
+            Order:       order,
+            Search:      filter.Search,
+        },
     })
 }
 
+// parseAlumniSort membaca sortBy dan order; field di luar whitelist
+// kembali ke NIM.
+func parseAlumniSort(c *fiber.Ctx) (string, string) {
+    sortBy := c.Query("sortBy", "nim")
+    order := strings.ToLower(c.Query("order", "asc"))
+
+    if !alumniSortWhitelist[sortBy] {
+        sortBy = "nim"
+    }
+    if order != "desc" {
+        order = "asc"
+    }
+    return sortBy, order
+}
+
+// parseAlumniFilter membaca pencarian dan filter terstruktur dari query
+// string. Nilai yang tidak valid ditolak agar filter tidak diam-diam
+// diabaikan.
+func parseAlumniFilter(c *fiber.Ctx) (model.AlumniFilter, error) {
+    f := model.AlumniFilter{Search: strings.TrimSpace(c.Query("search", ""))}
+    var err error
+
+    if f.Angkatan, err = parseIntList(c, "angkatan"); err != nil {
+        return f, err
+    }
+    if f.AngkatanFrom, err = parseOptionalInt(c, "angkatan_from"); err != nil {
+        return f, err
+    }
+    if f.AngkatanTo, err = parseOptionalInt(c, "angkatan_to"); err != nil {
+        return f, err
+    }
+    if f.TahunLulus, err = parseIntList(c, "tahun_lulus"); err != nil {
+        return f, err
+    }
+    if f.TahunLulusFrom, err = parseOptionalInt(c, "tahun_lulus_from"); err != nil {
+        return f, err
+    }
+    if f.TahunLulusTo, err = parseOptionalInt(c, "tahun_lulus_to"); err != nil {
+        return f, err
+    }
+    if f.IDFakultas, err = parseIntList(c, "id_fakultas"); err != nil {
+        return f, err
+    }
+    if f.IDProdi, err = parseIntList(c, "id_prodi"); err != nil {
+        return f, err
+    }
+    if f.IDSumber, err = parseIntList(c, "id_sumber"); err != nil {
+        return f, err
+    }
+    if f.Sumber, err = parseStringList(c, "sumber"); err != nil {
+        return f, err
+    }
+
+    if f.AngkatanFrom != nil && f.AngkatanTo != nil && *f.AngkatanFrom > *f.AngkatanTo {
+        return f, errors.New("angkatan_from tidak boleh lebih besar dari angkatan_to")
+    }
+    if f.TahunLulusFrom != nil && f.TahunLulusTo != nil && *f.TahunLulusFrom > *f.TahunLulusTo {
+        return f, errors.New("tahun_lulus_from tidak boleh lebih besar dari tahun_lulus_to")
+    }
+    return f, nil
+}
+
+func parseStringList(c *fiber.Ctx, key string) ([]string, error) {
+    raw := strings.TrimSpace(c.Query(key))
+    if raw == "" {
+        return nil, nil
+    }
+
+    var values []string
+    for _, part := range strings.Split(raw, ",") {
+        if part = strings.TrimSpace(part); part != "" {
+            values = append(values, part)
+        }
+    }
+    if len(values) > alumniFilterMaxValues {
+        return nil, fmt.Errorf("%s maksimal berisi %d nilai", key, alumniFilterMaxValues)
+    }
+    return values, nil
+}
+
+func parseIntList(c *fiber.Ctx, key string) ([]int, error) {
+    parts, err := parseStringList(c, key)
+    if err != nil {
+        return nil, err
+    }
+
+    var values []int
+    for _, part := range parts {
+        n, err := strconv.Atoi(part)
+        if err != nil {
+            return nil, fmt.Errorf("%s harus berupa angka", key)
+        }
+        values = append(values, n)
+    }
+    return values, nil
+}
+
+func parseOptionalInt(c *fiber.Ctx, key string) (*int, error) {
+    raw := strings.TrimSpace(c.Query(key))
+    if raw == "" {
+        return nil, nil
+    }
+    n, err := strconv.Atoi(raw)
+    if err != nil {
+        return nil, fmt.Errorf("%s harus berupa angka", key)
+    }
+    return &n, nil
+}
+
 // HandleGetAllUsers godoc
 // @Summary Dapatkan semua Alumni
 // @Description Mengambil daftar semua Alumni dari database
@@ -43,7 +240,6 @@ func (s *AlumniService) GetAllAlumniService(c *fiber.Ctx) error {
 // @Failure 400 {object} model.ErrorResponse
 // @Param credentials body model.Alumni true "Data Alumni"
 // @Success 200 {array} model.Alumni
-// @Router /api/alumni [get]
 // @Router /api/alumni/:nim [get]
 // @Router /api/alumni [post]
 // @Router /api/alumni/:nim [put]
@@ -72,22 +268,31 @@ func (s *AlumniService) CheckAlumniService(c *fiber.Ctx) error {
             "success": false,
         })
     }
+
+    names, err := s.masterNames(c, []model.Alumni{*alumni})
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "message": "Gagal memuat master data karena " + err.Error(),
+            "success": false,
+        })
+    }
     return c.Status(fiber.StatusOK).JSON(fiber.Map{
         "message":  "Berhasil mendapatkan data alumni",
         "success":  true,
         "isAlumni": true,
-        "alumni":   alumni,
+        "alumni":   alumniView(c, alumni, names),
     })
 }
 
 func (s *AlumniService) CreateAlumniService(c *fiber.Ctx) error {
-    var alumni model.Alumni
-    if err := c.BodyParser(&alumni); err != nil {
+    var body model.SaveAlumni
+    if err := c.BodyParser(&body); err != nil {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
             "message": "Invalid request body",
             "success": false,
         })
     }
+    alumni := body.Alumni()
 
     if alumni.NIM == "" {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -96,8 +301,21 @@ func (s *AlumniService) CreateAlumniService(c *fiber.Ctx) error {
         })
     }
 
+    if status, msg := validateAlumniRefs(s.masterRepo, &alumni); status != 0 {
+        return c.Status(status).JSON(fiber.Map{
+            "message": msg,
+            "success": false,
+        })
+    }
+
     // Panggil method dari interface repo
     if err := s.repo.CreateAlumni(&alumni); err != nil {
+        if mongo.IsDuplicateKeyError(err) {
+            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+                "message": "NIM sudah terdaftar",
+                "success": false,
+            })
+        }
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "message": "Gagal membuat alumni karena " + err.Error(),
             "success": false,
@@ -107,7 +325,7 @@ func (s *AlumniService) CreateAlumniService(c *fiber.Ctx) error {
     return c.Status(fiber.StatusCreated).JSON(fiber.Map{
         "message": "Berhasil membuat data alumni",
         "success": true,
-        "alumni":  alumni,
+        "alumni":  model.NewAdminAlumni(&alumni),
     })
 }
 
@@ -120,16 +338,38 @@ func (s *AlumniService) UpdateAlumniService(c *fiber.Ctx) error {
         })
     }
 
-    var alumni model.Alumni
-    if err := c.BodyParser(&alumni); err != nil {
+    var body model.SaveAlumni
+    if err := c.BodyParser(&body); err != nil {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
             "message": "Invalid request body",
             "success": false,
         })
     }
+    alumni := body.Alumni()
+    // NIM adalah kunci unik alumni dan tidak diganti lewat update
+    if alumni.NIM != "" && alumni.NIM != nim {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "message": "NIM tidak dapat diubah",
+            "success": false,
+        })
+    }
+    alumni.NIM = nim
+
+    if status, msg := validateAlumniRefs(s.masterRepo, &alumni); status != 0 {
+        return c.Status(status).JSON(fiber.Map{
+            "message": msg,
+            "success": false,
+        })
+    }
 
     // Panggil method dari interface repo
     if err := s.repo.UpdateAlumni(nim, &alumni); err != nil {
+        if errors.Is(err, mongo.ErrNoDocuments) {
+            return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+                "message": "Alumni tidak ditemukan",
+                "success": false,
+            })
+        }
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "message": "Gagal update alumni karena " + err.Error(),
             "success": false,
@@ -139,7 +379,7 @@ func (s *AlumniService) UpdateAlumniService(c *fiber.Ctx) error {
     return c.Status(fiber.StatusOK).JSON(fiber.Map{
         "message": "Berhasil update data alumni",
         "success": true,
-        "alumni":  alumni,
+        "alumni":  model.NewAdminAlumni(&alumni),
     })
 }
 
@@ -166,3 +406,37 @@ func (s *AlumniService) DeleteAlumniService(c *fiber.Ctx) error {
     })
 }
 
+
+// masterNames memuat nama master data hanya jika diminta lewat ?expand=true.
+func (s *AlumniService) masterNames(c *fiber.Ctx, list []model.Alumni) (*model.MasterNames, error) {
+    if !c.QueryBool("expand") {
+        return nil, nil
+    }
+    return loadMasterNames(s.masterRepo, list)
+}
+
+// alumniView memilih DTO sesuai hak pemanggil: relasi akun dan sumber data
+// hanya terlihat oleh pemegang alumni:write. Nama master data diisi jika
+// names tidak nil.
+func alumniView(c *fiber.Ctx, alumni *model.Alumni, names *model.MasterNames) interface{} {
+    if principal, ok := middleware.GetPrincipal(c); ok && principal.HasPermission(model.PermAlumniWrite) {
+        view := model.NewAdminAlumni(alumni)
+        if names != nil {
+            view.Expand(names)
+        }
+        return view
+    }
+    view := model.NewPublicAlumni(alumni)
+    if names != nil {
+        view.Expand(names)
+    }
+    return view
+}
+
+func alumniViews(c *fiber.Ctx, list []model.Alumni, names *model.MasterNames) []interface{} {
+    views := make([]interface{}, 0, len(list))
+    for i := range list {
+        views = append(views, alumniView(c, &list[i], names))
+    }
+    return views
+}
@@ -1,19 +1,27 @@
 package service
 
 import (
-	"Mongo/domain/config"
+	"Mongo/domain/middleware"
 	"Mongo/domain/model"
-	. "Mongo/domain/repository"
+	"context"
 	"errors"
-	"fmt"
-	"strconv"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+type PekerjaanAlumniService struct {
+	repo   model.PekerjaanAlumniRepository
+	policy *OwnershipPolicy
+}
+
+func NewPekerjaanAlumniService(repo model.PekerjaanAlumniRepository, policy *OwnershipPolicy) *PekerjaanAlumniService {
+	return &PekerjaanAlumniService{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
 // @Summary Dapatkan semua Pekerjaan Alumni
 // @Description Mengambil daftar semua Pekerjaan Alumni dari database
 // @Accept json
@@ -22,19 +30,86 @@ import (
 // @Failure 400 {object} model.ErrorResponse
 // @Success 200 {array} model.PekerjaanAlumni
 // @Router /api/pekerjaan [get]
-func GetAllpekerjaanAlumniService(c *fiber.Ctx) error {
-	pekerjaanList, err := GetAllpekerjaanAlumni()
+func (s *PekerjaanAlumniService) GetAllpekerjaanAlumniService(c *fiber.Ctx) error {
+	pekerjaanList, err := s.repo.FindAll()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Gagal mendapatkan daftar pekerjaan alumni karena " + err.Error(),
 			"success": false,
 		})
 	}
-	
+
+	principal, _ := middleware.GetPrincipal(c)
+	views, err := s.policy.ViewPekerjaan(principal, pekerjaanList)
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":   "Berhasil mendapatkan daftar pekerjaan alumni",
 		"success":   true,
-		"pekerjaan": pekerjaanList,
+		"pekerjaan": views,
+	})
+}
+
+// @Summary Export pekerjaan alumni
+// @Description Data yang sama dengan GET /api/pekerjaan. CSV dan XLSX dialirkan langsung dari database; PDF dibatasi 5000 baris. Gaji hanya terisi untuk data yang boleh dilihat pemanggil.
+// @Tags PekerjaanAlumni
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param format query string false "csv, xlsx, atau pdf" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/pekerjaan/export [get]
+func (s *PekerjaanAlumniService) ExportPekerjaanAlumniService(c *fiber.Ctx) error {
+	format, ok := exportFormat(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "format harus csv, xlsx, atau pdf",
+			"success": false,
+		})
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	canSeeSalary, err := s.policy.SalaryVisibility(principal)
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
+	cursor, err := s.repo.OpenCursor()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Gagal membaca data pekerjaan alumni karena " + err.Error(),
+			"success": false,
+		})
+	}
+
+	return streamExport(c, format, exportTable{
+		Name:   "pekerjaan-alumni",
+		Title:  "Data Pekerjaan Alumni",
+		Header: []string{"NIM", "Status Kerja", "Jenis Industri", "Jabatan", "Pekerjaan", "Gaji", "Lama Bekerja"},
+		Rows: func(ctx context.Context, emit func([]interface{}) error) error {
+			defer cursor.Close(context.Background())
+
+			for cursor.Next(ctx) {
+				var p model.PekerjaanAlumni
+				if err := cursor.Decode(&p); err != nil {
+					return err
+				}
+
+				var gaji interface{}
+				if canSeeSalary(p.NimAlumni) {
+					gaji = p.Gaji
+				}
+				row := []interface{}{p.NimAlumni, p.StatusKerja, p.JenisIndustri, p.Jabatan, p.Pekerjaan, gaji, p.LamaBekerja}
+				if err := emit(row); err != nil {
+					return err
+				}
+			}
+			return cursor.Err()
+		},
 	})
 }
 
@@ -44,7 +119,7 @@ func GetAllpekerjaanAlumniService(c *fiber.Ctx) error {
 // @Failure 400 {object} model.ErrorResponse
 // @Success 200 {array} model.PekerjaanAlumni
 // @Router /api/pekerjaan/:id [get]
-func CheckpekerjaanAlumniService(c *fiber.Ctx) error {
+func (s *PekerjaanAlumniService) CheckpekerjaanAlumniService(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -53,7 +128,7 @@ func CheckpekerjaanAlumniService(c *fiber.Ctx) error {
 		})
 	}
 
-	pekerjaan, err := CheckpekerjaanAlumniByID(id)
+	pekerjaan, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -74,11 +149,17 @@ func CheckpekerjaanAlumniService(c *fiber.Ctx) error {
 		})
 	}
 
+	principal, _ := middleware.GetPrincipal(c)
+	views, err := s.policy.ViewPekerjaan(principal, []model.PekerjaanAlumni{*pekerjaan})
+	if err != nil {
+		return ownershipError(c, err)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":   "Berhasil mendapatkan data pekerjaan alumni",
 		"success":   true,
 		"exists":    true,
-		"pekerjaan": pekerjaan,
+		"pekerjaan": views[0],
 	})
 }
 
@@ -89,7 +170,7 @@ func CheckpekerjaanAlumniService(c *fiber.Ctx) error {
 // @Param credentials body model.PekerjaanAlumni true "Data Pekerjaan Alumni"
 // @Success 200 {array} model.PekerjaanAlumni
 // @Router /api/pekerjaan [post]
-func CreatepekerjaanAlumniService(c *fiber.Ctx) error {
+func (s *PekerjaanAlumniService) CreatepekerjaanAlumniService(c *fiber.Ctx) error {
 	var pekerjaan model.PekerjaanAlumni
 	if err := c.BodyParser(&pekerjaan); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -97,21 +178,31 @@ func CreatepekerjaanAlumniService(c *fiber.Ctx) error {
 			"success": false,
 		})
 	}
-	
+
 	if pekerjaan.NimAlumni == "" || pekerjaan.StatusKerja == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "IDAlumni dan StatusKerja wajib diisi",
 			"success": false,
 		})
 	}
-	
-	if err := CreatepekerjaanAlumni(&pekerjaan); err != nil {
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
+	}
+	if allowed, err := s.policy.CanManagePekerjaan(principal, &pekerjaan); err != nil {
+		return ownershipError(c, err)
+	} else if !allowed {
+		return denyOwnership(c)
+	}
+
+	if err := s.repo.Create(&pekerjaan); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Gagal membuat pekerjaan alumni karena " + err.Error(),
 			"success": false,
 		})
 	}
-	
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message":   "Berhasil membuat data pekerjaan alumni",
 		"success":   true,
@@ -119,7 +210,15 @@ func CreatepekerjaanAlumniService(c *fiber.Ctx) error {
 	})
 }
 
-func UpdatepekerjaanAlumniService(c *fiber.Ctx) error {
+// @Accept json
+// @Produce json
+// @Tags PekerjaanAlumni
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Param credentials body model.PekerjaanAlumni true "Data Pekerjaan Alumni"
+// @Success 200 {object} model.PekerjaanAlumni
+// @Router /api/pekerjaan/:id [put]
+func (s *PekerjaanAlumniService) UpdatepekerjaanAlumniService(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -136,20 +235,49 @@ func UpdatepekerjaanAlumniService(c *fiber.Ctx) error {
 		})
 	}
 
-	if pekerjaan.ID.IsZero() || pekerjaan.StatusKerja == "" {
+	if pekerjaan.StatusKerja == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "ID dan StatusKerja wajib diisi",
+			"message": "StatusKerja wajib diisi",
 			"success": false,
 		})
 	}
 
-	if err := UpdatepekerjaanAlumni(id, &pekerjaan); err != nil {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
+	}
+
+	existing, status, err := s.loadPekerjaan(id)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+			"success": false,
+		})
+	}
+
+	// Data lama dan NIM tujuan sama-sama harus milik pemanggil, supaya
+	// pekerjaan tidak bisa "dipindahkan" ke alumni lain.
+	if pekerjaan.NimAlumni == "" {
+		pekerjaan.NimAlumni = existing.NimAlumni
+	}
+	for _, target := range []*model.PekerjaanAlumni{existing, &pekerjaan} {
+		allowed, err := s.policy.CanManagePekerjaan(principal, target)
+		if err != nil {
+			return ownershipError(c, err)
+		}
+		if !allowed {
+			return denyOwnership(c)
+		}
+	}
+
+	if err := s.repo.Update(id, &pekerjaan); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Gagal update pekerjaan alumni karena " + err.Error(),
 			"success": false,
 		})
 	}
 
+	pekerjaan.ID = existing.ID
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":   "Berhasil update data pekerjaan alumni",
 		"success":   true,
@@ -164,7 +292,7 @@ func UpdatepekerjaanAlumniService(c *fiber.Ctx) error {
 // @Param credentials body model.PekerjaanAlumni true "Data Pekerjaan Alumni"
 // @Success 200 {array} model.PekerjaanAlumni
 // @Router /api/softdeleted/:id [put]
-func SoftDeleteBynimService(c *fiber.Ctx) error {
+func (s *PekerjaanAlumniService) SoftDeleteBynimService(c *fiber.Ctx) error {
 	nim := c.Params("id")
 	if nim == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -173,37 +301,17 @@ func SoftDeleteBynimService(c *fiber.Ctx) error {
 		})
 	}
 
-	userRole, okRole := c.Locals("role").(string)
-	loggedInUserID, okUser := c.Locals("id").(int)
-	loggedInIDString := strconv.Itoa(loggedInUserID)
-
-	if !okRole || !okUser || userRole == "" || loggedInUserID == 0 {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Data otentikasi tidak lengkap atau tidak valid",
-			"success": false,
-		})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
 	}
-
-	if userRole == "admin" {
-		fmt.Println("HASIL: Akses diberikan (ADMIN)")
-	} else if userRole == "user" {
-		if loggedInIDString != nim {
-			fmt.Println("HASIL: Akses DITOLAK (NIM tidak cocok)")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "Akses ditolak: Anda hanya dapat menghapus data Anda sendiri",
-				"success": false,
-			})
-		}
-		fmt.Println("HASIL: Akses diberikan (USER, ID/NIM cocok)")
-	} else {
-		fmt.Println("HASIL: Akses DITOLAK (Role tidak dikenal)")
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"message": "Akses ditolak: Role tidak diizinkan",
-			"success": false,
-		})
+	if allowed, err := s.policy.CanManagePekerjaan(principal, &model.PekerjaanAlumni{NimAlumni: nim}); err != nil {
+		return ownershipError(c, err)
+	} else if !allowed {
+		return denyOwnership(c)
 	}
 
-	if err := SoftDeleteBynim(nim); err != nil {
+	if err := s.repo.SoftDeleteByNIM(nim); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Gagal menghapus pekerjaan alumni karena " + err.Error(),
 			"success": false,
@@ -223,56 +331,33 @@ func SoftDeleteBynimService(c *fiber.Ctx) error {
 // @Failure 400 {object} model.ErrorResponse
 // @Success 200 {array} model.PekerjaanAlumni
 // @Router /api/tarsh [get]
-func GetAllTrashService(c *fiber.Ctx) error {
-	userID, ok := c.Locals("id").(int)
-	if !ok || userID == 0 {
+func (s *PekerjaanAlumniService) GetAllTrashService(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "user ID tidak ditemukan di token",
 		})
 	}
 
-	role, _ := c.Locals("role").(string)
-
 	var nimAlumni string
 
-	if role != "admin" {
-
-		ctx := c.Context()
-
-		alumniCollection := config.DB.Database("alumni_management_db").Collection("alumni")
-		userIDStr, okStr := c.Locals("id").(string)
-		if !okStr {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Format user ID di token tidak valid (bukan string/ObjectID)",
-			})
-		}
-
-		objID, err := primitive.ObjectIDFromHex(userIDStr)
+	// Tanpa pekerjaan:manage_all, trash dibatasi ke alumni milik pemanggil
+	if !principal.HasPermission(model.PermPekerjaanManageAll) {
+		caller, err := s.policy.CallerAlumni(principal)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "User ID di token bukan ObjectID yang valid",
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
 			})
 		}
-
-		var alumni model.Alumni
-		filter := bson.M{"user_id": objID}
-
-		err = alumniCollection.FindOne(ctx, filter).Decode(&alumni)
-
-		if errors.Is(err, mongo.ErrNoDocuments) {
+		if caller == nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "data alumni tidak ditemukan untuk user ini",
 			})
-		} else if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Gagal mencari data alumni: " + err.Error(),
-			})
 		}
-
-		nimAlumni = alumni.NIM
+		nimAlumni = caller.NIM
 	}
 
-	trashes, err := GetAllTrash(nimAlumni)
+	trashes, err := s.repo.FindTrash(nimAlumni)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -289,7 +374,7 @@ func GetAllTrashService(c *fiber.Ctx) error {
 // @Failure 400 {object} model.ErrorResponse
 // @Success 200 {array} model.PekerjaanAlumni
 // @Router /api/restore/:id [put]
-func RestoreBynimService(c *fiber.Ctx) error {
+func (s *PekerjaanAlumniService) RestoreBynimService(c *fiber.Ctx) error {
 	nim := c.Params("id")
 	if nim == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -298,37 +383,17 @@ func RestoreBynimService(c *fiber.Ctx) error {
 		})
 	}
 
-	userRole, okRole := c.Locals("role").(string)
-	loggedInUserID, okUser := c.Locals("id").(int)
-	loggedInIDString := strconv.Itoa(loggedInUserID)
-
-	if !okRole || !okUser || userRole == "" || loggedInUserID == 0 {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Data otentikasi tidak lengkap atau tidak valid",
-			"success": false,
-		})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
 	}
-
-	if userRole == "admin" {
-		fmt.Println("HASIL: Akses diberikan (ADMIN)")
-	} else if userRole == "user" {
-		if loggedInIDString != nim {
-			fmt.Println("HASIL: Akses DITOLAK (NIM tidak cocok)")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "Akses ditolak: Anda hanya dapat mengembalikan data Anda sendiri",
-				"success": false,
-			})
-		}
-		fmt.Println("HASIL: Akses diberikan (USER, ID/NIM cocok)")
-	} else {
-		fmt.Println("HASIL: Akses DITOLAK (Role tidak dikenal)")
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"message": "Akses ditolak: Role tidak diizinkan",
-			"success": false,
-		})
+	if allowed, err := s.policy.CanManagePekerjaan(principal, &model.PekerjaanAlumni{NimAlumni: nim}); err != nil {
+		return ownershipError(c, err)
+	} else if !allowed {
+		return denyOwnership(c)
 	}
 
-	if err := RestoreTrashBynim(nim); err != nil {
+	if err := s.repo.RestoreByNIM(nim); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Gagal mengembalikan pekerjaan alumni karena " + err.Error(),
 			"success": false,
@@ -347,47 +412,36 @@ func RestoreBynimService(c *fiber.Ctx) error {
 // @Param credentials body model.PekerjaanAlumni true "Data Pekerjaan Alumni"
 // @Failure 400 {object} model.ErrorResponse
 // @Success 200 {array} model.PekerjaanAlumni
-// @Router /api/delete/:id [delete]
-func DeletePekerjaanAlumniService(c *fiber.Ctx) error {
-	nim := c.Params("id")
-	if nim == "" {
+// @Router /api/pekerjaan/:id/purge [delete]
+func (s *PekerjaanAlumniService) DeletePekerjaanAlumniService(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "NIM wajib diisi",
+			"message": "ID wajib diisi",
 			"success": false,
 		})
 	}
 
-	userRole, okRole := c.Locals("role").(string)
-	loggedInUserID, okUser := c.Locals("id").(int)
-	loggedInIDString := strconv.Itoa(loggedInUserID)
-
-	if !okRole || !okUser || userRole == "" || loggedInUserID == 0 {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "Data otentikasi tidak lengkap atau tidak valid",
-			"success": false,
-		})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
 	}
 
-	if userRole == "admin" {
-		fmt.Println("HASIL: Akses diberikan (ADMIN)")
-	} else if userRole == "user" {
-		if loggedInIDString != nim {
-			fmt.Println("HASIL: Akses DITOLAK (NIM tidak cocok)")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "Akses ditolak: Anda hanya dapat menghapus data Anda sendiri",
-				"success": false,
-			})
-		}
-		fmt.Println("HASIL: Akses diberikan (USER, ID/NIM cocok)")
-	} else {
-		fmt.Println("HASIL: Akses DITOLAK (Role tidak dikenal)")
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"message": "Akses ditolak: Role tidak diizinkan",
+	// Kepemilikan dicek dari NIM pada data yang tersimpan, bukan dari parameter
+	existing, status, err := s.loadPekerjaan(id)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
 			"success": false,
 		})
 	}
+	if allowed, err := s.policy.CanManagePekerjaan(principal, existing); err != nil {
+		return ownershipError(c, err)
+	} else if !allowed {
+		return denyOwnership(c)
+	}
 
-	if err := DeletePekerjaanByid(nim); err != nil {
+	if err := s.repo.Delete(id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Gagal menghapus pekerjaan alumni karena " + err.Error(),
 			"success": false,
@@ -399,3 +453,19 @@ func DeletePekerjaanAlumniService(c *fiber.Ctx) error {
 		"success": true,
 	})
 }
+
+// loadPekerjaan mengambil data pekerjaan beserta status HTTP yang cocok
+// jika gagal, supaya handler update dan delete berperilaku sama.
+func (s *PekerjaanAlumniService) loadPekerjaan(id string) (*model.PekerjaanAlumni, int, error) {
+	pekerjaan, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fiber.StatusNotFound, errors.New("Data pekerjaan alumni tidak ditemukan")
+		}
+		if err.Error() == "invalid job ID format" {
+			return nil, fiber.StatusBadRequest, errors.New("Format ID pekerjaan alumni tidak valid")
+		}
+		return nil, fiber.StatusInternalServerError, errors.New("Gagal cek pekerjaan alumni karena " + err.Error())
+	}
+	return pekerjaan, fiber.StatusOK, nil
+}
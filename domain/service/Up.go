@@ -1,6 +1,7 @@
 package service
 
 import (
+	"Mongo/domain/middleware"
 	"Mongo/domain/model"
 	"Mongo/domain/repository"
 	"fmt"
@@ -15,6 +16,7 @@ type UploadsService interface {
 	UploadFile(c *fiber.Ctx) error
 	GetAllFiles(c *fiber.Ctx) error
 	GetFileByID(c *fiber.Ctx) error
+	DownloadFile(c *fiber.Ctx) error
 	DeleteFile(c *fiber.Ctx) error
 }
 type upService struct {
@@ -38,6 +40,11 @@ func NewUploadsService(repo repository.UploadsRepository, uploadPath string) Upl
 	}
 }
 func (s *upService) UploadFile(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
+	}
+
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -117,6 +124,7 @@ func (s *upService) UploadFile(c *fiber.Ctx) error {
 		UploadsPath:  filePath,
 		UploadsSize:  fileHeader.Size,
 		UploadsType:  contentType,
+		UploadedBy:   principal.UserID,
 	}
 	if err := s.repo.Create(UploadsModel); err != nil {
 		// Hapus file jika gagal simpan ke database
@@ -135,7 +143,19 @@ func (s *upService) UploadFile(c *fiber.Ctx) error {
 }
 
 func (s *upService) GetAllFiles(c *fiber.Ctx) error {
-	files, err := s.repo.FindAll()
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return unauthenticated(c)
+	}
+
+	// Selain peninjau klaim, user hanya melihat file unggahannya sendiri
+	var files []model.Uploads
+	var err error
+	if principal.HasPermission(model.PermAlumniClaimsReview) {
+		files, err = s.repo.FindAll()
+	} else {
+		files, err = s.repo.FindByUploader(principal.UserID)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -155,13 +175,11 @@ func (s *upService) GetAllFiles(c *fiber.Ctx) error {
 }
 
 func (s *upService) GetFileByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	file, err := s.repo.FindByID(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	file, status, msg := s.loadFile(c)
+	if file == nil {
+		return c.Status(status).JSON(fiber.Map{
 			"success": false,
-			"message": "File not found",
-			"error":   err.Error(),
+			"message": msg,
 		})
 	}
 	return c.JSON(fiber.Map{
@@ -171,14 +189,26 @@ func (s *upService) GetFileByID(c *fiber.Ctx) error {
 	})
 }
 
+// DownloadFile mengirim isi file sebagai lampiran. File tidak disajikan
+// langsung dari folder upload agar aturan akses yang sama tetap berlaku.
+func (s *upService) DownloadFile(c *fiber.Ctx) error {
+	file, status, msg := s.loadFile(c)
+	if file == nil {
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": msg,
+		})
+	}
+	return c.Download(file.UploadsPath, file.OriginalName)
+}
+
 func (s *upService) DeleteFile(c *fiber.Ctx) error {
 	id := c.Params("id")
-	file, err := s.repo.FindByID(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	file, status, msg := s.loadFile(c)
+	if file == nil {
+		return c.Status(status).JSON(fiber.Map{
 			"success": false,
-			"message": "File not found",
-			"error":   err.Error(),
+			"message": msg,
 		})
 	}
 	// Hapus file dari storage
@@ -199,7 +229,37 @@ func (s *upService) DeleteFile(c *fiber.Ctx) error {
 	})
 }
 
+// loadFile mengambil file dari parameter id jika pemanggil boleh
+// mengaksesnya.
+func (s *upService) loadFile(c *fiber.Ctx) (*model.Uploads, int, string) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return nil, fiber.StatusUnauthorized, "Unauthorized"
+	}
+
+	file, err := s.repo.FindByID(c.Params("id"))
+	// File milik user lain diperlakukan seperti tidak ada
+	if err != nil || file == nil || !canAccessFile(principal, file) {
+		return nil, fiber.StatusNotFound, "File not found"
+	}
+	return file, fiber.StatusOK, ""
+}
+
+// canAccessFile: file bisa dibaca pengunggahnya dan peninjau klaim alumni
+// yang memeriksa dokumen bukti. File lama tanpa pengunggah hanya bisa
+// dibaca peninjau.
+func canAccessFile(principal *model.Principal, file *model.Uploads) bool {
+	if principal.HasPermission(model.PermAlumniClaimsReview) {
+		return true
+	}
+	return !file.UploadedBy.IsZero() && file.UploadedBy == principal.UserID
+}
+
 func (s *upService) toFileResponse(file *model.Uploads) *model.UploadsResponse {
+	var uploadedBy string
+	if !file.UploadedBy.IsZero() {
+		uploadedBy = file.UploadedBy.Hex()
+	}
 	return &model.UploadsResponse{
 		ID:           file.ID.Hex(),
 		UploadsName:  file.UploadsName,
@@ -208,5 +268,6 @@ func (s *upService) toFileResponse(file *model.Uploads) *model.UploadsResponse {
 		UploadsSize:  file.UploadsSize,
 		UploadsType:  file.UploadsType,
 		UploadedAt:   file.UploadedAt,
+		UploadedBy:   uploadedBy,
 	}
 }
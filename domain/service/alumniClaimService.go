@@ -0,0 +1,311 @@
+package service
+
+import (
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/repository"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AlumniClaimService menghubungkan akun user ke data alumni: user
+// mengajukan klaim NIM, admin menyetujui atau menolak. Setelah disetujui,
+// NIM ikut tercantum di token berikutnya karena generateJWT membaca
+// tautan Alumni.UserID.
+type AlumniClaimService interface {
+	CreateClaimHandler() fiber.Handler
+	GetMyClaimHandler() fiber.Handler
+	ListClaimsHandler() fiber.Handler
+	ApproveClaimHandler() fiber.Handler
+	RejectClaimHandler() fiber.Handler
+}
+
+type alumniClaimService struct {
+	claimRepo   model.AlumniClaimRepository
+	alumniRepo  model.AlumniRepository
+	userRepo    model.UserRepository
+	uploadsRepo repository.UploadsRepository
+}
+
+func NewAlumniClaimService(claimRepo model.AlumniClaimRepository, alumniRepo model.AlumniRepository, userRepo model.UserRepository, uploadsRepo repository.UploadsRepository) AlumniClaimService {
+	return &alumniClaimService{claimRepo: claimRepo, alumniRepo: alumniRepo, userRepo: userRepo, uploadsRepo: uploadsRepo}
+}
+
+// @Summary Ajukan klaim NIM
+// @Description Meminta akun dihubungkan ke data alumni. Tahun lulus dan dokumen (ID file dari /api/upload) opsional sebagai bukti untuk admin.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.CreateAlumniClaim true "NIM dan bukti"
+// @Success 201 {object} model.AlumniClaim
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/me/alumni-claim [post]
+func (s *alumniClaimService) CreateClaimHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.CreateAlumniClaim
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		body.NIM = strings.TrimSpace(body.NIM)
+		if body.NIM == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "nim wajib diisi"})
+		}
+
+		var documentID *primitive.ObjectID
+		if body.DocumentID != "" {
+			id, err := primitive.ObjectIDFromHex(body.DocumentID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "document_id tidak valid"})
+			}
+			documentID = &id
+		}
+
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		// Dokumen bukti harus file yang diunggah sendiri, agar klaim tidak
+		// bisa menunjuk file milik user lain
+		if documentID != nil {
+			document, err := s.uploadsRepo.FindByID(documentID.Hex())
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa dokumen"})
+			}
+			if document == nil || document.UploadedBy != user.ID {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "document_id tidak ditemukan"})
+			}
+		}
+
+		linked, err := s.alumniRepo.FindByUserID(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa data alumni"})
+		}
+		if linked != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "akun sudah terhubung ke NIM " + linked.NIM})
+		}
+
+		latest, err := s.claimRepo.FindLatestByUser(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa klaim"})
+		}
+		if latest != nil && latest.Status == model.ClaimStatusPending {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "masih ada klaim yang menunggu peninjauan"})
+		}
+
+		alumni, status, msg := s.findAlumni(body.NIM)
+		if alumni == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if !alumni.UserID.IsZero() {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "NIM sudah terhubung ke akun lain"})
+		}
+
+		claim := &model.AlumniClaim{
+			UserID:     user.ID,
+			NIM:        alumni.NIM,
+			TahunLulus: body.TahunLulus,
+			DocumentID: documentID,
+			Note:       strings.TrimSpace(body.Note),
+			Status:     model.ClaimStatusPending,
+		}
+		if err := s.claimRepo.Create(claim); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menyimpan klaim"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "klaim berhasil diajukan, menunggu peninjauan admin",
+			"claim":   claim,
+		})
+	}
+}
+
+// @Summary Status klaim NIM terakhir
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.AlumniClaim
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/me/alumni-claim [get]
+func (s *alumniClaimService) GetMyClaimHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user ID tidak ditemukan di token"})
+		}
+
+		claim, err := s.claimRepo.FindLatestByUser(principal.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat klaim"})
+		}
+		if claim == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "belum ada klaim"})
+		}
+
+		return c.JSON(fiber.Map{"claim": claim})
+	}
+}
+
+// @Summary Daftar klaim NIM
+// @Description Setiap klaim disertai data alumni yang diklaim agar bukti bisa dibandingkan
+// @Tags Alumni Claims
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending (default), approved, rejected, atau all"
+// @Success 200 {array} model.AlumniClaim
+// @Router /api/admin/alumni-claims [get]
+func (s *alumniClaimService) ListClaimsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status := c.Query("status", model.ClaimStatusPending)
+		switch status {
+		case model.ClaimStatusPending, model.ClaimStatusApproved, model.ClaimStatusRejected:
+		case "all":
+			status = ""
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status tidak valid"})
+		}
+
+		claims, err := s.claimRepo.FindByStatus(status)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengambil klaim"})
+		}
+
+		items := make([]fiber.Map, 0, len(claims))
+		for i := range claims {
+			item := fiber.Map{"claim": claims[i], "alumni": nil}
+			if alumni, _, _ := s.findAlumni(claims[i].NIM); alumni != nil {
+				item["alumni"] = model.NewAdminAlumni(alumni)
+			}
+			items = append(items, item)
+		}
+
+		return c.JSON(fiber.Map{"claims": items})
+	}
+}
+
+// @Summary Setujui klaim NIM
+// @Description Menautkan data alumni ke akun pengaju. NIM tercantum di token setelah user login ulang atau me-refresh token.
+// @Tags Alumni Claims
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID klaim"
+// @Success 200 {object} model.AlumniClaim
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/admin/alumni-claims/{id}/approve [post]
+func (s *alumniClaimService) ApproveClaimHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claim, reviewer, status, msg := s.loadPendingClaim(c)
+		if claim == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		linked, err := s.alumniRepo.FindByUserID(claim.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa data alumni"})
+		}
+		if linked != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "user sudah terhubung ke NIM " + linked.NIM})
+		}
+
+		// Klaim dikunci lebih dulu agar tidak disetujui dan ditolak bersamaan
+		reviewed, err := s.claimRepo.Review(claim.ID, model.ClaimStatusApproved, reviewer, "")
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal meninjau klaim"})
+		}
+		if !reviewed {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "klaim sudah ditinjau"})
+		}
+
+		ok, err := s.alumniRepo.LinkUser(claim.NIM, claim.UserID)
+		if err != nil || !ok {
+			_ = s.claimRepo.Reopen(claim.ID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menautkan data alumni"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "NIM sudah terhubung ke akun lain"})
+		}
+
+		return c.JSON(fiber.Map{"message": "klaim disetujui, NIM akan tercantum di token berikutnya milik user"})
+	}
+}
+
+// @Summary Tolak klaim NIM
+// @Tags Alumni Claims
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID klaim"
+// @Param credentials body model.ReviewAlumniClaim false "Alasan penolakan"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/alumni-claims/{id}/reject [post]
+func (s *alumniClaimService) RejectClaimHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.ReviewAlumniClaim
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&body); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+			}
+		}
+
+		claim, reviewer, status, msg := s.loadPendingClaim(c)
+		if claim == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		reviewed, err := s.claimRepo.Review(claim.ID, model.ClaimStatusRejected, reviewer, strings.TrimSpace(body.Reason))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal meninjau klaim"})
+		}
+		if !reviewed {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "klaim sudah ditinjau"})
+		}
+
+		return c.JSON(fiber.Map{"message": "klaim ditolak"})
+	}
+}
+
+// loadPendingClaim memuat klaim dari parameter :id beserta ID peninjau.
+// Jika gagal, klaim bernilai nil dan status/pesan berisi respons yang harus
+// dikirim.
+func (s *alumniClaimService) loadPendingClaim(c *fiber.Ctx) (*model.AlumniClaim, primitive.ObjectID, int, string) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return nil, primitive.NilObjectID, fiber.StatusUnauthorized, "unauthorized"
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, primitive.NilObjectID, fiber.StatusBadRequest, "ID klaim tidak valid"
+	}
+
+	claim, err := s.claimRepo.FindByID(id)
+	if err != nil {
+		return nil, primitive.NilObjectID, fiber.StatusInternalServerError, "gagal memuat klaim"
+	}
+	if claim == nil {
+		return nil, primitive.NilObjectID, fiber.StatusNotFound, "klaim tidak ditemukan"
+	}
+	if claim.Status != model.ClaimStatusPending {
+		return nil, primitive.NilObjectID, fiber.StatusBadRequest, "klaim sudah ditinjau"
+	}
+
+	return claim, principal.UserID, 0, ""
+}
+
+func (s *alumniClaimService) findAlumni(nim string) (*model.Alumni, int, string) {
+	alumni, err := s.alumniRepo.CheckAlumniByNim(nim)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fiber.StatusNotFound, "NIM tidak ditemukan"
+		}
+		return nil, fiber.StatusInternalServerError, "gagal memeriksa data alumni"
+	}
+	return alumni, 0, ""
+}
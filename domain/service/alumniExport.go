@@ -0,0 +1,119 @@
+package service
+
+import (
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Export daftar alumni
+// @Description Menerima filter, pencarian, dan urutan yang sama dengan GET /api/alumni tanpa paginasi. CSV dan XLSX dialirkan langsung dari database; PDF dibatasi 5000 baris.
+// @Tags Alumni
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param format query string false "csv, xlsx, atau pdf" default(csv)
+// @Param search query string false "Cari berdasarkan NIM atau nama"
+// @Param sortBy query string false "nim, nama, angkatan, tahun_lulus, created_at" default(nim)
+// @Param order query string false "asc atau desc" default(asc)
+// @Param angkatan query string false "Daftar angkatan dipisah koma"
+// @Param tahun_lulus_from query int false "Tahun lulus minimal (inklusif)"
+// @Param tahun_lulus_to query int false "Tahun lulus maksimal (inklusif)"
+// @Param id_fakultas query string false "Daftar ID fakultas dipisah koma"
+// @Param id_prodi query string false "Daftar ID prodi dipisah koma"
+// @Param sumber query string false "Daftar sumber dipisah koma"
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/alumni/export [get]
+func (s *AlumniService) ExportAlumniService(c *fiber.Ctx) error {
+	format, ok := exportFormat(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "format harus csv, xlsx, atau pdf",
+			"success": false,
+		})
+	}
+
+	filter, err := parseAlumniFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+			"success": false,
+		})
+	}
+	sortBy, order := parseAlumniSort(c)
+
+	names, err := loadAllMasterNames(s.masterRepo)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Gagal memuat master data karena " + err.Error(),
+			"success": false,
+		})
+	}
+
+	// Cursor dibuka sebelum streaming agar kegagalan query masih bisa
+	// dilaporkan dengan status 500
+	cursor, err := s.repo.OpenAlumniCursor(filter, sortBy, order)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Gagal membaca data alumni karena " + err.Error(),
+			"success": false,
+		})
+	}
+
+	// Sumber data hanya ikut untuk pemegang alumni:write, sama seperti
+	// respons JSON
+	principal, _ := middleware.GetPrincipal(c)
+	full := principal != nil && principal.HasPermission(model.PermAlumniWrite)
+
+	header := []string{"NIM", "Nama", "Angkatan", "Tahun Lulus", "Fakultas", "Prodi"}
+	if full {
+		header = append(header, "Sumber")
+	}
+
+	return streamExport(c, format, exportTable{
+		Name:   "alumni",
+		Title:  "Daftar Alumni",
+		Header: header,
+		Rows: func(ctx context.Context, emit func([]interface{}) error) error {
+			defer cursor.Close(context.Background())
+
+			for cursor.Next(ctx) {
+				var alumni model.Alumni
+				if err := cursor.Decode(&alumni); err != nil {
+					return err
+				}
+
+				view := model.NewAdminAlumni(&alumni)
+				view.Expand(names)
+				row := []interface{}{
+					view.NIM,
+					view.Nama,
+					intCell(view.Angkatan),
+					intCell(view.TahunLulus),
+					masterCell(view.NamaFakultas, view.IDFakultas),
+					masterCell(view.NamaProdi, view.IDProdi),
+				}
+				if full {
+					row = append(row, masterCell(view.NamaSumber, view.IDSumber))
+				}
+				if err := emit(row); err != nil {
+					return err
+				}
+			}
+			return cursor.Err()
+		},
+	})
+}
+
+// masterCell menampilkan nama master data, atau ID-nya jika nama tidak
+// ditemukan.
+func masterCell(name string, id *int) interface{} {
+	if name != "" {
+		return name
+	}
+	return intCell(id)
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"Mongo/domain/model"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// alumniImportMaxSize dan alumniImportMaxRows membatasi satu file import.
+	alumniImportMaxSize = 5 * 1024 * 1024
+	alumniImportMaxRows = 10000
+	// alumniImportMaxUnzip membatasi ukuran isi XLSX setelah diekstrak agar
+	// file kecil yang berisi zip bomb tidak menghabiskan memori.
+	alumniImportMaxUnzip = 50 * 1024 * 1024
+)
+
+var errImportFormat = errors.New("format file harus .csv atau .xlsx")
+
+type AlumniImportService interface {
+	ImportAlumniHandler() fiber.Handler
+}
+
+type alumniImportService struct {
+	alumniRepo model.AlumniRepository
+	masterRepo model.MasterDataRepository
+}
+
+func NewAlumniImportService(alumniRepo model.AlumniRepository, masterRepo model.MasterDataRepository) AlumniImportService {
+	return &alumniImportService{
+		alumniRepo: alumniRepo,
+		masterRepo: masterRepo,
+	}
+}
+
+// importRow adalah satu baris file yang sudah dipetakan ke model.Alumni.
+type importRow struct {
+	line    int
+	alumni  model.Alumni
+	columns []string
+}
+
+// @Summary Import alumni dari CSV/XLSX
+// @Description Baris pertama adalah header dengan nama kolom: nim, nama, angkatan, tahun_lulus, id_fakultas, id_prodi, id_sumber, sumber. Alumni di-upsert berdasarkan NIM; kolom yang tidak ada di file tidak diubah. Dengan dry_run=true tidak ada data yang disimpan.
+// @Tags Alumni
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "File .csv atau .xlsx"
+// @Param dry_run query bool false "Hanya validasi tanpa menyimpan"
+// @Success 200 {object} model.AlumniImportResult
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/alumni/import [post]
+func (s *alumniImportService) ImportAlumniHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file wajib diunggah"})
+		}
+		if fileHeader.Size > alumniImportMaxSize {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ukuran file maksimal 5MB"})
+		}
+
+		records, err := readImportFile(fileHeader)
+		if err != nil {
+			if errors.Is(err, errImportFormat) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			}
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file tidak dapat dibaca"})
+		}
+		if len(records) < 2 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file tidak berisi data"})
+		}
+		if len(records)-1 > alumniImportMaxRows {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("maksimal %d baris per import", alumniImportMaxRows)})
+		}
+
+		header, ignored := mapImportHeader(records[0])
+		if _, ok := header["nim"]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "kolom nim wajib ada di header"})
+		}
+
+		result := model.AlumniImportResult{
+			DryRun:         c.QueryBool("dry_run"),
+			IgnoredColumns: ignored,
+			Errors:         []model.AlumniImportRowError{},
+		}
+
+		// Hasil lookup master data dipakai ulang untuk semua baris
+		masters := newCachedMasterData(s.masterRepo)
+		seen := map[string]int{}
+		var rows []importRow
+		for i, record := range records[1:] {
+			line := i + 2
+			if isBlankRecord(record) {
+				continue
+			}
+			result.TotalRows++
+
+			row, rowErrors := parseImportRow(header, record)
+			row.line = line
+			if prev, dup := seen[row.alumni.NIM]; dup {
+				rowErrors = append(rowErrors, fmt.Sprintf("NIM sudah muncul di baris %d", prev))
+			} else if row.alumni.NIM != "" {
+				seen[row.alumni.NIM] = line
+			}
+			if len(rowErrors) == 0 {
+				status, msg := validateAlumniRefs(masters, &row.alumni)
+				if status == fiber.StatusInternalServerError {
+					return c.Status(status).JSON(fiber.Map{"error": msg})
+				}
+				if status != 0 {
+					rowErrors = append(rowErrors, msg)
+				}
+			}
+			if len(rowErrors) > 0 {
+				result.Skipped++
+				result.Errors = append(result.Errors, model.AlumniImportRowError{Row: line, NIM: row.alumni.NIM, Errors: rowErrors})
+				continue
+			}
+
+			row.columns = withResolvedRefs(row.columns, &row.alumni)
+			rows = append(rows, row)
+		}
+
+		if result.DryRun {
+			if err := s.countDryRun(rows, &result); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek NIM yang sudah ada"})
+			}
+			return c.JSON(result)
+		}
+
+		for _, row := range rows {
+			created, err := s.alumniRepo.UpsertAlumni(&row.alumni, row.columns)
+			switch {
+			case mongo.IsDuplicateKeyError(err):
+				result.Skipped++
+				result.Errors = append(result.Errors, model.AlumniImportRowError{Row: row.line, NIM: row.alumni.NIM, Errors: []string{"NIM sudah terdaftar"}})
+			case err != nil:
+				result.Skipped++
+				result.Errors = append(result.Errors, model.AlumniImportRowError{Row: row.line, NIM: row.alumni.NIM, Errors: []string{"gagal menyimpan baris ini"}})
+			case created:
+				result.Created++
+			default:
+				result.Updated++
+			}
+		}
+
+		return c.JSON(result)
+	}
+}
+
+// countDryRun menghitung baris yang akan dibuat dan diubah tanpa menulis
+// apa pun.
+func (s *alumniImportService) countDryRun(rows []importRow, result *model.AlumniImportResult) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	nims := make([]string, 0, len(rows))
+	for _, row := range rows {
+		nims = append(nims, row.alumni.NIM)
+	}
+	existing, err := s.alumniRepo.ExistingNIMs(nims)
+	if err != nil {
+		return err
+	}
+
+	result.Updated = len(existing)
+	result.Created = len(rows) - len(existing)
+	return nil
+}
+
+// readImportFile membaca semua baris dari file CSV atau sheet pertama XLSX.
+func readImportFile(fileHeader *multipart.FileHeader) ([][]string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".csv":
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		// BOM dari Excel dibuang, dan pemisah titik koma (CSV Excel versi
+		// Indonesia) dideteksi dari baris header
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		reader := csv.NewReader(bytes.NewReader(data))
+		firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+		if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+			reader.Comma = ';'
+		}
+		reader.FieldsPerRecord = -1
+		return reader.ReadAll()
+	case ".xlsx":
+		book, err := excelize.OpenReader(file, excelize.Options{UnzipSizeLimit: alumniImportMaxUnzip})
+		if err != nil {
+			return nil, err
+		}
+		defer book.Close()
+
+		rows, err := book.Rows(book.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		// Baris dibaca satu per satu dan berhenti setelah melewati batas,
+		// sehingga sheet raksasa tidak dimuat seluruhnya ke memori.
+		var records [][]string
+		for rows.Next() && len(records) <= alumniImportMaxRows+1 {
+			record, err := rows.Columns()
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		return records, rows.Error()
+	default:
+		return nil, errImportFormat
+	}
+}
+
+// mapImportHeader memetakan nama kolom ke indeksnya. Nama dibandingkan tanpa
+// membedakan huruf besar/kecil, dan spasi dianggap sama dengan garis bawah.
+func mapImportHeader(record []string) (map[string]int, []string) {
+	known := map[string]bool{}
+	for _, column := range model.AlumniImportColumns {
+		known[column] = true
+	}
+
+	header := map[string]int{}
+	var ignored []string
+	for i, raw := range record {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if !known[name] {
+			if name != "" {
+				ignored = append(ignored, strings.TrimSpace(raw))
+			}
+			continue
+		}
+		if _, dup := header[name]; !dup {
+			header[name] = i
+		}
+	}
+	return header, ignored
+}
+
+// parseImportRow mengisi model.Alumni dari satu baris. Kolom dengan sel
+// kosong tetap ditulis sebagai kosong, kecuali nim.
+func parseImportRow(header map[string]int, record []string) (importRow, []string) {
+	var row importRow
+	var errs []string
+
+	cell := func(column string) (string, bool) {
+		i, ok := header[column]
+		if !ok {
+			return "", false
+		}
+		if i >= len(record) {
+			return "", true
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+	intCell := func(column string) *int {
+		value, ok := cell(column)
+		if !ok || value == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, column+" harus berupa angka")
+			return nil
+		}
+		return &n
+	}
+
+	for _, column := range model.AlumniImportColumns {
+		if _, ok := header[column]; ok {
+			row.columns = append(row.columns, column)
+		}
+	}
+
+	row.alumni.NIM, _ = cell("nim")
+	if row.alumni.NIM == "" {
+		errs = append(errs, "nim wajib diisi")
+	}
+	if nama, ok := cell("nama"); ok {
+		row.alumni.Nama = nama
+	}
+	row.alumni.Angkatan = intCell("angkatan")
+	row.alumni.TahunLulus = intCell("tahun_lulus")
+	row.alumni.IDFakultas = intCell("id_fakultas")
+	row.alumni.IDProdi = intCell("id_prodi")
+	row.alumni.IDSumber = intCell("id_sumber")
+	if sumber, ok := cell("sumber"); ok && sumber != "" {
+		row.alumni.Sumber = &sumber
+	}
+
+	if row.alumni.Angkatan != nil && row.alumni.TahunLulus != nil && *row.alumni.TahunLulus < *row.alumni.Angkatan {
+		errs = append(errs, "tahun_lulus tidak boleh lebih awal dari angkatan")
+	}
+
+	return row, errs
+}
+
+// withResolvedRefs menambahkan kolom yang dilengkapi validateAlumniRefs
+// (fakultas dari prodi, pasangan id_sumber/sumber) agar ikut tersimpan.
+func withResolvedRefs(columns []string, a *model.Alumni) []string {
+	has := map[string]bool{}
+	for _, column := range columns {
+		has[column] = true
+	}
+	add := func(column string) {
+		if !has[column] {
+			has[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	if a.IDFakultas != nil {
+		add("id_fakultas")
+	}
+	if has["id_sumber"] || has["sumber"] {
+		add("id_sumber")
+		add("sumber")
+	}
+	return columns
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedMasterData menyimpan hasil FindByID dan FindByName selama satu
+// import agar baris dengan prodi yang sama tidak memicu query berulang.
+type cachedMasterData struct {
+	model.MasterDataRepository
+	items map[string]*model.MasterData
+}
+
+func newCachedMasterData(repo model.MasterDataRepository) *cachedMasterData {
+	return &cachedMasterData{MasterDataRepository: repo, items: map[string]*model.MasterData{}}
+}
+
+func (m *cachedMasterData) FindByID(kind string, id int) (*model.MasterData, error) {
+	return m.cached(kind+"#"+strconv.Itoa(id), func() (*model.MasterData, error) {
+		return m.MasterDataRepository.FindByID(kind, id)
+	})
+}
+
+func (m *cachedMasterData) FindByName(kind, nama string) (*model.MasterData, error) {
+	return m.cached(kind+"@"+strings.ToLower(nama), func() (*model.MasterData, error) {
+		return m.MasterDataRepository.FindByName(kind, nama)
+	})
+}
+
+func (m *cachedMasterData) cached(key string, load func() (*model.MasterData, error)) (*model.MasterData, error) {
+	if item, ok := m.items[key]; ok {
+		return item, nil
+	}
+	item, err := load()
+	if err != nil {
+		return nil, err
+	}
+	m.items[key] = item
+	return item, nil
+}
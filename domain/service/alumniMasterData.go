@@ -0,0 +1,127 @@
+package service
+
+import (
+	"Mongo/domain/model"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// validateAlumniRefs memastikan fakultas, prodi, dan sumber alumni ada di
+// master data. Fakultas diisi dari prodi jika kosong, dan Sumber selalu
+// disamakan dengan nama di master data. Status 0 berarti valid.
+func validateAlumniRefs(masterRepo model.MasterDataRepository, a *model.Alumni) (int, string) {
+	if a.IDProdi != nil {
+		prodi, err := masterRepo.FindByID(model.MasterProdi, *a.IDProdi)
+		if err != nil {
+			return fiber.StatusInternalServerError, "gagal memuat prodi"
+		}
+		if prodi == nil {
+			return fiber.StatusBadRequest, "prodi tidak ditemukan"
+		}
+		if a.IDFakultas == nil {
+			a.IDFakultas = prodi.IDFakultas
+		} else if prodi.IDFakultas != nil && *prodi.IDFakultas != *a.IDFakultas {
+			return fiber.StatusBadRequest, "prodi tidak termasuk fakultas yang dipilih"
+		}
+	}
+
+	if a.IDFakultas != nil {
+		fakultas, err := masterRepo.FindByID(model.MasterFakultas, *a.IDFakultas)
+		if err != nil {
+			return fiber.StatusInternalServerError, "gagal memuat fakultas"
+		}
+		if fakultas == nil {
+			return fiber.StatusBadRequest, "fakultas tidak ditemukan"
+		}
+	}
+
+	// Sumber lama berupa teks bebas; teks dicocokkan ke master data agar
+	// keduanya tidak pernah berbeda
+	var sumber *model.MasterData
+	var err error
+	switch {
+	case a.IDSumber != nil:
+		sumber, err = masterRepo.FindByID(model.MasterSumber, *a.IDSumber)
+	case a.Sumber != nil && strings.TrimSpace(*a.Sumber) != "":
+		sumber, err = masterRepo.FindByName(model.MasterSumber, strings.TrimSpace(*a.Sumber))
+	default:
+		a.Sumber = nil
+		return 0, ""
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, "gagal memuat sumber"
+	}
+	if sumber == nil {
+		return fiber.StatusBadRequest, "sumber tidak ditemukan"
+	}
+	a.IDSumber = &sumber.ID
+	a.Sumber = &sumber.Nama
+
+	return 0, ""
+}
+
+// loadMasterNames memuat nama master data yang dirujuk daftar alumni dalam
+// satu query per jenis.
+func loadMasterNames(masterRepo model.MasterDataRepository, list []model.Alumni) (*model.MasterNames, error) {
+	ids := map[string]map[int]bool{}
+	add := func(kind string, id *int) {
+		if id == nil {
+			return
+		}
+		if ids[kind] == nil {
+			ids[kind] = map[int]bool{}
+		}
+		ids[kind][*id] = true
+	}
+	for i := range list {
+		add(model.MasterFakultas, list[i].IDFakultas)
+		add(model.MasterProdi, list[i].IDProdi)
+		add(model.MasterSumber, list[i].IDSumber)
+	}
+
+	names := map[string]map[int]string{}
+	for kind, set := range ids {
+		keys := make([]int, 0, len(set))
+		for id := range set {
+			keys = append(keys, id)
+		}
+
+		items, err := masterRepo.FindByIDs(kind, keys)
+		if err != nil {
+			return nil, err
+		}
+		names[kind] = make(map[int]string, len(items))
+		for _, item := range items {
+			names[kind][item.ID] = item.Nama
+		}
+	}
+
+	return &model.MasterNames{
+		Fakultas: names[model.MasterFakultas],
+		Prodi:    names[model.MasterProdi],
+		Sumber:   names[model.MasterSumber],
+	}, nil
+}
+
+// loadAllMasterNames memuat seluruh nama master data. Jumlahnya kecil, jadi
+// dipakai untuk export agar nama tidak dicari per baris.
+func loadAllMasterNames(masterRepo model.MasterDataRepository) (*model.MasterNames, error) {
+	names := map[string]map[int]string{}
+	for _, kind := range model.MasterDataKinds {
+		items, err := masterRepo.FindAll(kind, nil)
+		if err != nil {
+			return nil, err
+		}
+		names[kind] = make(map[int]string, len(items))
+		for _, item := range items {
+			names[kind][item.ID] = item.Nama
+		}
+	}
+
+	return &model.MasterNames{
+		Fakultas: names[model.MasterFakultas],
+		Prodi:    names[model.MasterProdi],
+		Sumber:   names[model.MasterSumber],
+	}, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// apiKeyPrefix membuat key mudah dikenali saat bocor di log atau repo.
+	apiKeyPrefix = "amk_"
+	// apiKeyMaxExpiry adalah batas atas expires_in_days.
+	apiKeyMaxExpiry = 365 * 24 * time.Hour
+)
+
+type APIKeyService interface {
+	CreateAPIKeyHandler() fiber.Handler
+	ListAPIKeysHandler() fiber.Handler
+	RevokeAPIKeyHandler() fiber.Handler
+}
+
+type apiKeyService struct {
+	apiKeyRepo model.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo model.APIKeyRepository) APIKeyService {
+	return &apiKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+// @Summary Buat API key
+// @Description Key asli hanya ditampilkan sekali di respons ini. Permission key tidak boleh melebihi permission pembuatnya, dan permission pengelolaan user, role, undangan, serta API key tidak bisa diberikan ke key.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.CreateAPIKey true "Nama, permission, dan masa berlaku"
+// @Success 201 {object} model.APIKey
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/api-keys [post]
+func (s *apiKeyService) CreateAPIKeyHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+
+		var body model.CreateAPIKey
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		body.Name = strings.TrimSpace(body.Name)
+		if body.Name == "" || len(body.Permissions) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name dan permissions wajib diisi"})
+		}
+		for _, perm := range body.Permissions {
+			// Wildcard tidak diizinkan agar cakupan key selalu eksplisit
+			if perm == model.PermAll || !model.IsKnownPermission(perm) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "permission tidak dikenal: " + perm})
+			}
+			if !model.AllowedForAPIKey(perm) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "permission tidak dapat diberikan ke API key: " + perm})
+			}
+			if !principal.HasPermission(perm) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "tidak dapat memberikan permission yang tidak Anda miliki: " + perm})
+			}
+		}
+
+		expiry := config.GetAPIKeyExpiry()
+		if body.ExpiresInDays < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "expires_in_days tidak valid"})
+		}
+		if body.ExpiresInDays > 0 {
+			expiry = time.Duration(body.ExpiresInDays) * 24 * time.Hour
+		}
+		if expiry > apiKeyMaxExpiry {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "masa berlaku API key maksimal 365 hari"})
+		}
+
+		token, err := newOpaqueToken()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat API key"})
+		}
+		rawKey := apiKeyPrefix + token
+
+		key := &model.APIKey{
+			Name:        body.Name,
+			Prefix:      rawKey[:len(apiKeyPrefix)+6],
+			KeyHash:     model.HashAPIKey(rawKey),
+			Permissions: body.Permissions,
+			CreatedBy:   principal.UserID,
+			ExpiresAt:   time.Now().Add(expiry),
+		}
+		if err := s.apiKeyRepo.Create(key); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menyimpan API key"})
+		}
+
+		// Key asli hanya dikembalikan sekali di sini
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "API key berhasil dibuat, simpan key ini karena tidak akan ditampilkan lagi",
+			"key":     rawKey,
+			"api_key": key,
+		})
+	}
+}
+
+// @Summary Daftar API key
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.APIKey
+// @Router /api/admin/api-keys [get]
+func (s *apiKeyService) ListAPIKeysHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		keys, err := s.apiKeyRepo.FindAll()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengambil API key"})
+		}
+		return c.JSON(fiber.Map{"api_keys": keys})
+	}
+}
+
+// @Summary Cabut API key
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID API key"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/admin/api-keys/{id} [delete]
+func (s *apiKeyService) RevokeAPIKeyHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID API key tidak valid"})
+		}
+
+		revoked, err := s.apiKeyRepo.Revoke(id, principal.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut API key"})
+		}
+		if !revoked {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "API key tidak ditemukan atau sudah dicabut"})
+		}
+
+		return c.JSON(fiber.Map{"message": "API key berhasil dicabut"})
+	}
+}
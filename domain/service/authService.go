@@ -3,20 +3,38 @@ package service
 import (
 	"Mongo/domain/config"
 	"Mongo/domain/model"
+	"log"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	RegisterHandler() fiber.Handler
 	LoginHandler() fiber.Handler
+	RefreshHandler() fiber.Handler
+	LogoutHandler() fiber.Handler
+	UnlockLoginHandler() fiber.Handler
+	LoginTwoFactorHandler() fiber.Handler
+	LoginTwoFactorSetupHandler() fiber.Handler
+	OIDCLoginHandler() fiber.Handler
+	OIDCCallbackHandler() fiber.Handler
 }
 
 type authService struct {
-	userRepo model.UserRepository
+	userRepo    model.UserRepository
+	refreshRepo model.RefreshTokenRepository
+	alumniRepo  model.AlumniRepository
+	verifier    VerificationService
+	guard       *LoginGuard
+	twoFactor   *TwoFactorGuard
+	sessions    *SessionManager
+	oidc        *OIDCClient
 }
 
 // HandleGetAllUsers godoc
@@ -26,11 +44,12 @@ type authService struct {
 // @Produce json
 // @Tags Authentication
 // @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
 // @Param credentials body model.Register true "Register"
 // @Success 200 {array} model.Register
 // @Router /api/register [post]
-func NewAuthService(userRepo model.UserRepository) AuthService {
-	return &authService{userRepo: userRepo}
+func NewAuthService(userRepo model.UserRepository, refreshRepo model.RefreshTokenRepository, alumniRepo model.AlumniRepository, verifier VerificationService, guard *LoginGuard, twoFactor *TwoFactorGuard, sessions *SessionManager, oidc *OIDCClient) AuthService {
+	return &authService{userRepo: userRepo, refreshRepo: refreshRepo, alumniRepo: alumniRepo, verifier: verifier, guard: guard, twoFactor: twoFactor, sessions: sessions, oidc: oidc}
 }
 
 // ---------------- HANDLER REGISTER ----------------
@@ -50,53 +69,60 @@ func (s *authService) registerLogic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
 	}
 
-	// 2. Validasi role
-	if body.Role != "admin" && body.Role != "user" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role tidak valid, harus 'admin' atau 'user'"})
+	body.Email = model.NormalizeEmail(body.Email)
+
+	// 2. Registrasi publik selalu membuat user biasa; admin hanya lewat undangan
+	if body.Role != "" && body.Role != "user" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role tidak valid, registrasi publik hanya untuk 'user'"})
+	}
+
+	// 3. Password harus memenuhi kebijakan sebelum email dicek
+	if violations := validatePassword(body.Password, body.Email, body.Username); len(violations) > 0 {
+		return passwordPolicyError(c, violations)
 	}
 
-	// 3. Cek email sudah ada
+	// 4. Cek email sudah ada
 	existing, err := s.userRepo.FindByEmail(body.Email)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek email"})
 	}
 	if existing != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email sudah terdaftar"})
+		return emailTaken(c)
 	}
 
-	// 4. Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	// 5. Hash password
+	hashedPassword, err := hashPassword(body.Password)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengenkripsi password"})
 	}
 
-	// 5. Buat user
+	// 6. Buat user, belum aktif sampai email diverifikasi
 	user := &model.Users{
 		Email:    body.Email,
 		Username: body.Username,
-		Password: string(hashedPassword),
-		Role:     body.Role,
+		Password: hashedPassword,
+		Role:     "user",
+		Status:   model.UserStatusUnverified,
 	}
 
-	// 6. Simpan ke DB
+	// 7. Simpan ke DB
 	if err := s.userRepo.Create(user); err != nil {
+		// Pendaftaran bersamaan dengan email yang sama ditolak oleh unique index
+		if mongo.IsDuplicateKeyError(err) {
+			return emailTaken(c)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat user"})
 	}
 
-	// 7. Buat JWT
-	token, err := s.generateJWT(user)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat token"})
+	// 8. Kirim tautan verifikasi. Jika gagal, admin masih bisa mengirim ulang.
+	if err := s.verifier.SendVerification(user); err != nil {
+		log.Printf("gagal mengirim email verifikasi ke %s: %v", user.Email, err)
 	}
 
-	// 8. Hapus password sebelum return
-	user.Password = ""
-
-	// 9. Return response
+	// 9. Return response tanpa token; login baru bisa setelah verifikasi
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "user berhasil didaftarkan",
-		"token":   token,
-		"user":    user,
+		"message": "user berhasil didaftarkan, silakan cek email untuk verifikasi",
+		"user":    model.NewAdminUser(user),
 	})
 }
 
@@ -107,6 +133,7 @@ func (s *authService) registerLogic(c *fiber.Ctx) error {
 // @Produce json
 // @Tags Authentication
 // @Failure 400 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
 // @Param credentials body model.Login true "Data Login"
 // @Success 200 {array} model.Login
 // @Router /api/login [post]
@@ -126,45 +153,198 @@ func (s *authService) loginLogic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
 	}
 
+	body.Email = model.NormalizeEmail(body.Email)
+
+	// Percobaan dihitung lebih dulu; email atau IP yang sedang dijeda/dikunci
+	// ditolak sebelum password dicek
+	wait, err := s.guard.Reserve(body.Email, c.IP())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa percobaan login"})
+	}
+	if wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
 	user, err := s.userRepo.FindByEmail(body.Email)
 	if err != nil {
+		s.guard.Cancel(body.Email, c.IP())
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencari user"})
 	}
+
+	// Email tidak terdaftar dan password salah diperlakukan sama persis
 	if user == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "email salah"})
+		compareDummyPassword(body.Password)
+		return s.failLogin(c, body.Email)
 	}
-
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "password salah"})
+		return s.failLogin(c, body.Email)
+	}
+
+	if err := s.guard.Succeed(body.Email, c.IP()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa percobaan login"})
 	}
 
-	token, err := s.generateJWT(user)
+	// Hash lama dengan cost di bawah BCRYPT_COST diperbarui selagi password
+	// asli tersedia; kegagalan tidak menggagalkan login
+	if passwordNeedsRehash(user.Password) {
+		s.rehashPassword(user, body.Password)
+	}
+
+	return s.finishLogin(c, user)
+}
+
+// finishLogin dipanggil setelah identitas user terbukti, baik lewat
+// password maupun SSO: memeriksa status akun lalu meminta 2FA atau
+// langsung menerbitkan token.
+func (s *authService) finishLogin(c *fiber.Ctx, user *model.Users) error {
+	if user.IsSuspended() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+	}
+	if !user.IsVerified() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "email belum diverifikasi"})
+	}
+
+	// User dengan 2FA (atau yang diwajibkan memakainya) menyelesaikan login
+	// lewat /login/2fa memakai mfa_token
+	mfaToken, setup, err := s.twoFactor.Challenge(user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa 2FA"})
+	}
+	if mfaToken != "" {
+		message := "masukkan kode 2FA untuk menyelesaikan login"
+		if setup {
+			message = "2FA wajib untuk akun ini, selesaikan setup 2FA terlebih dahulu"
+		}
+		return c.JSON(fiber.Map{
+			"message":            message,
+			"mfa_required":       true,
+			"mfa_setup_required": setup,
+			"mfa_token":          mfaToken,
+		})
+	}
+
+	return s.completeLogin(c, user, nil)
+}
+
+// completeLogin menerbitkan pasangan token untuk user yang sudah lolos
+// semua langkah autentikasi.
+func (s *authService) completeLogin(c *fiber.Ctx, user *model.Users, extra fiber.Map) error {
+	tokens, err := s.issueTokens(c, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat token"})
 	}
 
-	user.Password = ""
+	response := fiber.Map{
+		"message":       "login berhasil",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          model.NewAdminUser(user),
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	return c.JSON(response)
+}
 
-	return c.JSON(fiber.Map{
-		"message": "login berhasil",
-		"token":   token,
-		"user":    user,
-	})
+func (s *authService) failLogin(c *fiber.Ctx, email string) error {
+	if err := s.guard.Fail(email, c.IP()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa percobaan login"})
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "email atau password salah"})
+}
+
+func tooManyAttempts(c *fiber.Ctx, wait time.Duration) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "terlalu banyak percobaan login, coba lagi nanti"})
+}
+
+// @Summary Buka kunci login
+// @Description Menghapus catatan percobaan login gagal untuk email dan/atau IP
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.UnlockLogin true "Email dan/atau IP"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/login-attempts/unlock [post]
+func (s *authService) UnlockLoginHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.UnlockLogin
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		if body.Email == "" && body.IP == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email atau ip wajib diisi"})
+		}
+
+		if err := s.guard.Unlock(body.Email, body.IP); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuka kunci login"})
+		}
+
+		return c.JSON(fiber.Map{"message": "kunci login berhasil dibuka"})
+	}
+}
+
+func (s *authService) rehashPassword(user *model.Users, password string) {
+	hashed, err := hashPassword(password)
+	if err != nil {
+		log.Printf("gagal memperbarui hash password user %s: %v", user.ID.Hex(), err)
+		return
+	}
+	if err := s.userRepo.UpdatePasswordHash(user.ID, user.Password, hashed); err != nil {
+		log.Printf("gagal memperbarui hash password user %s: %v", user.ID.Hex(), err)
+		return
+	}
+	user.Password = hashed
+}
+
+// emailTaken membalas 409 untuk email yang sudah dipakai user lain, baik
+// dari pengecekan awal maupun dari unique index saat menyimpan.
+func emailTaken(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "email sudah terdaftar"})
 }
 
-func (s *authService) generateJWT(user *model.Users) (string, error) {
-	secret := config.GetJWTSecret()
-	expiry := config.GetJWTExpiry()
-	userIDHex := user.ID.Hex()
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), config.GetPasswordPolicy().BcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (s *authService) generateJWT(user *model.Users, sessionID string) (string, error) {
+	return signAccessToken(s.alumniRepo, user, sessionID, nil, config.GetJWTExpiry())
+}
+
+// signAccessToken membuat access token untuk user. actor hanya diisi untuk
+// token impersonasi.
+func signAccessToken(alumniRepo model.AlumniRepository, user *model.Users, sessionID string, actor *model.ActorClaim, expiry time.Duration) (string, error) {
+	now := time.Now()
+
+	// NIM alumni yang terhubung ikut disimpan agar handler tidak perlu query ulang
+	var nim string
+	alumni, err := alumniRepo.FindByUserID(user.ID)
+	if err != nil {
+		return "", err
+	}
+	if alumni != nil {
+		nim = alumni.NIM
+	}
 
-	claims := jwt.MapClaims{
-		"sub":      userIDHex,
-		"role":     user.Role,
-		"username": user.Username,
-		"exp":      time.Now().Add(expiry).Unix(),
-		"iat":      time.Now().Unix(),
+	claims := model.Claims{
+		Role:      user.Role,
+		Username:  user.Username,
+		NIM:       nim,
+		SessionID: sessionID,
+		Actor:     actor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.Hex(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return config.GetKeySet().Sign(claims)
 }
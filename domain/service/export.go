@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	exportCSV  = "csv"
+	exportXLSX = "xlsx"
+	exportPDF  = "pdf"
+
+	// exportPDFMaxRows membatasi PDF karena dokumennya disusun di memori
+	// sebelum dikirim; untuk data lebih besar gunakan CSV atau XLSX.
+	exportPDFMaxRows = 5000
+	// exportTimeout membatasi lama satu export membaca cursor.
+	exportTimeout = 10 * time.Minute
+)
+
+var errExportLimit = errors.New("batas baris export tercapai")
+
+var exportContentTypes = map[string]string{
+	exportCSV:  "text/csv; charset=utf-8",
+	exportXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	exportPDF:  "application/pdf",
+}
+
+// exportTable adalah satu tabel yang diexport. Rows memanggil emit untuk
+// setiap baris; nilai nil ditulis sebagai sel kosong.
+type exportTable struct {
+	Name   string
+	Title  string
+	Header []string
+	Rows   func(ctx context.Context, emit func([]interface{}) error) error
+}
+
+// tableWriter menulis tabel ke satu format file.
+type tableWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// exportFormat membaca ?format=, default CSV.
+func exportFormat(c *fiber.Ctx) (string, bool) {
+	format := strings.ToLower(c.Query("format", exportCSV))
+	_, ok := exportContentTypes[format]
+	return format, ok
+}
+
+// streamExport mengirim tabel sebagai file unduhan. Baris ditulis langsung
+// ke respons selama cursor dibaca, jadi status 200 sudah terkirim sebelum
+// baris pertama; kegagalan di tengah jalan hanya dicatat di log.
+func streamExport(c *fiber.Ctx, format string, table exportTable) error {
+	filename := fmt.Sprintf("%s-%s.%s", table.Name, time.Now().Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentType, exportContentTypes[format])
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+
+		if err := writeExport(ctx, w, format, table); err != nil {
+			log.Printf("export %s gagal: %v", filename, err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+func writeExport(ctx context.Context, w io.Writer, format string, table exportTable) error {
+	var out tableWriter
+	var err error
+	switch format {
+	case exportXLSX:
+		out, err = newXLSXTableWriter(w, table.Name)
+	case exportPDF:
+		out = newPDFTableWriter(w, table.Title, len(table.Header))
+	default:
+		out = newCSVTableWriter(w)
+	}
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(table.Header))
+	for i, h := range table.Header {
+		header[i] = h
+	}
+	if err := out.WriteRow(header); err != nil {
+		return err
+	}
+
+	rowErr := table.Rows(ctx, out.WriteRow)
+	if errors.Is(rowErr, errExportLimit) {
+		rowErr = nil
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return rowErr
+}
+
+// cellText mengubah nilai sel menjadi teks untuk CSV dan PDF.
+func cellText(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// intCell mengubah *int menjadi nilai sel; nil menjadi sel kosong.
+func intCell(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+func newCSVTableWriter(w io.Writer) *csvTableWriter {
+	return &csvTableWriter{w: csv.NewWriter(w)}
+}
+
+func (t *csvTableWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvCell(v)
+	}
+	return t.w.Write(record)
+}
+
+// csvFormulaPrefixes adalah karakter awal yang membuat spreadsheet membaca
+// sel CSV sebagai formula.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvCell menambahkan petik tunggal di depan teks yang akan dibaca sebagai
+// formula saat CSV dibuka di spreadsheet. Angka dibiarkan apa adanya.
+func csvCell(v interface{}) string {
+	text := cellText(v)
+	if _, ok := v.(string); ok && text != "" && strings.ContainsRune(csvFormulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// xlsxTableWriter memakai StreamWriter excelize yang memindahkan baris ke
+// file sementara setelah melewati batas memori.
+type xlsxTableWriter struct {
+	out    io.Writer
+	book   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXTableWriter(w io.Writer, sheet string) (*xlsxTableWriter, error) {
+	book := excelize.NewFile()
+	if err := book.SetSheetName(book.GetSheetName(0), sheet); err != nil {
+		book.Close()
+		return nil, err
+	}
+	stream, err := book.NewStreamWriter(sheet)
+	if err != nil {
+		book.Close()
+		return nil, err
+	}
+	return &xlsxTableWriter{out: w, book: book, stream: stream}, nil
+}
+
+func (t *xlsxTableWriter) WriteRow(values []interface{}) error {
+	t.row++
+	cell, err := excelize.CoordinatesToCellName(1, t.row)
+	if err != nil {
+		return err
+	}
+	return t.stream.SetRow(cell, values)
+}
+
+func (t *xlsxTableWriter) Close() error {
+	defer t.book.Close()
+	if err := t.stream.Flush(); err != nil {
+		return err
+	}
+	return t.book.Write(t.out)
+}
+
+// pdfTableWriter menyusun tabel sederhana A4 landscape dengan header yang
+// diulang di setiap halaman.
+type pdfTableWriter struct {
+	out       io.Writer
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+	width     float64
+	header    []string
+	rows      int
+}
+
+const (
+	pdfRowHeight = 6.0
+	pdfMargin    = 10.0
+)
+
+func newPDFTableWriter(w io.Writer, title string, columns int) *pdfTableWriter {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+
+	pageWidth, _ := pdf.GetPageSize()
+	t := &pdfTableWriter{
+		out:       w,
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+		width:     (pageWidth - 2*pdfMargin) / float64(columns),
+	}
+
+	pdf.SetHeaderFunc(func() {
+		if t.header != nil {
+			t.writeHeader()
+		}
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, t.translate(title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(0, 5, "Dicetak "+time.Now().Format("02-01-2006 15:04"), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	return t
+}
+
+func (t *pdfTableWriter) writeHeader() {
+	t.pdf.SetFont("Helvetica", "B", 8)
+	t.pdf.SetFillColor(230, 230, 230)
+	for _, h := range t.header {
+		t.pdf.CellFormat(t.width, pdfRowHeight, t.translate(h), "1", 0, "L", true, 0, "")
+	}
+	t.pdf.Ln(-1)
+	t.pdf.SetFont("Helvetica", "", 8)
+}
+
+func (t *pdfTableWriter) WriteRow(values []interface{}) error {
+	if t.header == nil {
+		t.header = make([]string, len(values))
+		for i, v := range values {
+			t.header[i] = cellText(v)
+		}
+		t.writeHeader()
+		return nil
+	}
+
+	if t.rows >= exportPDFMaxRows {
+		t.pdf.SetFont("Helvetica", "I", 8)
+		t.pdf.CellFormat(0, pdfRowHeight, fmt.Sprintf("Dipotong pada %d baris; gunakan format CSV atau XLSX untuk data lengkap.", exportPDFMaxRows), "", 1, "L", false, 0, "")
+		return errExportLimit
+	}
+	t.rows++
+
+	for _, v := range values {
+		text := t.translate(cellText(v))
+		// Teks dipotong agar baris tetap satu tinggi
+		for len(text) > 0 && t.pdf.GetStringWidth(text) > t.width-2 {
+			text = text[:len(text)-1]
+		}
+		t.pdf.CellFormat(t.width, pdfRowHeight, text, "1", 0, "L", false, 0, "")
+	}
+	t.pdf.Ln(-1)
+	return t.pdf.Error()
+}
+
+func (t *pdfTableWriter) Close() error {
+	return t.pdf.Output(t.out)
+}
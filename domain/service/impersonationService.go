@@ -0,0 +1,125 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// impersonationReasonMaxLength membatasi alasan yang disimpan di audit log.
+const impersonationReasonMaxLength = 500
+
+type ImpersonationService interface {
+	StartImpersonationHandler() fiber.Handler
+}
+
+type impersonationService struct {
+	userRepo   model.UserRepository
+	roleRepo   model.RoleRepository
+	alumniRepo model.AlumniRepository
+	auditRepo  model.AuditRepository
+}
+
+func NewImpersonationService(userRepo model.UserRepository, roleRepo model.RoleRepository, alumniRepo model.AlumniRepository, auditRepo model.AuditRepository) ImpersonationService {
+	return &impersonationService{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		alumniRepo: alumniRepo,
+		auditRepo:  auditRepo,
+	}
+}
+
+// @Summary Impersonasi user
+// @Description Menerbitkan access token singkat untuk melihat aplikasi sebagai user lain. Token hanya boleh dipakai untuk request baca dan setiap request dicatat di audit log.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Param body body model.StartImpersonation false "Alasan impersonasi"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/impersonate [post]
+func (s *impersonationService) StartImpersonationHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		if principal.IsAPIKey() || principal.IsImpersonated() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "impersonasi hanya dapat dimulai oleh akun admin"})
+		}
+
+		var body model.StartImpersonation
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&body); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+			}
+		}
+		body.Reason = strings.TrimSpace(body.Reason)
+		if len(body.Reason) > impersonationReasonMaxLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "alasan terlalu panjang"})
+		}
+
+		targetID, err := primitive.ObjectIDFromHex(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID user tidak valid"})
+		}
+		if targetID == principal.UserID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tidak dapat melakukan impersonasi terhadap akun sendiri"})
+		}
+
+		target, err := s.userRepo.FindByID(targetID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencari user"})
+		}
+		if target == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user tidak ditemukan"})
+		}
+		if target.IsSuspended() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "user sedang dinonaktifkan"})
+		}
+
+		// Sesama admin tidak boleh diimpersonasi agar token ini tidak bisa
+		// dipakai untuk meminjam hak admin lain
+		role, err := s.roleRepo.FindByName(target.Role)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat role user"})
+		}
+		if role != nil && (&model.Principal{Permissions: role.Permissions}).HasPermission(model.PermUsersImpersonate) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "tidak dapat melakukan impersonasi terhadap admin"})
+		}
+
+		// Audit ditulis sebelum token diterbitkan: tanpa jejak, tidak ada token
+		entry := &model.AuditLog{
+			ActorID:    principal.UserID,
+			Action:     model.AuditImpersonationStarted,
+			TargetType: "user",
+			TargetID:   target.ID.Hex(),
+			Details:    fiber.Map{"reason": body.Reason},
+			IP:         c.IP(),
+		}
+		if err := s.auditRepo.Create(entry); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menulis audit log"})
+		}
+
+		expiry := config.GetImpersonationExpiry()
+		actor := &model.ActorClaim{Subject: principal.UserID.Hex(), Username: principal.Username}
+		token, err := signAccessToken(s.alumniRepo, target, "", actor, expiry)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat token impersonasi"})
+		}
+
+		return c.JSON(fiber.Map{
+			"token":      token,
+			"expires_in": int(expiry.Seconds()),
+			"read_only":  true,
+			"user":       model.NewAdminUser(target),
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type InviteService interface {
+	CreateInviteHandler() fiber.Handler
+	ListInvitesHandler() fiber.Handler
+	RedeemInviteHandler() fiber.Handler
+}
+
+type inviteService struct {
+	inviteRepo model.InviteRepository
+	userRepo   model.UserRepository
+}
+
+func NewInviteService(inviteRepo model.InviteRepository, userRepo model.UserRepository) InviteService {
+	return &inviteService{inviteRepo: inviteRepo, userRepo: userRepo}
+}
+
+// @Summary Buat undangan admin
+// @Description Membuat undangan sekali pakai untuk mendaftarkan admin baru
+// @Tags Invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.CreateInvite false "Batasi ke email tertentu"
+// @Success 201 {object} model.Invite
+// @Failure 403 {object} model.ErrorResponse
+// @Router /api/admin/invites [post]
+func (s *inviteService) CreateInviteHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+
+		var body model.CreateInvite
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&body); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+			}
+		}
+
+		token, err := newOpaqueToken()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat undangan"})
+		}
+
+		invite := &model.Invite{
+			TokenHash: hashToken(token),
+			Email:     model.NormalizeEmail(body.Email),
+			Role:      "admin",
+			CreatedBy: principal.UserID,
+			ExpiresAt: time.Now().Add(config.GetInviteExpiry()),
+		}
+		if err := s.inviteRepo.Create(invite); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menyimpan undangan"})
+		}
+
+		// Token asli hanya dikembalikan sekali di sini
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "undangan berhasil dibuat",
+			"token":   token,
+			"invite":  invite,
+		})
+	}
+}
+
+// @Summary Daftar undangan admin
+// @Tags Invites
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Invite
+// @Router /api/admin/invites [get]
+func (s *inviteService) ListInvitesHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		invites, err := s.inviteRepo.FindAll()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengambil undangan"})
+		}
+		return c.JSON(fiber.Map{"invites": invites})
+	}
+}
+
+// @Summary Daftar sebagai admin lewat undangan
+// @Tags Invites
+// @Accept json
+// @Produce json
+// @Param credentials body model.RedeemInvite true "Token undangan dan data akun"
+// @Success 201 {object} model.Users
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/register/invite [post]
+func (s *inviteService) RedeemInviteHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return s.redeemLogic(c)
+	}
+}
+
+func (s *inviteService) redeemLogic(c *fiber.Ctx) error {
+	// 1. Parse body JSON
+	var body model.RedeemInvite
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	body.Email = model.NormalizeEmail(body.Email)
+	if body.Token == "" || body.Email == "" || body.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token, email dan password wajib diisi"})
+	}
+
+	// 2. Validasi undangan
+	invite, err := s.inviteRepo.FindByHash(hashToken(body.Token))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa undangan"})
+	}
+	if invite == nil || invite.UsedAt != nil || time.Now().After(invite.ExpiresAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "undangan tidak valid atau sudah kedaluwarsa"})
+	}
+	if invite.Email != "" && !strings.EqualFold(invite.Email, body.Email) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "undangan ini bukan untuk email tersebut"})
+	}
+
+	if violations := validatePassword(body.Password, body.Email, body.Username); len(violations) > 0 {
+		return passwordPolicyError(c, violations)
+	}
+
+	// 3. Cek email sudah ada
+	existing, err := s.userRepo.FindByEmail(body.Email)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek email"})
+	}
+	if existing != nil {
+		return emailTaken(c)
+	}
+
+	// 4. Hash password
+	hashedPassword, err := hashPassword(body.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengenkripsi password"})
+	}
+
+	user := &model.Users{
+		ID:       primitive.NewObjectID(),
+		Email:    body.Email,
+		Username: body.Username,
+		Password: hashedPassword,
+		Role:     invite.Role,
+		// Undangan diterbitkan admin, jadi akun langsung aktif
+		Status: model.UserStatusActive,
+	}
+
+	// 5. Klaim undangan lebih dulu supaya tidak bisa dipakai dua kali bersamaan
+	claimed, err := s.inviteRepo.MarkUsed(invite.ID, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memakai undangan"})
+	}
+	if !claimed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "undangan tidak valid atau sudah kedaluwarsa"})
+	}
+
+	// 6. Simpan ke DB
+	if err := s.userRepo.Create(user); err != nil {
+		_ = s.inviteRepo.Release(invite.ID)
+		if mongo.IsDuplicateKeyError(err) {
+			return emailTaken(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat user"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "akun admin berhasil dibuat, silakan login",
+		"user":    model.NewAdminUser(user),
+	})
+}
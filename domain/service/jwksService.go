@@ -0,0 +1,19 @@
+package service
+
+import (
+	"Mongo/domain/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary JSON Web Key Set
+// @Description Kunci publik untuk memverifikasi access token yang diterbitkan API ini
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} config.JWKS
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(c *fiber.Ctx) error {
+	// Cache singkat agar kunci baru hasil rotasi cepat terlihat
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(config.GetKeySet().JWKS())
+}
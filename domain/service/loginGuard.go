@@ -0,0 +1,199 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LoginGuard membatasi percobaan login yang gagal per email dan per IP.
+// Setiap kegagalan akun menambah jeda sebelum percobaan berikutnya, dan
+// setelah batas terlampaui email atau IP dikunci sementara.
+type LoginGuard struct {
+	repo model.LoginAttemptRepository
+	cfg  config.LoginThrottle
+	now  func() time.Time
+}
+
+func NewLoginGuard(repo model.LoginAttemptRepository, cfg config.LoginThrottle) *LoginGuard {
+	return &LoginGuard{repo: repo, cfg: cfg, now: time.Now}
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Reserve menghitung satu percobaan untuk email dan IP sebelum password
+// dicek, sehingga request paralel tidak bisa sama-sama lolos sebelum
+// kegagalannya tercatat. Jika email atau IP sedang dijeda/dikunci, hitungan
+// dikembalikan dan lama waktu tunggunya dikembalikan.
+func (g *LoginGuard) Reserve(email, ip string) (time.Duration, error) {
+	limits := []struct {
+		key string
+		max int
+	}{
+		{emailAttemptKey(email), g.cfg.MaxAccountFailures},
+		{ipAttemptKey(ip), g.cfg.MaxIPFailures},
+	}
+
+	var wait time.Duration
+	var reserved []string
+	for _, limit := range limits {
+		attempt, err := g.repo.Reserve(limit.key, g.cfg.Window)
+		if err != nil {
+			g.release(reserved)
+			return 0, err
+		}
+		reserved = append(reserved, limit.key)
+
+		// Percobaan yang melewati batas berarti percobaan lain masih berjalan
+		// atau sudah gagal sebanyak batasnya
+		if attempt.Failures > limit.max && !g.locked(attempt) {
+			until := g.now().Add(g.cfg.Lockout)
+			if err := g.repo.Lock(limit.key, until); err != nil {
+				g.release(reserved)
+				return 0, err
+			}
+			attempt.LockedUntil = &until
+		}
+
+		if w := g.waitFor(limit.key, attempt); w > wait {
+			wait = w
+		}
+	}
+
+	// Percobaan yang ditolak tidak ikut dihitung
+	if wait > 0 {
+		if err := g.release(reserved); err != nil {
+			return 0, err
+		}
+	}
+	return wait, nil
+}
+
+// Fail menandai percobaan yang sudah dihitung Reserve sebagai gagal, lalu
+// mengunci kunci yang mencapai batas.
+func (g *LoginGuard) Fail(email, ip string) error {
+	limits := map[string]int{
+		emailAttemptKey(email): g.cfg.MaxAccountFailures,
+		ipAttemptKey(ip):       g.cfg.MaxIPFailures,
+	}
+
+	for key, max := range limits {
+		attempt, err := g.repo.RecordFailure(key)
+		if err != nil {
+			return err
+		}
+		if attempt.Failures >= max {
+			if err := g.repo.Lock(key, g.now().Add(g.cfg.Lockout)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Succeed menghapus catatan kegagalan akun. Catatan IP dibiarkan agar satu
+// akun yang valid tidak bisa dipakai untuk mengosongkan hitungan IP; hanya
+// hitungan percobaan ini yang dikembalikan.
+func (g *LoginGuard) Succeed(email, ip string) error {
+	if err := g.repo.Reset(emailAttemptKey(email)); err != nil {
+		return err
+	}
+	return g.repo.Release(ipAttemptKey(ip))
+}
+
+// Cancel mengembalikan hitungan Reserve untuk percobaan yang terhenti
+// karena kesalahan server, bukan karena kredensial salah.
+func (g *LoginGuard) Cancel(email, ip string) error {
+	return g.release([]string{emailAttemptKey(email), ipAttemptKey(ip)})
+}
+
+func (g *LoginGuard) release(keys []string) error {
+	for _, key := range keys {
+		if err := g.repo.Release(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unlock dipakai admin untuk membuka kunci email dan/atau IP.
+func (g *LoginGuard) Unlock(email, ip string) error {
+	if email != "" {
+		if err := g.repo.Reset(emailAttemptKey(email)); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		if err := g.repo.Reset(ipAttemptKey(ip)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitFor menghitung waktu tunggu dari data hasil Reserve; hitungannya
+// sudah termasuk percobaan yang sedang berjalan.
+func (g *LoginGuard) waitFor(key string, attempt *model.LoginAttempt) time.Duration {
+	now := g.now()
+
+	if g.locked(attempt) {
+		return attempt.LockedUntil.Sub(now)
+	}
+
+	// Jeda bertahap hanya untuk akun; IP bisa dipakai bersama banyak user
+	if !strings.HasPrefix(key, "email:") {
+		return 0
+	}
+
+	next := attempt.LastFailureAt.Add(g.delayAfter(attempt.Failures - 1))
+	if now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// delayAfter: kegagalan pertama tanpa jeda, berikutnya BaseDelay lalu
+// berlipat dua sampai MaxDelay.
+func (g *LoginGuard) delayAfter(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+
+	delay := g.cfg.BaseDelay
+	for i := 2; i < failures && delay < g.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.cfg.MaxDelay {
+		delay = g.cfg.MaxDelay
+	}
+	return delay
+}
+
+func (g *LoginGuard) locked(attempt *model.LoginAttempt) bool {
+	return attempt.LockedUntil != nil && g.now().Before(*attempt.LockedUntil)
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// compareDummyPassword menjalankan bcrypt untuk email yang tidak terdaftar
+// agar waktu respons tidak membedakan email terdaftar dan tidak.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), config.GetPasswordPolicy().BcryptCost)
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"Mongo/domain/model"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MasterDataService interface {
+	ListMasterDataHandler() fiber.Handler
+	GetMasterDataHandler() fiber.Handler
+	CreateMasterDataHandler() fiber.Handler
+	UpdateMasterDataHandler() fiber.Handler
+	DeleteMasterDataHandler() fiber.Handler
+}
+
+type masterDataService struct {
+	masterRepo model.MasterDataRepository
+	alumniRepo model.AlumniRepository
+}
+
+func NewMasterDataService(masterRepo model.MasterDataRepository, alumniRepo model.AlumniRepository) MasterDataService {
+	return &masterDataService{
+		masterRepo: masterRepo,
+		alumniRepo: alumniRepo,
+	}
+}
+
+// @Summary Daftar master data
+// @Tags Master Data
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "fakultas, prodi, atau sumber"
+// @Param id_fakultas query int false "Filter prodi berdasarkan fakultas"
+// @Success 200 {array} model.MasterData
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/master/{kind} [get]
+func (s *masterDataService) ListMasterDataHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		kind, ok := masterKind(c)
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "jenis master data tidak dikenal"})
+		}
+
+		var idFakultas *int
+		if raw := c.Query("id_fakultas"); raw != "" && kind == model.MasterProdi {
+			id, err := strconv.Atoi(raw)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "id_fakultas harus berupa angka"})
+			}
+			idFakultas = &id
+		}
+
+		items, err := s.masterRepo.FindAll(kind, idFakultas)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengambil master data"})
+		}
+		return c.JSON(fiber.Map{kind: items})
+	}
+}
+
+// @Summary Detail master data
+// @Tags Master Data
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "fakultas, prodi, atau sumber"
+// @Param id path int true "ID master data"
+// @Success 200 {object} model.MasterData
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/master/{kind}/{id} [get]
+func (s *masterDataService) GetMasterDataHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		kind, item, status, msg := s.loadItem(c)
+		if item == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(fiber.Map{kind: item})
+	}
+}
+
+// @Summary Buat master data
+// @Description ID ditentukan admin karena dirujuk langsung oleh data alumni. Prodi wajib memiliki id_fakultas.
+// @Tags Master Data
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "fakultas, prodi, atau sumber"
+// @Param body body model.SaveMasterData true "ID, nama, dan fakultas (khusus prodi)"
+// @Success 201 {object} model.MasterData
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/admin/master/{kind} [post]
+func (s *masterDataService) CreateMasterDataHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		kind, ok := masterKind(c)
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "jenis master data tidak dikenal"})
+		}
+
+		var body model.SaveMasterData
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		if body.ID <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "id wajib berupa angka positif"})
+		}
+		item := &model.MasterData{ID: body.ID}
+		if status, msg := s.applyBody(kind, item, body); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		if err := s.masterRepo.Create(kind, item); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": kind + " dengan ID ini sudah ada"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menyimpan master data"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{kind: item})
+	}
+}
+
+// @Summary Ubah master data
+// @Tags Master Data
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "fakultas, prodi, atau sumber"
+// @Param id path int true "ID master data"
+// @Param body body model.SaveMasterData true "Nama dan fakultas (khusus prodi)"
+// @Success 200 {object} model.MasterData
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/admin/master/{kind}/{id} [put]
+func (s *masterDataService) UpdateMasterDataHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		kind, item, status, msg := s.loadItem(c)
+		if item == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		var body model.SaveMasterData
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		// Memindahkan prodi ke fakultas lain akan membuat data alumni tidak
+		// konsisten, jadi hanya boleh selama belum dipakai
+		previous := item.IDFakultas
+		previousNama := item.Nama
+		if status, msg := s.applyBody(kind, item, body); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if kind == model.MasterProdi && previous != nil && *previous != *item.IDFakultas {
+			used, err := s.alumniRepo.CountAlumni(model.AlumniFilter{IDProdi: []int{item.ID}})
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek pemakaian prodi"})
+			}
+			if used > 0 {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "fakultas prodi tidak dapat diubah karena masih dipakai data alumni"})
+			}
+		}
+
+		found, err := s.masterRepo.Update(kind, item)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menyimpan master data"})
+		}
+		if !found {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": kind + " tidak ditemukan"})
+		}
+		// Alumni menyimpan nama sumber di samping id_sumber untuk filter
+		// sumber, jadi nama barunya ikut diperbarui
+		if kind == model.MasterSumber && item.Nama != previousNama {
+			if err := s.alumniRepo.RenameSumber(item.ID, item.Nama); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memperbarui nama sumber di data alumni"})
+			}
+		}
+
+		return c.JSON(fiber.Map{kind: item})
+	}
+}
+
+// @Summary Hapus master data
+// @Description Ditolak jika masih dirujuk oleh data alumni atau prodi.
+// @Tags Master Data
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "fakultas, prodi, atau sumber"
+// @Param id path int true "ID master data"
+// @Success 200 {object} map[string]string
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/admin/master/{kind}/{id} [delete]
+func (s *masterDataService) DeleteMasterDataHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		kind, item, status, msg := s.loadItem(c)
+		if item == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		if kind == model.MasterFakultas {
+			prodi, err := s.masterRepo.CountProdiByFakultas(item.ID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek pemakaian fakultas"})
+			}
+			if prodi > 0 {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "fakultas masih memiliki prodi"})
+			}
+		}
+
+		var filter model.AlumniFilter
+		switch kind {
+		case model.MasterFakultas:
+			filter.IDFakultas = []int{item.ID}
+		case model.MasterProdi:
+			filter.IDProdi = []int{item.ID}
+		case model.MasterSumber:
+			filter.IDSumber = []int{item.ID}
+		}
+		used, err := s.alumniRepo.CountAlumni(filter)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek pemakaian " + kind})
+		}
+		if used > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": kind + " masih dipakai oleh data alumni"})
+		}
+
+		if _, err := s.masterRepo.Delete(kind, item.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menghapus master data"})
+		}
+		return c.JSON(fiber.Map{"message": kind + " berhasil dihapus"})
+	}
+}
+
+// applyBody memvalidasi body dan menyalinnya ke item. Status 0 berarti
+// valid.
+func (s *masterDataService) applyBody(kind string, item *model.MasterData, body model.SaveMasterData) (int, string) {
+	item.Nama = strings.TrimSpace(body.Nama)
+	if item.Nama == "" {
+		return fiber.StatusBadRequest, "nama wajib diisi"
+	}
+
+	if kind != model.MasterProdi {
+		item.IDFakultas = nil
+		return 0, ""
+	}
+	if body.IDFakultas == nil {
+		return fiber.StatusBadRequest, "id_fakultas wajib diisi untuk prodi"
+	}
+	fakultas, err := s.masterRepo.FindByID(model.MasterFakultas, *body.IDFakultas)
+	if err != nil {
+		return fiber.StatusInternalServerError, "gagal memuat fakultas"
+	}
+	if fakultas == nil {
+		return fiber.StatusBadRequest, "fakultas tidak ditemukan"
+	}
+	item.IDFakultas = body.IDFakultas
+	return 0, ""
+}
+
+// loadItem memuat master data dari parameter :kind dan :id. Jika gagal,
+// item bernilai nil dan status/pesan berisi respons yang harus dikirim.
+func (s *masterDataService) loadItem(c *fiber.Ctx) (string, *model.MasterData, int, string) {
+	kind, ok := masterKind(c)
+	if !ok {
+		return "", nil, fiber.StatusNotFound, "jenis master data tidak dikenal"
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return kind, nil, fiber.StatusBadRequest, "ID tidak valid"
+	}
+
+	item, err := s.masterRepo.FindByID(kind, id)
+	if err != nil {
+		return kind, nil, fiber.StatusInternalServerError, "gagal memuat master data"
+	}
+	if item == nil {
+		return kind, nil, fiber.StatusNotFound, kind + " tidak ditemukan"
+	}
+	return kind, item, 0, ""
+}
+
+func masterKind(c *fiber.Ctx) (string, bool) {
+	kind := c.Params("kind")
+	for _, known := range model.MasterDataKinds {
+		if kind == known {
+			return kind, true
+		}
+	}
+	return "", false
+}
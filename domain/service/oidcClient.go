@@ -0,0 +1,150 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	errOIDCState = errors.New("sesi login SSO tidak valid atau sudah kedaluwarsa")
+	errOIDCEmail = errors.New("email dari identity provider belum terverifikasi")
+)
+
+// OIDCClient menjalankan authorization code flow dengan PKCE terhadap
+// identity provider. Discovery dilakukan saat pertama dipakai agar aplikasi
+// tetap bisa start walau provider sedang tidak terjangkau.
+type OIDCClient struct {
+	states model.OIDCStateRepository
+	cfg    config.OIDCConfig
+	// ctx membawa HTTP client ber-timeout untuk discovery, pengambilan JWKS,
+	// dan penukaran code; tidak terikat ke satu request.
+	ctx context.Context
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewOIDCClient mengembalikan nil jika SSO tidak dikonfigurasi.
+func NewOIDCClient(states model.OIDCStateRepository, cfg config.OIDCConfig) *OIDCClient {
+	if !cfg.Enabled() {
+		return nil
+	}
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	return &OIDCClient{states: states, cfg: cfg, ctx: oidc.ClientContext(context.Background(), httpClient)}
+}
+
+// AuthURL menyiapkan state, nonce, dan code verifier baru lalu mengembalikan
+// URL halaman login identity provider.
+func (o *OIDCClient) AuthURL() (string, error) {
+	oauthCfg, _, err := o.oauthConfig()
+	if err != nil {
+		return "", err
+	}
+
+	state, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	record := &model.OIDCState{
+		StateHash:    hashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(o.cfg.StateExpiry),
+	}
+	if err := o.states.Create(record); err != nil {
+		return "", err
+	}
+
+	return oauthCfg.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange menukar authorization code dengan token, memverifikasi ID token
+// beserta nonce-nya, dan mengembalikan identitas yang emailnya terverifikasi.
+func (o *OIDCClient) Exchange(state, code string) (*model.OIDCIdentity, error) {
+	record, err := o.states.Consume(hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || time.Now().After(record.ExpiresAt) {
+		return nil, errOIDCState
+	}
+
+	oauthCfg, provider, err := o.oauthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthCfg.Exchange(o.ctx, code, oauth2.VerifierOption(record.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("tukar authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("respons token tidak berisi id_token")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: o.cfg.ClientID}).Verify(o.ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verifikasi id_token: %w", err)
+	}
+	if idToken.Nonce != record.Nonce {
+		return nil, errors.New("nonce id_token tidak cocok")
+	}
+
+	identity := new(model.OIDCIdentity)
+	if err := idToken.Claims(identity); err != nil {
+		return nil, fmt.Errorf("baca klaim id_token: %w", err)
+	}
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errOIDCEmail
+	}
+
+	return identity, nil
+}
+
+func (o *OIDCClient) oauthConfig() (*oauth2.Config, *oidc.Provider, error) {
+	provider, err := o.discover()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     o.cfg.ClientID,
+		ClientSecret: o.cfg.ClientSecret,
+		RedirectURL:  o.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       o.cfg.Scopes,
+	}, provider, nil
+}
+
+// discover menyimpan hasil discovery yang berhasil; kegagalan dicoba lagi
+// pada request berikutnya.
+func (o *OIDCClient) discover() (*oidc.Provider, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider != nil {
+		return o.provider, nil
+	}
+	provider, err := oidc.NewProvider(o.ctx, o.cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discovery OIDC: %w", err)
+	}
+	o.provider = provider
+	return provider, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"Mongo/domain/model"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// @Summary Mulai login SSO
+// @Description Mengarahkan browser ke identity provider kampus (authorization code flow dengan PKCE)
+// @Tags Authentication
+// @Success 302
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/auth/oidc/login [get]
+func (s *authService) OIDCLoginHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if s.oidc == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "login SSO tidak diaktifkan"})
+		}
+
+		authURL, err := s.oidc.AuthURL()
+		if err != nil {
+			log.Printf("gagal memulai login SSO: %v", err)
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "identity provider tidak dapat dihubungi"})
+		}
+
+		return c.Redirect(authURL, fiber.StatusFound)
+	}
+}
+
+// @Summary Callback login SSO
+// @Description Menukar authorization code, mencocokkan email terverifikasi ke user (atau membuat user baru dengan role user), lalu menerbitkan token seperti login biasa
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State dari langkah login"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/auth/oidc/callback [get]
+func (s *authService) OIDCCallbackHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if s.oidc == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "login SSO tidak diaktifkan"})
+		}
+		if c.Query("error") != "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "login SSO dibatalkan atau ditolak identity provider"})
+		}
+
+		code, state := c.Query("code"), c.Query("state")
+		if code == "" || state == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "code dan state wajib diisi"})
+		}
+
+		identity, err := s.oidc.Exchange(state, code)
+		if err != nil {
+			if errors.Is(err, errOIDCState) || errors.Is(err, errOIDCEmail) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			}
+			log.Printf("login SSO gagal: %v", err)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "login SSO gagal"})
+		}
+
+		user, status, msg := s.oidcUser(identity)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		return s.finishLogin(c, user)
+	}
+}
+
+// oidcUser mencocokkan email terverifikasi dari identity provider ke user
+// yang ada, atau membuat user baru dengan role user. Jika gagal, user
+// bernilai nil dan status/pesan berisi respons yang harus dikirim.
+func (s *authService) oidcUser(identity *model.OIDCIdentity) (*model.Users, int, string) {
+	identity.Email = model.NormalizeEmail(identity.Email)
+	user, err := s.userRepo.FindByEmail(identity.Email)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal mencari user"
+	}
+
+	now := time.Now()
+	if user != nil {
+		// Identity provider sudah membuktikan kepemilikan email. Akun yang
+		// belum terverifikasi bisa saja didaftarkan orang lain lebih dulu,
+		// jadi password dan sesi yang dibuat pendaftar itu dibuang
+		if user.Status == model.UserStatusUnverified {
+			hashedPassword, err := randomPasswordHash()
+			if err != nil {
+				return nil, fiber.StatusInternalServerError, "gagal memperbarui user"
+			}
+			user.Status = model.UserStatusActive
+			user.VerifiedAt = &now
+			user.Password = hashedPassword
+			user.PasswordChangedAt = &now
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, fiber.StatusInternalServerError, "gagal memperbarui user"
+			}
+			if err := s.sessions.EndAll(user.ID); err != nil {
+				return nil, fiber.StatusInternalServerError, "gagal mencabut sesi lama"
+			}
+		}
+		return user, 0, ""
+	}
+
+	// Akun SSO tidak punya password lokal; hash dari nilai acak yang tidak
+	// pernah ditampilkan membuat login password selalu gagal
+	hashedPassword, err := randomPasswordHash()
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal membuat user"
+	}
+
+	user = &model.Users{
+		Email:      identity.Email,
+		Username:   oidcUsername(identity),
+		Password:   hashedPassword,
+		Role:       "user",
+		Status:     model.UserStatusActive,
+		VerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fiber.StatusConflict, "email sudah terdaftar"
+		}
+		return nil, fiber.StatusInternalServerError, "gagal membuat user"
+	}
+
+	return user, 0, ""
+}
+
+func randomPasswordHash() (string, error) {
+	randomPassword, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	return hashPassword(randomPassword)
+}
+
+func oidcUsername(identity *model.OIDCIdentity) string {
+	if identity.Username != "" {
+		return identity.Username
+	}
+	if identity.Name != "" {
+		return identity.Name
+	}
+	local, _, _ := strings.Cut(identity.Email, "@")
+	return local
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"Mongo/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OwnershipPolicy memutuskan apakah pemanggil boleh menyentuh data pekerjaan
+// tertentu. Kepemilikan ditentukan lewat Alumni.UserID, bukan dari isi token,
+// sehingga perubahan tautan akun langsung berlaku.
+type OwnershipPolicy struct {
+	alumniRepo model.AlumniRepository
+}
+
+func NewOwnershipPolicy(alumniRepo model.AlumniRepository) *OwnershipPolicy {
+	return &OwnershipPolicy{alumniRepo: alumniRepo}
+}
+
+// CallerAlumni mengembalikan data alumni yang terhubung ke pemanggil,
+// atau nil jika akun belum terhubung ke NIM mana pun.
+func (p *OwnershipPolicy) CallerAlumni(principal *model.Principal) (*model.Alumni, error) {
+	// Pemanggil lewat API key tidak punya akun, jadi tidak memiliki data apa pun
+	if principal.UserID.IsZero() {
+		return nil, nil
+	}
+	return p.alumniRepo.FindByUserID(principal.UserID)
+}
+
+func (p *OwnershipPolicy) OwnsPekerjaan(principal *model.Principal, target *model.PekerjaanAlumni) (bool, error) {
+	return p.ownsNIM(principal, target.NimAlumni)
+}
+
+// CanManagePekerjaan bernilai true jika pemanggil punya pekerjaan:manage_all
+// atau data pekerjaan tersebut milik NIM pemanggil.
+func (p *OwnershipPolicy) CanManagePekerjaan(principal *model.Principal, target *model.PekerjaanAlumni) (bool, error) {
+	if principal.HasPermission(model.PermPekerjaanManageAll) {
+		return true, nil
+	}
+	return p.OwnsPekerjaan(principal, target)
+}
+
+func (p *OwnershipPolicy) ownsNIM(principal *model.Principal, nim string) (bool, error) {
+	if nim == "" {
+		return false, nil
+	}
+
+	caller, err := p.CallerAlumni(principal)
+	if err != nil {
+		return false, err
+	}
+
+	return caller != nil && caller.NIM == nim, nil
+}
+
+// ViewPekerjaan memproyeksikan daftar pekerjaan sesuai hak pemanggil: gaji
+// hanya terlihat oleh pemilik data dan pemegang pekerjaan:manage_all.
+func (p *OwnershipPolicy) ViewPekerjaan(principal *model.Principal, list []model.PekerjaanAlumni) ([]interface{}, error) {
+	canSeeSalary, err := p.SalaryVisibility(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]interface{}, 0, len(list))
+	for i := range list {
+		if canSeeSalary(list[i].NimAlumni) {
+			views = append(views, model.NewAdminPekerjaan(&list[i]))
+		} else {
+			views = append(views, model.NewPublicPekerjaan(&list[i]))
+		}
+	}
+	return views, nil
+}
+
+// SalaryVisibility mengembalikan fungsi yang menentukan apakah gaji milik
+// NIM tertentu boleh dilihat pemanggil. Alumni pemanggil hanya dimuat sekali.
+func (p *OwnershipPolicy) SalaryVisibility(principal *model.Principal) (func(nim string) bool, error) {
+	if principal != nil && principal.HasPermission(model.PermPekerjaanManageAll) {
+		return func(string) bool { return true }, nil
+	}
+
+	callerNIM := ""
+	if principal != nil {
+		caller, err := p.CallerAlumni(principal)
+		if err != nil {
+			return nil, err
+		}
+		if caller != nil {
+			callerNIM = caller.NIM
+		}
+	}
+	return func(nim string) bool { return callerNIM != "" && nim == callerNIM }, nil
+}
+
+// denyOwnership adalah respons 403 yang sama untuk semua pelanggaran kepemilikan.
+func denyOwnership(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"message": "Akses ditolak: Anda hanya dapat mengelola data milik Anda sendiri",
+		"success": false,
+	})
+}
+
+func ownershipError(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Gagal memeriksa kepemilikan data karena " + err.Error(),
+		"success": false,
+	})
+}
+
+func unauthenticated(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"message": "Data otentikasi tidak lengkap atau tidak valid",
+		"success": false,
+	})
+}
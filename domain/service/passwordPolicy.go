@@ -0,0 +1,107 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptMaxBytes adalah panjang input yang masih diproses bcrypt.
+const bcryptMaxBytes = 72
+
+// minIdentifierLength mencegah email atau username yang sangat pendek
+// membuat hampir semua password ditolak.
+const minIdentifierLength = 4
+
+//go:embed data/common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// validatePassword memeriksa password pilihan user terhadap kebijakan dan
+// mengembalikan semua pelanggaran sekaligus agar klien bisa menampilkannya.
+func validatePassword(password, email, username string) []model.PasswordViolation {
+	policy := config.GetPasswordPolicy()
+	if password == "" {
+		return []model.PasswordViolation{{Code: model.PasswordRequired, Message: "password wajib diisi"}}
+	}
+
+	var violations []model.PasswordViolation
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		violations = append(violations, model.PasswordViolation{
+			Code:    model.PasswordTooShort,
+			Message: fmt.Sprintf("password minimal %d karakter", policy.MinLength),
+		})
+	}
+	if len(password) > bcryptMaxBytes {
+		violations = append(violations, model.PasswordViolation{
+			Code:    model.PasswordTooLong,
+			Message: fmt.Sprintf("password maksimal %d byte", bcryptMaxBytes),
+		})
+	}
+
+	lowered := strings.ToLower(password)
+	if isCommonPassword(lowered) {
+		violations = append(violations, model.PasswordViolation{Code: model.PasswordTooCommon, Message: "password terlalu umum"})
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	localPart, _, _ := strings.Cut(email, "@")
+	if email != "" && (lowered == email || containsIdentifier(lowered, localPart)) {
+		violations = append(violations, model.PasswordViolation{Code: model.PasswordContainsEmail, Message: "password tidak boleh memuat email"})
+	}
+	if containsIdentifier(lowered, strings.ToLower(strings.TrimSpace(username))) {
+		violations = append(violations, model.PasswordViolation{Code: model.PasswordContainsUsername, Message: "password tidak boleh memuat username"})
+	}
+
+	return violations
+}
+
+func containsIdentifier(password, identifier string) bool {
+	return utf8.RuneCountInString(identifier) >= minIdentifierLength && strings.Contains(password, identifier)
+}
+
+func isCommonPassword(lowered string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+		scanner := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			commonPasswords[strings.ToLower(line)] = struct{}{}
+		}
+	})
+	_, found := commonPasswords[lowered]
+	return found
+}
+
+// passwordPolicyError mengirim pelanggaran kebijakan password sebagai 400.
+func passwordPolicyError(c *fiber.Ctx, violations []model.PasswordViolation) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":      "password tidak memenuhi kebijakan",
+		"violations": violations,
+	})
+}
+
+// passwordNeedsRehash bernilai true jika hash dibuat dengan cost yang lebih
+// rendah dari BCRYPT_COST saat ini.
+func passwordNeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return cost < config.GetPasswordPolicy().BcryptCost
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/mailer"
+	"Mongo/domain/model"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const forgotPasswordMessage = "jika email terdaftar, tautan reset password telah dikirim"
+
+type PasswordService interface {
+	ForgotPasswordHandler() fiber.Handler
+	ResetPasswordHandler() fiber.Handler
+}
+
+type passwordService struct {
+	userRepo  model.UserRepository
+	tokenRepo model.OneTimeTokenRepository
+	sessions  *SessionManager
+	mailer    mailer.Mailer
+}
+
+func NewPasswordService(userRepo model.UserRepository, tokenRepo model.OneTimeTokenRepository, sessions *SessionManager, m mailer.Mailer) PasswordService {
+	return &passwordService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		sessions:  sessions,
+		mailer:    m,
+	}
+}
+
+// @Summary Lupa password
+// @Description Mengirim tautan reset password ke email. Respons selalu sama agar tidak membocorkan email yang terdaftar.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.ForgotPassword true "Email akun"
+// @Success 200 {object} map[string]string
+// @Router /api/password/forgot [post]
+func (s *passwordService) ForgotPasswordHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.ForgotPassword
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		email := model.NormalizeEmail(body.Email)
+		if email == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email wajib diisi"})
+		}
+
+		// Kegagalan internal hanya dicatat di log; pemanggil selalu menerima
+		// pesan yang sama.
+		if err := s.sendResetLink(email); err != nil {
+			log.Printf("reset password untuk %s gagal: %v", email, err)
+		}
+
+		return c.JSON(fiber.Map{"message": forgotPasswordMessage})
+	}
+}
+
+func (s *passwordService) sendResetLink(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	// Hanya tautan terbaru yang berlaku
+	if err := s.tokenRepo.InvalidateForUser(user.ID, model.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	expiry := config.GetPasswordResetExpiry()
+	record := &model.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposePasswordReset,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if err := s.tokenRepo.Create(record); err != nil {
+		return err
+	}
+
+	link := config.GetAppBaseURL() + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Anda",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nGunakan tautan berikut untuk mengatur ulang password Anda:\n%s\n\nTautan berlaku selama %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.\n",
+			user.Username, link, expiry,
+		),
+	})
+}
+
+// @Summary Reset password
+// @Description Mengganti password memakai token dari email lupa password. Semua sesi user dicabut.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.ResetPassword true "Token dan password baru"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/password/reset [post]
+func (s *passwordService) ResetPasswordHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.ResetPassword
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		if body.Token == "" || body.Password == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token dan password wajib diisi"})
+		}
+
+		record, err := s.tokenRepo.FindByHash(model.TokenPurposePasswordReset, hashToken(body.Token))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi token"})
+		}
+		if record == nil || record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token reset tidak valid atau sudah kedaluwarsa"})
+		}
+
+		user, err := s.userRepo.FindByID(record.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencari user"})
+		}
+		if user == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token reset tidak valid atau sudah kedaluwarsa"})
+		}
+
+		if violations := validatePassword(body.Password, user.Email, user.Username); len(violations) > 0 {
+			return passwordPolicyError(c, violations)
+		}
+
+		hashedPassword, err := hashPassword(body.Password)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengenkripsi password"})
+		}
+
+		// Token ditandai terpakai lebih dulu agar dua request bersamaan
+		// tidak sama-sama berhasil
+		consumed, err := s.tokenRepo.Consume(record.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi token"})
+		}
+		if !consumed {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token reset tidak valid atau sudah kedaluwarsa"})
+		}
+
+		now := time.Now()
+		user.Password = hashedPassword
+		user.PasswordChangedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengubah password"})
+		}
+
+		// Sesi lama bisa saja milik orang yang mengambil alih akun
+		if err := s.sessions.EndAll(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi lama"})
+		}
+
+		return c.JSON(fiber.Map{"message": "password berhasil diubah, silakan login kembali"})
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ProfileService melayani akun milik pemanggil sendiri, dikunci ke subject JWT.
+type ProfileService interface {
+	GetMeHandler() fiber.Handler
+	UpdateMeHandler() fiber.Handler
+	ChangePasswordHandler() fiber.Handler
+}
+
+type profileService struct {
+	userRepo model.UserRepository
+	sessions *SessionManager
+	verifier VerificationService
+}
+
+func NewProfileService(userRepo model.UserRepository, sessions *SessionManager, verifier VerificationService) ProfileService {
+	return &profileService{userRepo: userRepo, sessions: sessions, verifier: verifier}
+}
+
+// @Summary Profil akun sendiri
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.AdminUser
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/me [get]
+func (s *profileService) GetMeHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		return c.JSON(fiber.Map{"user": model.NewAdminUser(user)})
+	}
+}
+
+// @Summary Ubah username atau email akun sendiri
+// @Description Email baru disimpan sebagai pending_email dan baru menggantikan email akun setelah tautan verifikasi di alamat baru dibuka. Akun tetap aktif dengan email lama selama menunggu.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.UpdateUser true "Data profil"
+// @Success 200 {object} model.AdminUser
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/me [patch]
+func (s *profileService) UpdateMeHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.UpdateUser
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		body.Email = model.NormalizeEmail(body.Email)
+		body.Username = strings.TrimSpace(body.Username)
+		if body.Email == "" && body.Username == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email atau username wajib diisi"})
+		}
+
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		emailChanged := false
+		if body.Email != "" && body.Email != user.Email {
+			existing, err := s.userRepo.FindByEmail(body.Email)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek email"})
+			}
+			if existing != nil {
+				return emailTaken(c)
+			}
+			// Email baru harus dibuktikan milik pemanggil dulu; sampai saat itu
+			// akun tetap aktif dan login memakai email lama
+			user.PendingEmail = body.Email
+			emailChanged = true
+		} else if body.Email == user.Email {
+			// Kembali ke email lama membatalkan perubahan yang masih menunggu
+			user.PendingEmail = ""
+		}
+		if body.Username != "" {
+			user.Username = body.Username
+		}
+
+		if err := s.userRepo.Update(user); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return emailTaken(c)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengubah profil"})
+		}
+
+		message := "profil berhasil diubah"
+		if emailChanged {
+			if err := s.verifier.SendVerification(user); err != nil {
+				log.Printf("gagal mengirim email verifikasi ke %s: %v", user.PendingEmail, err)
+			}
+			message = "profil berhasil diubah, silakan verifikasi email baru Anda"
+		}
+
+		return c.JSON(fiber.Map{"message": message, "user": model.NewAdminUser(user)})
+	}
+}
+
+// @Summary Ganti password akun sendiri
+// @Description Semua sesi aktif dicabut setelah password diganti.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.ChangePassword true "Password lama dan baru"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/me/password [post]
+func (s *profileService) ChangePasswordHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.ChangePassword
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		if body.CurrentPassword == "" || body.NewPassword == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "password lama dan password baru wajib diisi"})
+		}
+		if body.CurrentPassword == body.NewPassword {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "password baru harus berbeda dari password lama"})
+		}
+
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.CurrentPassword)); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "password lama salah"})
+		}
+
+		if violations := validatePassword(body.NewPassword, user.Email, user.Username); len(violations) > 0 {
+			return passwordPolicyError(c, violations)
+		}
+
+		hashedPassword, err := hashPassword(body.NewPassword)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengenkripsi password"})
+		}
+
+		now := time.Now()
+		user.Password = hashedPassword
+		user.PasswordChangedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengubah password"})
+		}
+
+		if err := s.sessions.EndAll(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi lama"})
+		}
+
+		return c.JSON(fiber.Map{"message": "password berhasil diubah, silakan login kembali"})
+	}
+}
+
+// loadCallerUser memuat user pemilik token. Jika gagal, user bernilai nil
+// dan status/pesan berisi respons yang harus dikirim.
+func loadCallerUser(c *fiber.Ctx, userRepo model.UserRepository) (*model.Users, int, string) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return nil, fiber.StatusUnauthorized, "user ID tidak ditemukan di token"
+	}
+	if principal.IsAPIKey() {
+		return nil, fiber.StatusForbidden, "endpoint ini hanya untuk akun pengguna"
+	}
+
+	user, err := userRepo.FindByID(principal.UserID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal memuat user"
+	}
+	if user == nil {
+		return nil, fiber.StatusNotFound, "user tidak ditemukan"
+	}
+	return user, 0, ""
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Perbarui access token
+// @Description Menukar refresh token dengan pasangan token baru (rotasi)
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param credentials body model.RefreshRequest true "Refresh Token"
+// @Success 200 {object} model.TokenPair
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/token/refresh [post]
+func (s *authService) RefreshHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return s.refreshLogic(c)
+	}
+}
+
+func (s *authService) refreshLogic(c *fiber.Ctx) error {
+	var body model.RefreshRequest
+	if err := c.BodyParser(&body); err != nil || body.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token wajib diisi"})
+	}
+
+	// 1. Cari token berdasarkan hash
+	current, err := s.refreshRepo.FindByHash(hashToken(body.RefreshToken))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa refresh token"})
+	}
+	if current == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "refresh token tidak valid"})
+	}
+
+	// 2. Token yang sudah dirotasi dipakai lagi: anggap bocor, cabut seluruh family
+	if current.RevokedAt != nil {
+		if err := s.sessions.End(current.FamilyID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut refresh token"})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "refresh token sudah digunakan, silakan login ulang"})
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "refresh token kedaluwarsa"})
+	}
+
+	// 3. Pastikan user masih ada
+	user, err := s.userRepo.FindByID(current.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencari user"})
+	}
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user tidak ditemukan"})
+	}
+	if user.IsSuspended() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+	}
+	if !user.IsVerified() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "email belum diverifikasi"})
+	}
+
+	// 4. Rotasi: token lama ditandai, token baru masuk family yang sama
+	nextToken, err := newOpaqueToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat token"})
+	}
+
+	rotated, err := s.refreshRepo.Rotate(current.ID, hashToken(nextToken))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal merotasi refresh token"})
+	}
+	if !rotated {
+		// Request lain sudah merotasi token ini lebih dulu
+		if err := s.sessions.End(current.FamilyID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut refresh token"})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "refresh token sudah digunakan, silakan login ulang"})
+	}
+
+	// Sesi yang dicabut dari daftar sesi tidak boleh hidup lagi lewat refresh
+	active, err := s.sessions.Resume(user.ID, current.FamilyID, c.IP(), c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa sesi"})
+	}
+	if !active {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "sesi sudah berakhir, silakan login ulang"})
+	}
+
+	tokens, err := s.issueTokensWith(user, current.FamilyID, nextToken)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat token"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "token berhasil diperbarui",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// @Summary Logout
+// @Description Mencabut seluruh family refresh token milik sesi ini
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param credentials body model.RefreshRequest true "Refresh Token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/logout [post]
+func (s *authService) LogoutHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return s.logoutLogic(c)
+	}
+}
+
+func (s *authService) logoutLogic(c *fiber.Ctx) error {
+	var body model.RefreshRequest
+	if err := c.BodyParser(&body); err != nil || body.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token wajib diisi"})
+	}
+
+	current, err := s.refreshRepo.FindByHash(hashToken(body.RefreshToken))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa refresh token"})
+	}
+
+	// Token yang tidak dikenal tetap dijawab sukses agar logout idempoten
+	if current != nil {
+		if err := s.sessions.End(current.FamilyID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut refresh token"})
+		}
+	}
+
+	return c.JSON(fiber.Map{"message": "logout berhasil"})
+}
+
+// issueTokens memulai sesi baru untuk login yang berhasil lalu membuat
+// access token dan refresh token pertamanya.
+func (s *authService) issueTokens(c *fiber.Ctx, user *model.Users) (*model.TokenPair, error) {
+	session, err := s.sessions.Start(user.ID, c.IP(), c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokensWith(user, session.ID, refreshToken)
+}
+
+func (s *authService) issueTokensWith(user *model.Users, familyID, refreshToken string) (*model.TokenPair, error) {
+	accessToken, err := s.generateJWT(user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.GetRefreshTokenExpiry()),
+	}
+	if err := s.refreshRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.GetJWTExpiry().Seconds()),
+	}, nil
+}
+
+// newOpaqueToken menghasilkan token acak 256-bit yang aman dipakai di URL.
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken dipakai untuk menyimpan token di database tanpa menyimpan nilai aslinya.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
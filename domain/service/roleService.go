@@ -0,0 +1,221 @@
+package service
+
+import (
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RoleService interface {
+	ListPermissionsHandler() fiber.Handler
+	ListRolesHandler() fiber.Handler
+	CreateRoleHandler() fiber.Handler
+	UpdateRoleHandler() fiber.Handler
+	DeleteRoleHandler() fiber.Handler
+}
+
+type roleService struct {
+	roleRepo model.RoleRepository
+	userRepo model.UserRepository
+}
+
+func NewRoleService(roleRepo model.RoleRepository, userRepo model.UserRepository) RoleService {
+	return &roleService{roleRepo: roleRepo, userRepo: userRepo}
+}
+
+// @Summary Daftar permission
+// @Description Mengambil semua permission yang bisa dipakai role
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string
+// @Router /api/admin/permissions [get]
+func (s *roleService) ListPermissionsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"permissions": model.AllPermissions})
+	}
+}
+
+// @Summary Daftar role
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Role
+// @Router /api/admin/roles [get]
+func (s *roleService) ListRolesHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		roles, err := s.roleRepo.FindAll()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengambil role"})
+		}
+		return c.JSON(fiber.Map{"roles": roles})
+	}
+}
+
+// @Summary Buat role kustom
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.RoleRequest true "Data Role"
+// @Success 201 {object} model.Role
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/roles [post]
+func (s *roleService) CreateRoleHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.RoleRequest
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		name := normalizeRoleName(body.Name)
+		if name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "nama role wajib diisi"})
+		}
+		if err := validatePermissions(body.Permissions); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if status, msg := checkGrantable(c, body.Permissions); status != fiber.StatusOK {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		existing, err := s.roleRepo.FindByName(name)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek role"})
+		}
+		if existing != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role sudah ada"})
+		}
+
+		role := &model.Role{
+			Name:        name,
+			Description: body.Description,
+			Permissions: body.Permissions,
+		}
+		if err := s.roleRepo.Create(role); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membuat role"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "role berhasil dibuat",
+			"role":    role,
+		})
+	}
+}
+
+// @Summary Ubah permission role
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Nama role"
+// @Param credentials body model.RoleRequest true "Data Role"
+// @Success 200 {object} model.Role
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/roles/{name} [put]
+func (s *roleService) UpdateRoleHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name := normalizeRoleName(c.Params("name"))
+
+		var body model.RoleRequest
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		if err := validatePermissions(body.Permissions); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if status, msg := checkGrantable(c, body.Permissions); status != fiber.StatusOK {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		// Role admin dikunci agar tidak ada yang bisa mengunci dirinya sendiri
+		if name == "admin" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role admin tidak dapat diubah"})
+		}
+
+		role := &model.Role{
+			Name:        name,
+			Description: body.Description,
+			Permissions: body.Permissions,
+		}
+		if err := s.roleRepo.Update(role); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "role tidak ditemukan"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengubah role"})
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "role berhasil diubah",
+			"role":    role,
+		})
+	}
+}
+
+// @Summary Hapus role kustom
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Nama role"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/roles/{name} [delete]
+func (s *roleService) DeleteRoleHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name := normalizeRoleName(c.Params("name"))
+
+		inUse, err := s.userRepo.CountByRole(name)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek pemakaian role"})
+		}
+		if inUse > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role masih dipakai oleh user"})
+		}
+
+		if err := s.roleRepo.Delete(name); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "role tidak ditemukan atau merupakan role bawaan"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menghapus role"})
+		}
+
+		return c.JSON(fiber.Map{"message": "role berhasil dihapus"})
+	}
+}
+
+func normalizeRoleName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func validatePermissions(permissions []string) error {
+	if len(permissions) == 0 {
+		return errors.New("permissions wajib diisi")
+	}
+	for _, p := range permissions {
+		// Wildcard hanya dimiliki role admin bawaan
+		if p == model.PermAll {
+			return errors.New("permission * tidak dapat diberikan ke role kustom")
+		}
+		if !model.IsKnownPermission(p) {
+			return errors.New("permission tidak dikenal: " + p)
+		}
+	}
+	return nil
+}
+
+// checkGrantable menolak permission yang tidak dimiliki pemanggil, dengan
+// aturan yang sama seperti pembuatan API key.
+func checkGrantable(c *fiber.Ctx, permissions []string) (int, string) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return fiber.StatusUnauthorized, "unauthorized"
+	}
+	if missing := principal.MissingPermission(permissions); missing != "" {
+		return fiber.StatusForbidden, "tidak dapat memberikan permission yang tidak Anda miliki: " + missing
+	}
+	return fiber.StatusOK, ""
+}
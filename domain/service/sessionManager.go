@@ -0,0 +1,78 @@
+package service
+
+import (
+	"Mongo/domain/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SessionManager mencatat sesi login dan mencabutnya bersama family
+// refresh token-nya, sehingga access token (lewat klaim sid) dan refresh
+// token berhenti berlaku bersamaan.
+type SessionManager struct {
+	sessions model.SessionRepository
+	refresh  model.RefreshTokenRepository
+}
+
+func NewSessionManager(sessions model.SessionRepository, refresh model.RefreshTokenRepository) *SessionManager {
+	return &SessionManager{sessions: sessions, refresh: refresh}
+}
+
+// Start membuat sesi baru untuk login yang baru saja berhasil.
+func (m *SessionManager) Start(userID primitive.ObjectID, ip, userAgent string) (*model.Session, error) {
+	session := &model.Session{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    userID,
+		IP:        ip,
+		UserAgent: userAgent,
+	}
+	if err := m.sessions.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Resume dipanggil saat refresh token dirotasi. Sesi yang sudah dicabut
+// menghasilkan false. Family lama yang dibuat sebelum sesi dicatat
+// dibuatkan sesinya di sini.
+func (m *SessionManager) Resume(userID primitive.ObjectID, sessionID, ip, userAgent string) (bool, error) {
+	session, err := m.sessions.FindByID(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session == nil {
+		err := m.sessions.Create(&model.Session{ID: sessionID, UserID: userID, IP: ip, UserAgent: userAgent})
+		return err == nil, err
+	}
+	if !session.IsActive() || session.UserID != userID {
+		return false, nil
+	}
+	return true, m.sessions.Touch(sessionID, time.Now(), ip)
+}
+
+// Find mengembalikan sesi, atau nil jika tidak ada.
+func (m *SessionManager) Find(sessionID string) (*model.Session, error) {
+	return m.sessions.FindByID(sessionID)
+}
+
+// List mengembalikan sesi aktif milik user.
+func (m *SessionManager) List(userID primitive.ObjectID) ([]model.Session, error) {
+	return m.sessions.ListActiveByUser(userID)
+}
+
+// End mencabut satu sesi beserta family refresh token-nya.
+func (m *SessionManager) End(sessionID string) error {
+	if err := m.refresh.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+	return m.sessions.Revoke(sessionID)
+}
+
+// EndAll mencabut semua sesi milik user.
+func (m *SessionManager) EndAll(userID primitive.ObjectID) error {
+	if err := m.refresh.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	return m.sessions.RevokeAllForUser(userID)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionService melayani daftar dan pencabutan sesi milik pemanggil sendiri.
+type SessionService interface {
+	ListMySessionsHandler() fiber.Handler
+	RevokeMySessionHandler() fiber.Handler
+}
+
+type sessionService struct {
+	sessions *SessionManager
+}
+
+func NewSessionService(sessions *SessionManager) SessionService {
+	return &sessionService{sessions: sessions}
+}
+
+// @Summary Daftar sesi aktif akun sendiri
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.SessionInfo
+// @Router /api/me/sessions [get]
+func (s *sessionService) ListMySessionsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user ID tidak ditemukan di token"})
+		}
+
+		sessions, err := s.sessions.List(principal.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat sesi"})
+		}
+
+		return c.JSON(fiber.Map{"sessions": model.NewSessionInfos(sessions, principal.SessionID)})
+	}
+}
+
+// @Summary Keluarkan satu sesi
+// @Description Access token dan refresh token sesi tersebut langsung berhenti berlaku
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID sesi"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/me/sessions/{id} [delete]
+func (s *sessionService) RevokeMySessionHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user ID tidak ditemukan di token"})
+		}
+
+		session, err := s.sessions.Find(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat sesi"})
+		}
+		// Sesi milik user lain diperlakukan seperti tidak ada
+		if session == nil || session.UserID != principal.UserID || !session.IsActive() {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "sesi tidak ditemukan"})
+		}
+
+		if err := s.sessions.End(session.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi"})
+		}
+
+		return c.JSON(fiber.Map{"message": "sesi berhasil dicabut"})
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	totpPeriod        = 30
+	recoveryCodeCount = 10
+)
+
+var (
+	errTwoFactorEnabled    = errors.New("2FA sudah aktif")
+	errTwoFactorNotStarted = errors.New("setup 2FA belum dimulai")
+)
+
+// TwoFactorGuard mengelola pendaftaran TOTP, kode pemulihan, dan tiket
+// langkah kedua login (mfa_token).
+type TwoFactorGuard struct {
+	repo      model.TwoFactorRepository
+	tokenRepo model.OneTimeTokenRepository
+	cfg       config.TwoFactorConfig
+	now       func() time.Time
+}
+
+func NewTwoFactorGuard(repo model.TwoFactorRepository, tokenRepo model.OneTimeTokenRepository, cfg config.TwoFactorConfig) *TwoFactorGuard {
+	return &TwoFactorGuard{repo: repo, tokenRepo: tokenRepo, cfg: cfg, now: time.Now}
+}
+
+// RequiredByPolicy bernilai true jika role user wajib memakai 2FA.
+func (g *TwoFactorGuard) RequiredByPolicy(user *model.Users) bool {
+	return g.cfg.RequiredForAdmin && user.Role == "admin"
+}
+
+// Challenge dipanggil setelah password benar. Jika user memakai 2FA atau
+// diwajibkan memakainya, Challenge menerbitkan mfa_token dan setup bernilai
+// true bila user masih harus mendaftar lebih dulu. Token kosong berarti
+// login boleh langsung selesai.
+func (g *TwoFactorGuard) Challenge(user *model.Users) (token string, setup bool, err error) {
+	tf, err := g.repo.FindByUserID(user.ID)
+	if err != nil {
+		return "", false, err
+	}
+
+	enabled := tf != nil && tf.Enabled
+	if !enabled && !g.RequiredByPolicy(user) {
+		return "", false, nil
+	}
+
+	token, err = newOpaqueToken()
+	if err != nil {
+		return "", false, err
+	}
+	record := &model.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposeMFA,
+		TokenHash: hashToken(token),
+		ExpiresAt: g.now().Add(g.cfg.ChallengeExpiry),
+	}
+	if err := g.tokenRepo.Create(record); err != nil {
+		return "", false, err
+	}
+
+	return token, !enabled, nil
+}
+
+// ResolveChallenge mengembalikan tiket yang masih berlaku, atau nil jika
+// token tidak dikenal, kedaluwarsa, atau sudah dipakai.
+func (g *TwoFactorGuard) ResolveChallenge(token string) (*model.OneTimeToken, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	record, err := g.tokenRepo.FindByHash(model.TokenPurposeMFA, hashToken(token))
+	if err != nil || record == nil {
+		return nil, err
+	}
+	if record.UsedAt != nil || g.now().After(record.ExpiresAt) {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// CompleteChallenge menandai tiket terpakai secara atomik sehingga satu
+// mfa_token hanya menghasilkan satu sesi.
+func (g *TwoFactorGuard) CompleteChallenge(record *model.OneTimeToken) (bool, error) {
+	return g.tokenRepo.Consume(record.ID)
+}
+
+// Enabled bernilai true jika user sudah menyelesaikan pendaftaran 2FA.
+func (g *TwoFactorGuard) Enabled(userID primitive.ObjectID) (bool, error) {
+	tf, err := g.repo.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return tf != nil && tf.Enabled, nil
+}
+
+// Begin membuat secret baru yang belum aktif dan mengembalikan secret
+// beserta URI otpauth untuk dipindai aplikasi authenticator. Setup yang
+// belum dikonfirmasi sebelumnya diganti.
+func (g *TwoFactorGuard) Begin(user *model.Users) (*otp.Key, error) {
+	tf, err := g.repo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf != nil && tf.Enabled {
+		return nil, errTwoFactorEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: g.cfg.Issuer, AccountName: user.Email, Period: totpPeriod})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.repo.Save(&model.TwoFactor{UserID: user.ID, Secret: key.Secret()}); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Confirm mengaktifkan setup yang tertunda jika kode cocok dan
+// mengembalikan kode pemulihan dalam bentuk asli. Kode pemulihan hanya
+// ditampilkan sekali ini.
+func (g *TwoFactorGuard) Confirm(userID primitive.ObjectID, code string) ([]string, bool, error) {
+	tf, err := g.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if tf == nil {
+		return nil, false, errTwoFactorNotStarted
+	}
+	if tf.Enabled {
+		return nil, false, errTwoFactorEnabled
+	}
+
+	step, ok := g.matchStep(tf.Secret, code)
+	if !ok {
+		return nil, false, nil
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := g.now()
+	tf.Enabled = true
+	tf.ConfirmedAt = &now
+	tf.LastStep = step
+	tf.RecoveryCodes = hashes
+	if err := g.repo.Save(tf); err != nil {
+		return nil, false, err
+	}
+	return codes, true, nil
+}
+
+// Verify memeriksa kode TOTP atau, jika diisi, kode pemulihan. Masing-masing
+// hanya bisa dipakai sekali.
+func (g *TwoFactorGuard) Verify(userID primitive.ObjectID, code, recoveryCode string) (bool, error) {
+	tf, err := g.repo.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	if tf == nil || !tf.Enabled {
+		return false, nil
+	}
+
+	if recoveryCode != "" {
+		return g.repo.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(recoveryCode)))
+	}
+
+	step, ok := g.matchStep(tf.Secret, code)
+	if !ok {
+		return false, nil
+	}
+	return g.repo.MarkStepUsed(userID, step)
+}
+
+// RegenerateRecoveryCodes mengganti semua kode pemulihan lama.
+func (g *TwoFactorGuard) RegenerateRecoveryCodes(userID primitive.ObjectID) ([]string, error) {
+	tf, err := g.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || !tf.Enabled {
+		return nil, errTwoFactorNotStarted
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	tf.RecoveryCodes = hashes
+	if err := g.repo.Save(tf); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (g *TwoFactorGuard) Disable(userID primitive.ObjectID) error {
+	return g.repo.Delete(userID)
+}
+
+// matchStep mencocokkan kode dengan time step saat ini dan satu step di
+// sebelah-menyebelahnya untuk menoleransi selisih jam.
+func (g *TwoFactorGuard) matchStep(secret, code string) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != 6 {
+		return 0, false
+	}
+
+	now := g.now()
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	for _, skew := range []int64{0, -1, 1} {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(secret, at, opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes membuat kode pemulihan berbentuk "xxxx-xxxx" beserta hash-nya.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+		code := raw[:4] + "-" + raw[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
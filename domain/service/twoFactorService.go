@@ -0,0 +1,325 @@
+package service
+
+import (
+	"Mongo/domain/model"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TwoFactorService melayani pendaftaran dan pengelolaan TOTP milik
+// pemanggil sendiri.
+type TwoFactorService interface {
+	StatusHandler() fiber.Handler
+	SetupHandler() fiber.Handler
+	ConfirmHandler() fiber.Handler
+	DisableHandler() fiber.Handler
+	RecoveryCodesHandler() fiber.Handler
+}
+
+type twoFactorService struct {
+	userRepo model.UserRepository
+	guard    *TwoFactorGuard
+}
+
+func NewTwoFactorService(userRepo model.UserRepository, guard *TwoFactorGuard) TwoFactorService {
+	return &twoFactorService{userRepo: userRepo, guard: guard}
+}
+
+// @Summary Status 2FA akun sendiri
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]bool
+// @Router /api/me/2fa [get]
+func (s *twoFactorService) StatusHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		enabled, err := s.guard.Enabled(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa 2FA"})
+		}
+
+		return c.JSON(fiber.Map{"enabled": enabled, "required": s.guard.RequiredByPolicy(user)})
+	}
+}
+
+// @Summary Mulai setup 2FA
+// @Description Mengembalikan secret dan URI otpauth. 2FA baru aktif setelah dikonfirmasi dengan kode pertama.
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/me/2fa/setup [post]
+func (s *twoFactorService) SetupHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		return beginTwoFactorSetup(c, s.guard, user)
+	}
+}
+
+// @Summary Konfirmasi setup 2FA
+// @Description Mengaktifkan 2FA dan mengembalikan kode pemulihan yang hanya ditampilkan sekali.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.TwoFactorCode true "Kode dari aplikasi authenticator"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/me/2fa/confirm [post]
+func (s *twoFactorService) ConfirmHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.TwoFactorCode
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		codes, ok, err := s.guard.Confirm(user.ID, body.Code)
+		if err != nil {
+			return twoFactorError(c, err)
+		}
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "kode 2FA salah"})
+		}
+
+		return c.JSON(fiber.Map{"message": "2FA berhasil diaktifkan, simpan kode pemulihan di tempat aman", "recovery_codes": codes})
+	}
+}
+
+// @Summary Nonaktifkan 2FA
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.TwoFactorCode true "Kode 2FA atau kode pemulihan"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/me/2fa/disable [post]
+func (s *twoFactorService) DisableHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.TwoFactorCode
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if s.guard.RequiredByPolicy(user) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "2FA wajib untuk role ini dan tidak bisa dinonaktifkan"})
+		}
+
+		ok, err := s.guard.Verify(user.ID, body.Code, body.RecoveryCode)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa kode 2FA"})
+		}
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "kode 2FA salah"})
+		}
+
+		if err := s.guard.Disable(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menonaktifkan 2FA"})
+		}
+
+		return c.JSON(fiber.Map{"message": "2FA berhasil dinonaktifkan"})
+	}
+}
+
+// @Summary Buat ulang kode pemulihan 2FA
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body model.TwoFactorCode true "Kode 2FA"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/me/2fa/recovery-codes [post]
+func (s *twoFactorService) RecoveryCodesHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.TwoFactorCode
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		user, status, msg := loadCallerUser(c, s.userRepo)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		// Kode pemulihan tidak bisa dipakai untuk membuat kode pemulihan baru
+		ok, err := s.guard.Verify(user.ID, body.Code, "")
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa kode 2FA"})
+		}
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "kode 2FA salah"})
+		}
+
+		codes, err := s.guard.RegenerateRecoveryCodes(user.ID)
+		if err != nil {
+			return twoFactorError(c, err)
+		}
+
+		return c.JSON(fiber.Map{"message": "kode pemulihan lama tidak berlaku lagi", "recovery_codes": codes})
+	}
+}
+
+// @Summary Langkah kedua login (2FA)
+// @Description Menukar mfa_token dari /api/login dengan kode TOTP atau kode pemulihan. Untuk akun yang wajib 2FA tetapi belum mendaftar, kode pertama sekaligus mengaktifkan 2FA.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param credentials body model.LoginTwoFactor true "mfa_token dan kode"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/login/2fa [post]
+func (s *authService) LoginTwoFactorHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.LoginTwoFactor
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		record, user, err := s.resolveMFAToken(body.MFAToken)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi mfa_token"})
+		}
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "mfa_token tidak valid atau sudah kedaluwarsa"})
+		}
+
+		// Tebakan kode dibatasi oleh penghitung yang sama dengan password
+		wait, err := s.guard.Reserve(user.Email, c.IP())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa percobaan login"})
+		}
+		if wait > 0 {
+			return tooManyAttempts(c, wait)
+		}
+
+		enabled, err := s.twoFactor.Enabled(user.ID)
+		if err != nil {
+			s.guard.Cancel(user.Email, c.IP())
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa 2FA"})
+		}
+
+		var ok bool
+		var recoveryCodes []string
+		if enabled {
+			ok, err = s.twoFactor.Verify(user.ID, body.Code, body.RecoveryCode)
+		} else {
+			recoveryCodes, ok, err = s.twoFactor.Confirm(user.ID, body.Code)
+		}
+		if err != nil {
+			s.guard.Cancel(user.Email, c.IP())
+			return twoFactorError(c, err)
+		}
+		if !ok {
+			if err := s.guard.Fail(user.Email, c.IP()); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa percobaan login"})
+			}
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "kode 2FA salah"})
+		}
+
+		consumed, err := s.twoFactor.CompleteChallenge(record)
+		if err != nil {
+			s.guard.Cancel(user.Email, c.IP())
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi mfa_token"})
+		}
+		if !consumed {
+			s.guard.Cancel(user.Email, c.IP())
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "mfa_token tidak valid atau sudah kedaluwarsa"})
+		}
+		if err := s.guard.Succeed(user.Email, c.IP()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memeriksa percobaan login"})
+		}
+
+		var extra fiber.Map
+		if recoveryCodes != nil {
+			extra = fiber.Map{"recovery_codes": recoveryCodes}
+		}
+		return s.completeLogin(c, user, extra)
+	}
+}
+
+// @Summary Setup 2FA saat login
+// @Description Untuk akun yang wajib 2FA tetapi belum mendaftar. mfa_token tidak habis dipakai di sini.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param credentials body model.LoginTwoFactor true "mfa_token"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} model.ErrorResponse
+// @Router /api/login/2fa/setup [post]
+func (s *authService) LoginTwoFactorSetupHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.LoginTwoFactor
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+
+		_, user, err := s.resolveMFAToken(body.MFAToken)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi mfa_token"})
+		}
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "mfa_token tidak valid atau sudah kedaluwarsa"})
+		}
+
+		return beginTwoFactorSetup(c, s.twoFactor, user)
+	}
+}
+
+// resolveMFAToken memuat tiket dan user-nya. user bernilai nil jika tiket
+// tidak berlaku atau akun tidak lagi boleh login.
+func (s *authService) resolveMFAToken(token string) (*model.OneTimeToken, *model.Users, error) {
+	record, err := s.twoFactor.ResolveChallenge(token)
+	if err != nil || record == nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.FindByID(record.UserID)
+	if err != nil || user == nil {
+		return nil, nil, err
+	}
+	if user.IsSuspended() || !user.IsVerified() {
+		return nil, nil, nil
+	}
+	return record, user, nil
+}
+
+func beginTwoFactorSetup(c *fiber.Ctx, guard *TwoFactorGuard, user *model.Users) error {
+	key, err := guard.Begin(user)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "pindai URI di aplikasi authenticator lalu konfirmasi dengan kode pertama",
+		"secret":      key.Secret(),
+		"otpauth_uri": key.URL(),
+	})
+}
+
+func twoFactorError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errTwoFactorEnabled) || errors.Is(err, errTwoFactorNotStarted) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memproses 2FA"})
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UserAdminService interface {
+	GetUserHandler() fiber.Handler
+	UpdateUserHandler() fiber.Handler
+	ChangeRoleHandler() fiber.Handler
+	SuspendUserHandler() fiber.Handler
+	ReactivateUserHandler() fiber.Handler
+	DeleteUserHandler() fiber.Handler
+	ListSessionsHandler() fiber.Handler
+	RevokeSessionsHandler() fiber.Handler
+}
+
+type userAdminService struct {
+	userRepo   model.UserRepository
+	roleRepo   model.RoleRepository
+	sessions   *SessionManager
+	tokenRepo  model.OneTimeTokenRepository
+	auditRepo  model.AuditRepository
+	alumniRepo model.AlumniRepository
+}
+
+func NewUserAdminService(userRepo model.UserRepository, roleRepo model.RoleRepository, sessions *SessionManager, tokenRepo model.OneTimeTokenRepository, auditRepo model.AuditRepository, alumniRepo model.AlumniRepository) UserAdminService {
+	return &userAdminService{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		sessions:   sessions,
+		tokenRepo:  tokenRepo,
+		auditRepo:  auditRepo,
+		alumniRepo: alumniRepo,
+	}
+}
+
+// @Summary Detail user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} model.Users
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/admin/users/{id} [get]
+func (s *userAdminService) GetUserHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		return c.JSON(fiber.Map{"user": model.NewAdminUser(user)})
+	}
+}
+
+// @Summary Ubah data user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Param credentials body model.UpdateUser true "Data user"
+// @Success 200 {object} model.Users
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/admin/users/{id} [put]
+func (s *userAdminService) UpdateUserHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.UpdateUser
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		body.Email = model.NormalizeEmail(body.Email)
+		body.Username = strings.TrimSpace(body.Username)
+		if body.Email == "" && body.Username == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email atau username wajib diisi"})
+		}
+
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		changes := fiber.Map{}
+		if body.Email != "" && body.Email != user.Email {
+			existing, err := s.userRepo.FindByEmail(body.Email)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek email"})
+			}
+			if existing != nil {
+				return emailTaken(c)
+			}
+			changes["email"] = fiber.Map{"from": user.Email, "to": body.Email}
+			user.Email = body.Email
+		}
+		if body.Username != "" && body.Username != user.Username {
+			changes["username"] = fiber.Map{"from": user.Username, "to": body.Username}
+			user.Username = body.Username
+		}
+
+		if len(changes) > 0 {
+			if err := s.userRepo.Update(user); err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					return emailTaken(c)
+				}
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengubah user"})
+			}
+			s.audit(c, model.AuditUserUpdated, user.ID, changes)
+		}
+
+		return c.JSON(fiber.Map{"message": "user berhasil diubah", "user": model.NewAdminUser(user)})
+	}
+}
+
+// @Summary Ganti role user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Param credentials body model.ChangeRole true "Role baru"
+// @Success 200 {object} model.Users
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/role [put]
+func (s *userAdminService) ChangeRoleHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body model.ChangeRole
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+		}
+		roleName := normalizeRoleName(body.Role)
+		if roleName == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role wajib diisi"})
+		}
+
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if user.Role == roleName {
+			return c.JSON(fiber.Map{"message": "role user tidak berubah", "user": model.NewAdminUser(user)})
+		}
+		if s.isSelf(c, user) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tidak dapat mengganti role akun sendiri"})
+		}
+
+		role, err := s.roleRepo.FindByName(roleName)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek role"})
+		}
+		if role == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "role tidak ditemukan"})
+		}
+		// Pemegang users:manage tidak boleh menaikkan user ke role yang
+		// permission-nya melebihi miliknya sendiri
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		if missing := principal.MissingPermission(role.Permissions); missing != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "tidak dapat memberikan role dengan permission yang tidak Anda miliki: " + missing})
+		}
+
+		unlock, err := s.guardLastAdmin(user)
+		if err != nil {
+			return lastAdminError(c, err)
+		}
+		defer unlock()
+
+		previous := user.Role
+		if err := s.userRepo.UpdateRole(user.ID, roleName); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengganti role"})
+		}
+		user.Role = roleName
+		// Sesi lama dicabut agar user login ulang dengan role barunya
+		if err := s.sessions.EndAll(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi user"})
+		}
+		s.audit(c, model.AuditUserRoleChanged, user.ID, fiber.Map{"from": previous, "to": roleName})
+
+		return c.JSON(fiber.Map{"message": "role user berhasil diganti", "user": model.NewAdminUser(user)})
+	}
+}
+
+// @Summary Nonaktifkan user
+// @Description Menonaktifkan akun dan mencabut semua sesinya
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/suspend [post]
+func (s *userAdminService) SuspendUserHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if user.IsSuspended() {
+			return c.JSON(fiber.Map{"message": "user sudah dinonaktifkan"})
+		}
+		if s.isSelf(c, user) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tidak dapat menonaktifkan akun sendiri"})
+		}
+		unlock, err := s.guardLastAdmin(user)
+		if err != nil {
+			return lastAdminError(c, err)
+		}
+		defer unlock()
+
+		previous := user.Status
+		if err := s.userRepo.UpdateStatus(user.ID, model.UserStatusSuspended); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menonaktifkan user"})
+		}
+		user.Status = model.UserStatusSuspended
+		if err := s.sessions.EndAll(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi user"})
+		}
+		// Tautan verifikasi lama tidak boleh dipakai untuk keluar dari suspend
+		if err := s.tokenRepo.InvalidateForUser(user.ID, model.TokenPurposeEmailVerification); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membatalkan tautan verifikasi"})
+		}
+		s.audit(c, model.AuditUserSuspended, user.ID, fiber.Map{"previous_status": previous})
+
+		return c.JSON(fiber.Map{"message": "user berhasil dinonaktifkan"})
+	}
+}
+
+// @Summary Aktifkan kembali user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/reactivate [post]
+func (s *userAdminService) ReactivateUserHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if !user.IsSuspended() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "user tidak dalam status nonaktif"})
+		}
+
+		// Akun yang dinonaktifkan sebelum verifikasi email tetap harus
+		// memverifikasi emailnya
+		restored := model.UserStatusActive
+		if user.VerifiedAt == nil {
+			restored = model.UserStatusUnverified
+		}
+		if err := s.userRepo.UpdateStatus(user.ID, restored); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengaktifkan user"})
+		}
+		user.Status = restored
+		s.audit(c, model.AuditUserReactivated, user.ID, fiber.Map{"status": restored})
+
+		return c.JSON(fiber.Map{"message": "user berhasil diaktifkan kembali"})
+	}
+}
+
+// @Summary Hapus user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/users/{id} [delete]
+func (s *userAdminService) DeleteUserHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if s.isSelf(c, user) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tidak dapat menghapus akun sendiri"})
+		}
+		unlock, err := s.guardLastAdmin(user)
+		if err != nil {
+			return lastAdminError(c, err)
+		}
+		defer unlock()
+
+		if err := s.userRepo.Delete(user.ID); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user tidak ditemukan"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal menghapus user"})
+		}
+		if err := s.sessions.EndAll(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi user"})
+		}
+		// NIM yang tertaut dilepas agar bisa diklaim lagi dan tidak menunjuk
+		// ke akun yang sudah tidak ada
+		if err := s.alumniRepo.UnlinkUser(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal melepas tautan alumni"})
+		}
+		s.audit(c, model.AuditUserDeleted, user.ID, fiber.Map{"email": user.Email, "role": user.Role})
+
+		return c.JSON(fiber.Map{"message": "user berhasil dihapus"})
+	}
+}
+
+// @Summary Daftar sesi aktif user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {array} model.SessionInfo
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/sessions [get]
+func (s *userAdminService) ListSessionsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		sessions, err := s.sessions.List(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memuat sesi"})
+		}
+
+		return c.JSON(fiber.Map{"sessions": model.NewSessionInfos(sessions, "")})
+	}
+}
+
+// @Summary Cabut semua sesi user
+// @Description Access token dan refresh token user langsung berhenti berlaku
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/sessions [delete]
+func (s *userAdminService) RevokeSessionsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadTarget(c)
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		if err := s.sessions.EndAll(user.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencabut sesi user"})
+		}
+		s.audit(c, model.AuditUserSessionsRevoked, user.ID, nil)
+
+		return c.JSON(fiber.Map{"message": "semua sesi user berhasil dicabut"})
+	}
+}
+
+var errLastAdmin = errors.New("tidak dapat mengubah admin aktif terakhir")
+
+// guardLastAdmin menolak perubahan yang akan membuat sistem tanpa admin aktif.
+// Untuk target admin aktif, kunci perubahan admin diambil sebelum menghitung
+// dan harus dilepas pemanggil setelah perubahan disimpan, sehingga dua admin
+// yang saling menurunkan tidak sama-sama lolos pengecekan.
+func (s *userAdminService) guardLastAdmin(target *model.Users) (func(), error) {
+	if target.Role != "admin" || target.IsSuspended() || !target.IsVerified() {
+		return func() {}, nil
+	}
+
+	unlock, err := s.userRepo.LockAdminChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.userRepo.CountActiveByRole("admin")
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	if count <= 1 {
+		unlock()
+		return nil, errLastAdmin
+	}
+	return unlock, nil
+}
+
+func lastAdminError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errLastAdmin) || errors.Is(err, model.ErrAdminChangeBusy) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek jumlah admin"})
+}
+
+func (s *userAdminService) loadTarget(c *fiber.Ctx) (*model.Users, int, string) {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "ID user tidak valid"
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal mencari user"
+	}
+	if user == nil {
+		return nil, fiber.StatusNotFound, "user tidak ditemukan"
+	}
+
+	return user, fiber.StatusOK, ""
+}
+
+func (s *userAdminService) isSelf(c *fiber.Ctx, target *model.Users) bool {
+	principal, ok := middleware.GetPrincipal(c)
+	return ok && principal.UserID == target.ID
+}
+
+// audit mencatat perubahan. Kegagalan hanya dicatat di log karena
+// perubahan datanya sudah terjadi.
+func (s *userAdminService) audit(c *fiber.Ctx, action string, targetID primitive.ObjectID, details fiber.Map) {
+	entry := &model.AuditLog{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   targetID.Hex(),
+		Details:    details,
+		IP:         c.IP(),
+	}
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		entry.ActorID = principal.UserID
+	}
+
+	if err := s.auditRepo.Create(entry); err != nil {
+		log.Printf("gagal menulis audit log %s untuk %s: %v", action, entry.TargetID, err)
+	}
+}
@@ -17,7 +17,7 @@ import (
 // @Accept json
 // @Produce json
 // @Failure 400 {object} model.ErrorResponse
-// @Success 200 {array} model.Users
+// @Success 200 {object} model.UserResponse
 // @Router /api/users [get]
 func GetUsersService(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -49,7 +49,7 @@ func GetUsersService(c *fiber.Ctx) error {
 	}
 
 	response := model.UserResponse{
-		Data: users,
+		Data: model.NewAdminUsers(users),
 		MetaInfo: model.MetaInfo{
 			CurrentPage: page,
 			Limit:       limit,
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"Mongo/domain/config"
+	"Mongo/domain/mailer"
+	"Mongo/domain/model"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type VerificationService interface {
+	// SendVerification menerbitkan tautan verifikasi baru untuk user dan
+	// membatalkan tautan sebelumnya.
+	// Jika user punya PendingEmail, tautan dikirim ke alamat itu.
+	SendVerification(user *model.Users) error
+	VerifyEmailHandler() fiber.Handler
+	ResendVerificationHandler() fiber.Handler
+	VerifyUserHandler() fiber.Handler
+}
+
+type verificationService struct {
+	userRepo  model.UserRepository
+	tokenRepo model.OneTimeTokenRepository
+	mailer    mailer.Mailer
+}
+
+func NewVerificationService(userRepo model.UserRepository, tokenRepo model.OneTimeTokenRepository, m mailer.Mailer) VerificationService {
+	return &verificationService{userRepo: userRepo, tokenRepo: tokenRepo, mailer: m}
+}
+
+func (s *verificationService) SendVerification(user *model.Users) error {
+	if err := s.tokenRepo.InvalidateForUser(user.ID, model.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	to := user.Email
+	if user.PendingEmail != "" {
+		to = user.PendingEmail
+	}
+
+	expiry := config.GetEmailVerificationExpiry()
+	record := &model.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposeEmailVerification,
+		TokenHash: hashToken(token),
+		Email:     to,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if err := s.tokenRepo.Create(record); err != nil {
+		return err
+	}
+
+	link := config.GetAppBaseURL() + "/api/email/verify?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      to,
+		Subject: "Verifikasi email akun Anda",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKlik tautan berikut untuk memverifikasi email Anda:\n%s\n\nTautan berlaku selama %s. Abaikan email ini jika Anda tidak mendaftar.\n",
+			user.Username, link, expiry,
+		),
+	})
+}
+
+// @Summary Verifikasi email
+// @Description Mengaktifkan akun memakai token dari email verifikasi. Token bisa dikirim lewat query (tautan email) atau body JSON.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token query string false "Token verifikasi"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/email/verify [get]
+func (s *verificationService) VerifyEmailHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Query("token")
+		if token == "" && len(c.Body()) > 0 {
+			var body model.VerifyEmail
+			if err := c.BodyParser(&body); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+			}
+			token = body.Token
+		}
+		if token == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token wajib diisi"})
+		}
+
+		record, err := s.tokenRepo.FindByHash(model.TokenPurposeEmailVerification, hashToken(token))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi token"})
+		}
+		if record == nil || record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token verifikasi tidak valid atau sudah kedaluwarsa"})
+		}
+
+		user, err := s.userRepo.FindByID(record.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mencari user"})
+		}
+		if user == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token verifikasi tidak valid atau sudah kedaluwarsa"})
+		}
+		if user.IsSuspended() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+		}
+
+		// Token yang dikirim ke email baru hanya berlaku selama alamat itu
+		// masih menunggu verifikasi, dan baru saat itu email akun diganti
+		changeEmail := record.Email != "" && record.Email != user.Email
+		if changeEmail {
+			if record.Email != user.PendingEmail {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token verifikasi tidak valid atau sudah kedaluwarsa"})
+			}
+			existing, err := s.userRepo.FindByEmail(record.Email)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengecek email"})
+			}
+			if existing != nil && existing.ID != user.ID {
+				return emailTaken(c)
+			}
+		}
+
+		consumed, err := s.tokenRepo.Consume(record.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memvalidasi token"})
+		}
+		if !consumed {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token verifikasi tidak valid atau sudah kedaluwarsa"})
+		}
+
+		if changeEmail {
+			user.Email = user.PendingEmail
+			user.PendingEmail = ""
+		}
+		if err := s.markVerified(user); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return emailTaken(c)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memverifikasi user"})
+		}
+
+		return c.JSON(fiber.Map{"message": "email berhasil diverifikasi, silakan login"})
+	}
+}
+
+// @Summary Kirim ulang tautan verifikasi
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/verification [post]
+func (s *verificationService) ResendVerificationHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadUser(c.Params("id"))
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if user.IsVerified() && user.PendingEmail == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email user sudah terverifikasi"})
+		}
+
+		if err := s.SendVerification(user); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal mengirim email verifikasi"})
+		}
+
+		return c.JSON(fiber.Map{"message": "tautan verifikasi telah dikirim ulang"})
+	}
+}
+
+// @Summary Verifikasi user secara manual
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID user"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/admin/users/{id}/verify [post]
+func (s *verificationService) VerifyUserHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, status, msg := s.loadUser(c.Params("id"))
+		if user == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if user.IsVerified() {
+			return c.JSON(fiber.Map{"message": "email user sudah terverifikasi"})
+		}
+
+		if err := s.markVerified(user); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal memverifikasi user"})
+		}
+		if err := s.tokenRepo.InvalidateForUser(user.ID, model.TokenPurposeEmailVerification); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal membatalkan tautan verifikasi"})
+		}
+
+		return c.JSON(fiber.Map{"message": "user berhasil diverifikasi"})
+	}
+}
+
+// markVerified hanya mengaktifkan akun yang masih unverified; status lain,
+// termasuk suspended, tidak diubah.
+func (s *verificationService) markVerified(user *model.Users) error {
+	now := time.Now()
+	if user.Status == model.UserStatusUnverified {
+		user.Status = model.UserStatusActive
+	}
+	user.VerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+func (s *verificationService) loadUser(id string) (*model.Users, int, string) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "ID user tidak valid"
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, "gagal mencari user"
+	}
+	if user == nil {
+		return nil, fiber.StatusNotFound, "user tidak ditemukan"
+	}
+
+	return user, fiber.StatusOK, ""
+}
@@ -0,0 +1,204 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(key *model.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) FindByHash(hash string) (*model.APIKey, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindAll() ([]model.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(id, revokedBy primitive.ObjectID) (bool, error) {
+	args := m.Called(id, revokedBy)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(id primitive.ObjectID, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func setupAPIKeyApp(principal *model.Principal) (*fiber.App, *MockAPIKeyRepository) {
+	repo := new(MockAPIKeyRepository)
+	svc := service.NewAPIKeyService(repo)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, principal)
+		return c.Next()
+	})
+	app.Post("/admin/api-keys", svc.CreateAPIKeyHandler())
+	app.Delete("/admin/api-keys/:id", svc.RevokeAPIKeyHandler())
+
+	return app, repo
+}
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	post := func(app *fiber.App, payload interface{}) (int, map[string]interface{}) {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest("POST", "/admin/api-keys", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	t.Run("Key hanya ditampilkan sekali dan disimpan sebagai hash", func(t *testing.T) {
+		app, repo := setupAPIKeyApp(adminActor)
+		var stored *model.APIKey
+		repo.On("Create", mock.AnythingOfType("*model.APIKey")).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*model.APIKey)
+		}).Return(nil)
+
+		status, result := post(app, model.CreateAPIKey{Name: "laporan-fakultas", Permissions: []string{model.PermAlumniRead}, ExpiresInDays: 30})
+		assert.Equal(t, fiber.StatusCreated, status)
+
+		rawKey, _ := result["key"].(string)
+		assert.True(t, strings.HasPrefix(rawKey, "amk_"))
+		assert.Equal(t, model.HashAPIKey(rawKey), stored.KeyHash)
+		assert.True(t, strings.HasPrefix(rawKey, stored.Prefix))
+		assert.NotContains(t, result["api_key"], "key_hash")
+		assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("Wildcard ditolak", func(t *testing.T) {
+		app, repo := setupAPIKeyApp(adminActor)
+
+		status, _ := post(app, model.CreateAPIKey{Name: "semua", Permissions: []string{model.PermAll}})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Permission melebihi pembuat ditolak", func(t *testing.T) {
+		limited := &model.Principal{UserID: primitive.NewObjectID(), Permissions: []string{model.PermAPIKeysManage, model.PermAlumniRead}}
+		app, repo := setupAPIKeyApp(limited)
+
+		status, _ := post(app, model.CreateAPIKey{Name: "eskalasi", Permissions: []string{model.PermAlumniDelete}})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Permission pengelolaan admin ditolak", func(t *testing.T) {
+		app, repo := setupAPIKeyApp(adminActor)
+
+		for _, perm := range []string{model.PermAPIKeysManage, model.PermUsersManage, model.PermRolesManage, model.PermUsersImpersonate, model.PermInvitesManage} {
+			status, _ := post(app, model.CreateAPIKey{Name: "eskalasi", Permissions: []string{model.PermAlumniRead, perm}})
+			assert.Equal(t, fiber.StatusBadRequest, status, perm)
+		}
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestAPIKeyCannotManageAPIKeys(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+	repo.On("TouchLastUsed", mock.Anything, mock.Anything).Return(nil)
+
+	// Key lama yang dibuat sebelum batasan tetap tidak mendapat api_keys:manage
+	legacy := &model.APIKey{ID: primitive.NewObjectID(), Name: "lama", Permissions: []string{model.PermAlumniRead, model.PermAPIKeysManage}, ExpiresAt: time.Now().Add(time.Hour)}
+	repo.On("FindByHash", model.HashAPIKey("amk_legacy")).Return(legacy, nil)
+
+	deps := &middleware.AuthDeps{APIKeys: repo}
+	app := fiber.New()
+	app.Post("/admin/api-keys", middleware.JWTAuth(deps), middleware.RequireUser(), middleware.RequirePermission(model.PermAPIKeysManage), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	app.Get("/whoami", middleware.JWTAuth(deps), func(c *fiber.Ctx) error {
+		p, _ := middleware.GetPrincipal(c)
+		return c.JSON(p)
+	})
+
+	req := httptest.NewRequest("POST", "/admin/api-keys", nil)
+	req.Header.Set(model.APIKeyHeader, "amk_legacy")
+	resp, _ := app.Test(req)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	req = httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set(model.APIKeyHeader, "amk_legacy")
+	resp, _ = app.Test(req)
+	var principal model.Principal
+	json.NewDecoder(resp.Body).Decode(&principal)
+	assert.Equal(t, []string{model.PermAlumniRead}, principal.Permissions)
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	app, repo := setupAPIKeyApp(adminActor)
+	revokedID := primitive.NewObjectID()
+	repo.On("Revoke", revokedID, adminActor.UserID).Return(false, nil)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/admin/api-keys/"+revokedID.Hex(), nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestJWTAuthWithAPIKey(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+	repo.On("TouchLastUsed", mock.Anything, mock.Anything).Return(nil)
+
+	active := &model.APIKey{ID: primitive.NewObjectID(), Name: "sistem-akademik", Permissions: []string{model.PermAlumniRead}, ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &model.APIKey{ID: primitive.NewObjectID(), Permissions: []string{model.PermAlumniRead}, ExpiresAt: time.Now().Add(-time.Hour)}
+	revokedAt := time.Now()
+	revoked := &model.APIKey{ID: primitive.NewObjectID(), Permissions: []string{model.PermAlumniRead}, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	repo.On("FindByHash", model.HashAPIKey("amk_active")).Return(active, nil)
+	repo.On("FindByHash", model.HashAPIKey("amk_expired")).Return(expired, nil)
+	repo.On("FindByHash", model.HashAPIKey("amk_revoked")).Return(revoked, nil)
+	repo.On("FindByHash", mock.Anything).Return(nil, nil)
+
+	// Users dan Roles tidak diisi: jalur API key tidak boleh menyentuhnya
+	deps := &middleware.AuthDeps{APIKeys: repo}
+	app := fiber.New()
+	app.Get("/alumni", middleware.JWTAuth(deps), middleware.RequirePermission(model.PermAlumniRead), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Delete("/alumni/:nim", middleware.JWTAuth(deps), middleware.RequirePermission(model.PermAlumniDelete), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	call := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(model.APIKeyHeader, key)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, call("GET", "/alumni", "amk_active"))
+	assert.Equal(t, fiber.StatusForbidden, call("DELETE", "/alumni/123", "amk_active"))
+	assert.Equal(t, fiber.StatusUnauthorized, call("GET", "/alumni", "amk_expired"))
+	assert.Equal(t, fiber.StatusUnauthorized, call("GET", "/alumni", "amk_revoked"))
+	assert.Equal(t, fiber.StatusUnauthorized, call("GET", "/alumni", "amk_unknown"))
+	repo.AssertCalled(t, "TouchLastUsed", active.ID, mock.AnythingOfType("time.Time"))
+}
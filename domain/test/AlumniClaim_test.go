@@ -0,0 +1,220 @@
+package test
+
+import (
+	"testing"
+
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockAlumniClaimRepository struct {
+	mock.Mock
+}
+
+func (m *MockAlumniClaimRepository) Create(claim *model.AlumniClaim) error {
+	args := m.Called(claim)
+	return args.Error(0)
+}
+
+func (m *MockAlumniClaimRepository) FindByID(id primitive.ObjectID) (*model.AlumniClaim, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AlumniClaim), args.Error(1)
+}
+
+func (m *MockAlumniClaimRepository) FindLatestByUser(userID primitive.ObjectID) (*model.AlumniClaim, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AlumniClaim), args.Error(1)
+}
+
+func (m *MockAlumniClaimRepository) FindByStatus(status string) ([]model.AlumniClaim, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AlumniClaim), args.Error(1)
+}
+
+func (m *MockAlumniClaimRepository) Review(id primitive.ObjectID, status string, reviewer primitive.ObjectID, reason string) (bool, error) {
+	args := m.Called(id, status, reviewer, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAlumniClaimRepository) Reopen(id primitive.ObjectID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type alumniClaimMocks struct {
+	claims  *MockAlumniClaimRepository
+	alumni  *MockAlumniRepository
+	users   *MockUserRepository
+	uploads *MockUploadsRepository
+}
+
+func setupAlumniClaimApp(principal *model.Principal) (*fiber.App, *alumniClaimMocks) {
+	m := &alumniClaimMocks{
+		claims:  new(MockAlumniClaimRepository),
+		alumni:  new(MockAlumniRepository),
+		users:   new(MockUserRepository),
+		uploads: new(MockUploadsRepository),
+	}
+	svc := service.NewAlumniClaimService(m.claims, m.alumni, m.users, m.uploads)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, principal)
+		return c.Next()
+	})
+	app.Post("/me/alumni-claim", svc.CreateClaimHandler())
+	app.Get("/admin/alumni-claims", svc.ListClaimsHandler())
+	app.Post("/admin/alumni-claims/:id/approve", svc.ApproveClaimHandler())
+	app.Post("/admin/alumni-claims/:id/reject", svc.RejectClaimHandler())
+
+	return app, m
+}
+
+func TestCreateAlumniClaim(t *testing.T) {
+	user := &model.Users{ID: primitive.NewObjectID(), Email: "alumni@example.com", Role: "user"}
+	principal := &model.Principal{UserID: user.ID, Role: "user"}
+	tahun := 2020
+
+	setup := func() (*fiber.App, *alumniClaimMocks) {
+		app, m := setupAlumniClaimApp(principal)
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.alumni.On("FindByUserID", user.ID).Return(nil, nil)
+		return app, m
+	}
+
+	t.Run("Klaim baru menunggu peninjauan", func(t *testing.T) {
+		app, m := setup()
+		m.claims.On("FindLatestByUser", user.ID).Return(nil, nil)
+		m.alumni.On("CheckAlumniByNim", "2011001").Return(&model.Alumni{NIM: "2011001"}, nil)
+		m.claims.On("Create", mock.MatchedBy(func(c *model.AlumniClaim) bool {
+			return c.UserID == user.ID && c.NIM == "2011001" && c.Status == model.ClaimStatusPending && *c.TahunLulus == tahun
+		})).Return(nil)
+
+		status, _ := adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: " 2011001 ", TahunLulus: &tahun})
+		assert.Equal(t, fiber.StatusCreated, status)
+		m.claims.AssertExpectations(t)
+		m.alumni.AssertNotCalled(t, "LinkUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Dokumen harus unggahan sendiri", func(t *testing.T) {
+		app, m := setup()
+		own := &model.Uploads{ID: primitive.NewObjectID(), UploadedBy: user.ID}
+		other := &model.Uploads{ID: primitive.NewObjectID(), UploadedBy: primitive.NewObjectID()}
+		missing := primitive.NewObjectID()
+		m.uploads.On("FindByID", own.ID.Hex()).Return(own, nil)
+		m.uploads.On("FindByID", other.ID.Hex()).Return(other, nil)
+		m.uploads.On("FindByID", missing.Hex()).Return(nil, mongo.ErrNoDocuments)
+		m.claims.On("FindLatestByUser", user.ID).Return(nil, nil)
+		m.alumni.On("CheckAlumniByNim", "2011001").Return(&model.Alumni{NIM: "2011001"}, nil)
+		m.claims.On("Create", mock.AnythingOfType("*model.AlumniClaim")).Return(nil)
+
+		status, _ := adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: "2011001", DocumentID: other.ID.Hex()})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		status, _ = adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: "2011001", DocumentID: missing.Hex()})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		m.claims.AssertNotCalled(t, "Create", mock.Anything)
+
+		status, _ = adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: "2011001", DocumentID: own.ID.Hex()})
+		assert.Equal(t, fiber.StatusCreated, status)
+	})
+
+	t.Run("NIM milik akun lain ditolak", func(t *testing.T) {
+		app, m := setup()
+		m.claims.On("FindLatestByUser", user.ID).Return(nil, nil)
+		m.alumni.On("CheckAlumniByNim", "2011002").Return(&model.Alumni{NIM: "2011002", UserID: primitive.NewObjectID()}, nil)
+
+		status, _ := adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: "2011002"})
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.claims.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("NIM tidak ada", func(t *testing.T) {
+		app, m := setup()
+		m.claims.On("FindLatestByUser", user.ID).Return(nil, nil)
+		m.alumni.On("CheckAlumniByNim", "9999").Return(nil, mongo.ErrNoDocuments)
+
+		status, _ := adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: "9999"})
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("Klaim pending tidak bisa ditumpuk", func(t *testing.T) {
+		app, m := setup()
+		m.claims.On("FindLatestByUser", user.ID).Return(&model.AlumniClaim{Status: model.ClaimStatusPending}, nil)
+
+		status, _ := adminRequest(app, "POST", "/me/alumni-claim", model.CreateAlumniClaim{NIM: "2011001"})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		m.claims.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestReviewAlumniClaim(t *testing.T) {
+	pending := func() *model.AlumniClaim {
+		return &model.AlumniClaim{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), NIM: "2011001", Status: model.ClaimStatusPending}
+	}
+
+	t.Run("Persetujuan menautkan alumni ke user", func(t *testing.T) {
+		app, m := setupAlumniClaimApp(adminActor)
+		claim := pending()
+		m.claims.On("FindByID", claim.ID).Return(claim, nil)
+		m.alumni.On("FindByUserID", claim.UserID).Return(nil, nil)
+		m.claims.On("Review", claim.ID, model.ClaimStatusApproved, adminActor.UserID, "").Return(true, nil)
+		m.alumni.On("LinkUser", "2011001", claim.UserID).Return(true, nil)
+
+		status, _ := adminRequest(app, "POST", "/admin/alumni-claims/"+claim.ID.Hex()+"/approve", nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		m.alumni.AssertExpectations(t)
+		m.claims.AssertNotCalled(t, "Reopen", mock.Anything)
+	})
+
+	t.Run("NIM yang keburu tertaut membuka kembali klaim", func(t *testing.T) {
+		app, m := setupAlumniClaimApp(adminActor)
+		claim := pending()
+		m.claims.On("FindByID", claim.ID).Return(claim, nil)
+		m.alumni.On("FindByUserID", claim.UserID).Return(nil, nil)
+		m.claims.On("Review", claim.ID, model.ClaimStatusApproved, adminActor.UserID, "").Return(true, nil)
+		m.alumni.On("LinkUser", "2011001", claim.UserID).Return(false, nil)
+		m.claims.On("Reopen", claim.ID).Return(nil)
+
+		status, _ := adminRequest(app, "POST", "/admin/alumni-claims/"+claim.ID.Hex()+"/approve", nil)
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.claims.AssertCalled(t, "Reopen", claim.ID)
+	})
+
+	t.Run("Penolakan menyimpan alasan", func(t *testing.T) {
+		app, m := setupAlumniClaimApp(adminActor)
+		claim := pending()
+		m.claims.On("FindByID", claim.ID).Return(claim, nil)
+		m.claims.On("Review", claim.ID, model.ClaimStatusRejected, adminActor.UserID, "tahun lulus tidak cocok").Return(true, nil)
+
+		status, _ := adminRequest(app, "POST", "/admin/alumni-claims/"+claim.ID.Hex()+"/reject", model.ReviewAlumniClaim{Reason: "tahun lulus tidak cocok"})
+		assert.Equal(t, fiber.StatusOK, status)
+		m.alumni.AssertNotCalled(t, "LinkUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Klaim yang sudah ditinjau tidak bisa diubah", func(t *testing.T) {
+		app, m := setupAlumniClaimApp(adminActor)
+		claim := pending()
+		claim.Status = model.ClaimStatusRejected
+		m.claims.On("FindByID", claim.ID).Return(claim, nil)
+
+		status, _ := adminRequest(app, "POST", "/admin/alumni-claims/"+claim.ID.Hex()+"/approve", nil)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		m.claims.AssertNotCalled(t, "Review", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/xuri/excelize/v2"
+)
+
+func setupAlumniImportApp() (*fiber.App, *MockAlumniRepository, *MockMasterDataRepository) {
+	alumniRepo := new(MockAlumniRepository)
+	masterRepo := new(MockMasterDataRepository)
+	masterRepo.On("FindByID", model.MasterProdi, 12).Return(&model.MasterData{ID: 12, Nama: "Informatika", IDFakultas: intPtr(3)}, nil).Maybe()
+	masterRepo.On("FindByID", model.MasterProdi, 99).Return(nil, nil).Maybe()
+	masterRepo.On("FindByID", model.MasterFakultas, 3).Return(&model.MasterData{ID: 3, Nama: "Teknik"}, nil).Maybe()
+	masterRepo.On("FindByName", model.MasterSumber, "SNMPTN").Return(&model.MasterData{ID: 1, Nama: "SNMPTN"}, nil).Maybe()
+
+	app := fiber.New()
+	app.Post("/admin/alumni/import", service.NewAlumniImportService(alumniRepo, masterRepo).ImportAlumniHandler())
+	return app, alumniRepo, masterRepo
+}
+
+func postImportFile(app *fiber.App, query, filename string, content []byte) (int, model.AlumniImportResult) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/admin/alumni/import"+query, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, _ := app.Test(req)
+
+	var result model.AlumniImportResult
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+const importCSV = "NIM;Nama;Tahun Lulus;id_prodi;sumber;catatan\n" +
+	"1001;Budi;2020;12;SNMPTN;x\n" +
+	"1002;Siti;dua ribu;12;;\n" +
+	"1001;Budi Lagi;2021;12;;\n" +
+	";Tanpa NIM;2020;;;\n" +
+	"1003;Andi;2021;99;;\n" +
+	";;;;;\n" +
+	"1004;Citra;2019;;;\n"
+
+func TestImportAlumniDryRun(t *testing.T) {
+	app, alumniRepo, _ := setupAlumniImportApp()
+	alumniRepo.On("ExistingNIMs", []string{"1001", "1004"}).Return([]string{"1004"}, nil)
+
+	status, result := postImportFile(app, "?dry_run=true", "lulusan.csv", []byte("\xef\xbb\xbf"+importCSV))
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 6, result.TotalRows)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 4, result.Skipped)
+	assert.Equal(t, []string{"catatan"}, result.IgnoredColumns)
+
+	rows := map[int][]string{}
+	for _, e := range result.Errors {
+		rows[e.Row] = e.Errors
+	}
+	assert.Equal(t, []string{"tahun_lulus harus berupa angka"}, rows[3])
+	assert.Equal(t, []string{"NIM sudah muncul di baris 2"}, rows[4])
+	assert.Equal(t, []string{"nim wajib diisi"}, rows[5])
+	assert.Equal(t, []string{"prodi tidak ditemukan"}, rows[6])
+	alumniRepo.AssertNotCalled(t, "UpsertAlumni", mock.Anything, mock.Anything)
+}
+
+func TestImportAlumniApplyXLSX(t *testing.T) {
+	app, alumniRepo, _ := setupAlumniImportApp()
+	alumniRepo.On("UpsertAlumni", mock.MatchedBy(func(a *model.Alumni) bool { return a.NIM == "1001" }),
+		[]string{"nim", "nama", "id_prodi", "sumber", "id_fakultas", "id_sumber"}).
+		Return(true, nil).Once()
+	alumniRepo.On("UpsertAlumni", mock.MatchedBy(func(a *model.Alumni) bool { return a.NIM == "1002" }), mock.Anything).
+		Return(false, nil).Once()
+
+	book := excelize.NewFile()
+	sheet := book.GetSheetName(0)
+	book.SetSheetRow(sheet, "A1", &[]interface{}{"nim", "nama", "id_prodi", "sumber"})
+	book.SetSheetRow(sheet, "A2", &[]interface{}{"1001", "Budi", 12, "SNMPTN"})
+	book.SetSheetRow(sheet, "A3", &[]interface{}{"1002", "Siti", 12, ""})
+	book.SetSheetRow(sheet, "A4", &[]interface{}{"1003", "Andi", 99, ""})
+	var file bytes.Buffer
+	book.Write(&file)
+
+	status, result := postImportFile(app, "", "lulusan.xlsx", file.Bytes())
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Skipped)
+	alumniRepo.AssertExpectations(t)
+}
+
+func TestImportAlumniRejectsBadFile(t *testing.T) {
+	app, _, _ := setupAlumniImportApp()
+
+	status, _ := postImportFile(app, "", "lulusan.txt", []byte(importCSV))
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = postImportFile(app, "", "lulusan.csv", []byte("nama,angkatan\nBudi,2016\n"))
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestImportAlumniRejectsTooManyXLSXRows(t *testing.T) {
+	app, alumniRepo, _ := setupAlumniImportApp()
+
+	book := excelize.NewFile()
+	sheet := book.GetSheetName(0)
+	book.SetSheetRow(sheet, "A1", &[]interface{}{"nim", "nama"})
+	for i := 0; i <= 10000; i++ {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		book.SetSheetRow(sheet, cell, &[]interface{}{fmt.Sprintf("%d", 100000+i), "Alumni"})
+	}
+	var file bytes.Buffer
+	book.Write(&file)
+
+	status, _ := postImportFile(app, "", "lulusan.xlsx", file.Bytes())
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	alumniRepo.AssertNotCalled(t, "UpsertAlumni", mock.Anything, mock.Anything)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -19,14 +20,45 @@ type MockAlumniRepository struct {
 	mock.Mock
 }
 
-func (m *MockAlumniRepository) GetAllAlumni() ([]model.Alumni, error) {
-	args := m.Called()
+func (m *MockAlumniRepository) GetAllAlumni(filter model.AlumniFilter, sortBy, order string, limit, offset int) ([]model.Alumni, error) {
+	args := m.Called(filter, sortBy, order, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]model.Alumni), args.Error(1)
 }
 
+func (m *MockAlumniRepository) CountAlumni(filter model.AlumniFilter) (int, error) {
+	args := m.Called(filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAlumniRepository) OpenAlumniCursor(filter model.AlumniFilter, sortBy, order string) (model.Cursor, error) {
+	args := m.Called(filter, sortBy, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(model.Cursor), args.Error(1)
+}
+
+func (m *MockAlumniRepository) ExistingNIMs(nims []string) ([]string, error) {
+	args := m.Called(nims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAlumniRepository) UpsertAlumni(alumni *model.Alumni, columns []string) (bool, error) {
+	args := m.Called(alumni, columns)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAlumniRepository) EnsureIndexes() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func (m *MockAlumniRepository) CheckAlumniByNim(nim string) (*model.Alumni, error) {
 	args := m.Called(nim)
 	if args.Get(0) == nil {
@@ -35,6 +67,29 @@ func (m *MockAlumniRepository) CheckAlumniByNim(nim string) (*model.Alumni, erro
 	return args.Get(0).(*model.Alumni), args.Error(1)
 }
 
+func (m *MockAlumniRepository) FindByUserID(userID primitive.ObjectID) (*model.Alumni, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Alumni), args.Error(1)
+}
+
+func (m *MockAlumniRepository) LinkUser(nim string, userID primitive.ObjectID) (bool, error) {
+	args := m.Called(nim, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAlumniRepository) UnlinkUser(userID primitive.ObjectID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAlumniRepository) RenameSumber(idSumber int, nama string) error {
+	args := m.Called(idSumber, nama)
+	return args.Error(0)
+}
+
 func (m *MockAlumniRepository) CreateAlumni(alumni *model.Alumni) error {
 	args := m.Called(alumni)
 	return args.Error(0)
@@ -53,7 +108,7 @@ func (m *MockAlumniRepository) DeleteAlumni(nim string) error {
 func TestGetAllAlumniService(t *testing.T) {
 	// Setup
 	mockRepo := new(MockAlumniRepository)
-	svc := service.NewAlumniService(mockRepo)
+	svc := service.NewAlumniService(mockRepo, new(MockMasterDataRepository))
 	app := fiber.New()
 	app.Get("/alumni", svc.GetAllAlumniService)
 
@@ -63,7 +118,8 @@ func TestGetAllAlumniService(t *testing.T) {
 		{NIM: "456", Nama: "Siti"},
 	}
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("GetAllAlumni").Return(dummyAlumni, nil).Once()
+		mockRepo.On("GetAllAlumni", model.AlumniFilter{}, "nim", "asc", 10, 0).Return(dummyAlumni, nil).Once()
+		mockRepo.On("CountAlumni", model.AlumniFilter{}).Return(2, nil).Once()
 
 		req := httptest.NewRequest("GET", "/alumni", nil)
 		resp, _ := app.Test(req)
@@ -71,8 +127,61 @@ func TestGetAllAlumniService(t *testing.T) {
 		assert.Equal(t, 200, resp.StatusCode)
 	})
 
+	t.Run("Paginasi, Pencarian Dan Sort", func(t *testing.T) {
+		filter := model.AlumniFilter{Search: "budi"}
+		mockRepo.On("GetAllAlumni", filter, "tahun_lulus", "desc", 5, 10).Return(dummyAlumni[:1], nil).Once()
+		mockRepo.On("CountAlumni", filter).Return(11, nil).Once()
+
+		req := httptest.NewRequest("GET", "/alumni?page=3&limit=5&sortBy=tahun_lulus&order=DESC&search=budi", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		var result model.AlumniResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Len(t, result.Alumni, 1)
+		assert.Equal(t, model.MetaInfo{CurrentPage: 3, Limit: 5, Total: 11, Pages: 3, SortBy: "tahun_lulus", Order: "desc", Search: "budi"}, result.MetaInfo)
+	})
+
+	t.Run("Sort Di Luar Whitelist Dan Limit Dibatasi", func(t *testing.T) {
+		mockRepo.On("GetAllAlumni", model.AlumniFilter{}, "nim", "asc", 100, 0).Return(dummyAlumni, nil).Once()
+		mockRepo.On("CountAlumni", model.AlumniFilter{}).Return(2, nil).Once()
+
+		req := httptest.NewRequest("GET", "/alumni?limit=5000&sortBy=password&page=-1", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Filter Terstruktur", func(t *testing.T) {
+		from, to := 2019, 2021
+		filter := model.AlumniFilter{
+			TahunLulusFrom: &from,
+			TahunLulusTo:   &to,
+			IDProdi:        []int{12},
+			Sumber:         []string{"SNMPTN", "SBMPTN"},
+		}
+		mockRepo.On("GetAllAlumni", filter, "nim", "asc", 10, 0).Return(dummyAlumni, nil).Once()
+		mockRepo.On("CountAlumni", filter).Return(2, nil).Once()
+
+		req := httptest.NewRequest("GET", "/alumni?id_prodi=12&tahun_lulus_from=2019&tahun_lulus_to=2021&sumber=SNMPTN,%20SBMPTN", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Filter Tidak Valid", func(t *testing.T) {
+		for _, query := range []string{"id_prodi=12,abc", "angkatan_from=2020&angkatan_to=2018", "tahun_lulus_to=dua"} {
+			req := httptest.NewRequest("GET", "/alumni?"+query, nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, 400, resp.StatusCode, query)
+		}
+	})
+
 	t.Run("Internal Server Error", func(t *testing.T) {
-		mockRepo.On("GetAllAlumni").Return(nil, errors.New("db error")).Once()
+		mockRepo.On("GetAllAlumni", model.AlumniFilter{}, "nim", "asc", 10, 0).Return(nil, errors.New("db error")).Once()
 
 		req := httptest.NewRequest("GET", "/alumni", nil)
 		resp, _ := app.Test(req)
@@ -83,7 +192,7 @@ func TestGetAllAlumniService(t *testing.T) {
 
 func TestCheckAlumniService(t *testing.T) {
 	mockRepo := new(MockAlumniRepository)
-	svc := service.NewAlumniService(mockRepo)
+	svc := service.NewAlumniService(mockRepo, new(MockMasterDataRepository))
 	app := fiber.New()
 	app.Get("/alumni/:nim", svc.CheckAlumniService)
 
@@ -119,9 +228,14 @@ func TestCheckAlumniService(t *testing.T) {
 		assert.Equal(t, 500, resp.StatusCode)
 	})
 }
+// duplicateKeyError meniru error driver saat unique index dilanggar.
+func duplicateKeyError() error {
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key error"}}}
+}
+
 func TestCreateAlumniService(t *testing.T) {
 	mockRepo := new(MockAlumniRepository)
-	svc := service.NewAlumniService(mockRepo)
+	svc := service.NewAlumniService(mockRepo, new(MockMasterDataRepository))
 	app := fiber.New()
 	app.Post("/alumni", svc.CreateAlumniService)
 
@@ -138,6 +252,37 @@ func TestCreateAlumniService(t *testing.T) {
 		assert.Equal(t, 201, resp.StatusCode)
 	})
 
+	t.Run("Conflict - NIM Sudah Terdaftar", func(t *testing.T) {
+		input := model.Alumni{NIM: "123", Nama: "Duplikat"}
+		body, _ := json.Marshal(input)
+
+		mockRepo.On("CreateAlumni", mock.AnythingOfType("*model.Alumni")).Return(duplicateKeyError()).Once()
+
+		req := httptest.NewRequest("POST", "/alumni", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 409, resp.StatusCode)
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, "NIM sudah terdaftar", result["message"])
+		assert.NotContains(t, result["message"], "E11000")
+	})
+
+	t.Run("User ID Dari Body Diabaikan", func(t *testing.T) {
+		body := []byte(`{"nim":"124","nama":"Baru","user_id":"` + primitive.NewObjectID().Hex() + `"}`)
+
+		mockRepo.On("CreateAlumni", mock.MatchedBy(func(a *model.Alumni) bool {
+			return a.NIM == "124" && a.UserID.IsZero()
+		})).Return(nil).Once()
+
+		req := httptest.NewRequest("POST", "/alumni", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 201, resp.StatusCode)
+	})
+
 	t.Run("Bad Request - Empty NIM", func(t *testing.T) {
 		input := model.Alumni{NIM: "", Nama: "No Nim"}
 		body, _ := json.Marshal(input)
@@ -159,7 +304,7 @@ func TestCreateAlumniService(t *testing.T) {
 
 func TestUpdateAlumniService(t *testing.T) {
 	mockRepo := new(MockAlumniRepository)
-	svc := service.NewAlumniService(mockRepo)
+	svc := service.NewAlumniService(mockRepo, new(MockMasterDataRepository))
 	app := fiber.New()
 	app.Put("/alumni/:nim", svc.UpdateAlumniService)
 
@@ -177,6 +322,56 @@ func TestUpdateAlumniService(t *testing.T) {
 		assert.Equal(t, 200, resp.StatusCode)
 	})
 
+	t.Run("User ID Dari Body Diabaikan", func(t *testing.T) {
+		body := []byte(`{"nim":"123","nama":"Updated Name","user_id":"` + primitive.NewObjectID().Hex() + `"}`)
+
+		mockRepo.On("UpdateAlumni", "123", mock.MatchedBy(func(a *model.Alumni) bool {
+			return a.UserID.IsZero()
+		})).Return(nil).Once()
+
+		req := httptest.NewRequest("PUT", "/alumni/123", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+
+	t.Run("NIM Kosong Memakai NIM Di Path", func(t *testing.T) {
+		body, _ := json.Marshal(model.SaveAlumni{Nama: "Tanpa NIM"})
+
+		mockRepo.On("UpdateAlumni", "123", mock.MatchedBy(func(a *model.Alumni) bool {
+			return a.NIM == "123"
+		})).Return(nil).Once()
+
+		req := httptest.NewRequest("PUT", "/alumni/123", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+
+	t.Run("Bad Request - Ganti NIM", func(t *testing.T) {
+		body, _ := json.Marshal(model.SaveAlumni{NIM: "999", Nama: "Ganti NIM"})
+
+		req := httptest.NewRequest("PUT", "/alumni/123", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		body, _ := json.Marshal(model.SaveAlumni{Nama: "Hilang"})
+
+		mockRepo.On("UpdateAlumni", "404", mock.AnythingOfType("*model.Alumni")).Return(mongo.ErrNoDocuments).Once()
+
+		req := httptest.NewRequest("PUT", "/alumni/404", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+
 	t.Run("Failed Update - DB Error", func(t *testing.T) {
 		nim := "123"
 		input := model.Alumni{NIM: "123", Nama: "Updated Name"}
@@ -194,7 +389,7 @@ func TestUpdateAlumniService(t *testing.T) {
 
 func TestDeleteAlumniService(t *testing.T) {
 	mockRepo := new(MockAlumniRepository)
-	svc := service.NewAlumniService(mockRepo)
+	svc := service.NewAlumniService(mockRepo, new(MockMasterDataRepository))
 	app := fiber.New()
 	app.Delete("/alumni/:nim", svc.DeleteAlumniService)
 
@@ -2,11 +2,17 @@ package test
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"time"
 	"net/http/httptest"
 	"testing"
 
+	"Mongo/domain/config"
+	"Mongo/domain/mailer"
+	"Mongo/domain/middleware"
 	"Mongo/domain/model"
 	"Mongo/domain/service"
 
@@ -39,6 +45,42 @@ func (m *MockUserRepository) Count(role string) (int, error) {
 	return args.Get(0).(int), args.Error(1)
 }
 
+func (m *MockUserRepository) CountByRole(role string) (int, error) {
+	args := m.Called(role)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) CountActiveByRole(role string) (int, error) {
+	args := m.Called(role)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) LockAdminChanges() (func(), error) {
+	args := m.Called()
+	unlock, _ := args.Get(0).(func())
+	return unlock, args.Error(1)
+}
+
+func (m *MockUserRepository) EnsureIndexes() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePasswordHash(id primitive.ObjectID, oldHash, newHash string) error {
+	args := m.Called(id, oldHash, newHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateStatus(id primitive.ObjectID, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRole(id primitive.ObjectID, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(id primitive.ObjectID) error {
     args := m.Called(id)
     return args.Error(0)
@@ -65,18 +107,160 @@ func (m *MockUserRepository) Update(user *model.Users) error {
 	return args.Error(0)
 }
 
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(token *model.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByHash(hash string) (*model.RefreshToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Rotate(id primitive.ObjectID, replacedBy string) (bool, error) {
+	args := m.Called(id, replacedBy)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(familyID string) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllForUser(userID primitive.ObjectID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(msg mailer.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepository) Reserve(key string, window time.Duration) (*model.LoginAttempt, error) {
+	args := m.Called(key, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) Release(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) RecordFailure(key string) (*model.LoginAttempt, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) Lock(key string, until time.Time) error {
+	args := m.Called(key, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) Reset(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+var testLoginThrottle = config.LoginThrottle{
+	MaxAccountFailures: 3,
+	MaxIPFailures:      10,
+	Window:             15 * time.Minute,
+	Lockout:            15 * time.Minute,
+	BaseDelay:          time.Second,
+	MaxDelay:           8 * time.Second,
+}
+
+var (
+	mockLoginAttempts  *MockLoginAttemptRepository
+	mockRefreshRepo    *MockRefreshTokenRepository
+	mockAuthAlumniRepo *MockAlumniRepository
+	mockAuthTokenRepo  *MockOneTimeTokenRepository
+	mockAuthMailer     *MockMailer
+	mockTwoFactorRepo  *MockTwoFactorRepository
+	mockSessionRepo    *MockSessionRepository
+)
+
+var testTwoFactorConfig = config.TwoFactorConfig{Issuer: "Test", ChallengeExpiry: 5 * time.Minute}
+
 func setupTestApp() (*fiber.App, *MockUserRepository) {
+	return setupTestAppWithTwoFactor(testTwoFactorConfig)
+}
+
+func setupTestAppWithTwoFactor(twoFactorCfg config.TwoFactorConfig) (*fiber.App, *MockUserRepository) {
+	return setupAuthApp(twoFactorCfg, nil)
+}
+
+func setupAuthApp(twoFactorCfg config.TwoFactorConfig, oidc *service.OIDCClient) (*fiber.App, *MockUserRepository) {
 	mockRepo := new(MockUserRepository)
-	
-	authService := service.NewAuthService(mockRepo)
+	mockRefreshRepo = new(MockRefreshTokenRepository)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*model.RefreshToken")).Return(nil).Maybe()
+	mockAuthAlumniRepo = new(MockAlumniRepository)
+	mockAuthAlumniRepo.On("FindByUserID", mock.Anything).Return(nil, nil).Maybe()
+
+	mockAuthTokenRepo = new(MockOneTimeTokenRepository)
+	mockAuthTokenRepo.On("InvalidateForUser", mock.Anything, model.TokenPurposeEmailVerification).Return(nil).Maybe()
+	mockAuthTokenRepo.On("Create", mock.AnythingOfType("*model.OneTimeToken")).Return(nil).Maybe()
+	mockAuthMailer = new(MockMailer)
+	mockAuthMailer.On("Send", mock.Anything).Return(nil).Maybe()
+	verifier := service.NewVerificationService(mockRepo, mockAuthTokenRepo, mockAuthMailer)
+
+	mockLoginAttempts = new(MockLoginAttemptRepository)
+	mockLoginAttempts.On("Reserve", mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil).Maybe()
+	mockLoginAttempts.On("Release", mock.Anything).Return(nil).Maybe()
+	mockLoginAttempts.On("RecordFailure", mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil).Maybe()
+	mockLoginAttempts.On("Reset", mock.Anything).Return(nil).Maybe()
+	guard := service.NewLoginGuard(mockLoginAttempts, testLoginThrottle)
+
+	mockTwoFactorRepo = new(MockTwoFactorRepository)
+	mockTwoFactorRepo.On("FindByUserID", mock.Anything).Return(nil, nil).Maybe()
+	twoFactor := service.NewTwoFactorGuard(mockTwoFactorRepo, mockAuthTokenRepo, twoFactorCfg)
+
+	mockSessionRepo = newLenientSessionRepo()
+	sessions := service.NewSessionManager(mockSessionRepo, mockRefreshRepo)
+
+	authService := service.NewAuthService(mockRepo, mockRefreshRepo, mockAuthAlumniRepo, verifier, guard, twoFactor, sessions, oidc)
 
 	app := fiber.New()
 	app.Post("/register", authService.RegisterHandler())
 	app.Post("/login", authService.LoginHandler())
+	app.Post("/login/2fa", authService.LoginTwoFactorHandler())
+	app.Post("/login/2fa/setup", authService.LoginTwoFactorSetupHandler())
+	app.Post("/token/refresh", authService.RefreshHandler())
+	app.Post("/logout", authService.LogoutHandler())
+	app.Post("/admin/login-attempts/unlock", authService.UnlockLoginHandler())
+	app.Get("/auth/oidc/login", authService.OIDCLoginHandler())
+	app.Get("/auth/oidc/callback", authService.OIDCCallbackHandler())
 
 	return app, mockRepo
 }
 
+func hashForTest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func TestRegisterHandler(t *testing.T) {
 	t.Run("Success Register", func(t *testing.T) {
 		app, mockRepo := setupTestApp()
@@ -84,7 +268,7 @@ func TestRegisterHandler(t *testing.T) {
 		payload := model.Register{
 			Email:    "new@example.com",
 			Username: "newuser",
-			Password: "password123",
+			Password: "kebun-mangga-2024",
 			Role:     "user",
 		}
 		body, _ := json.Marshal(payload)
@@ -100,6 +284,17 @@ func TestRegisterHandler(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
 		mockRepo.AssertExpectations(t)
+
+		// Akun baru belum aktif: tidak ada token, email verifikasi dikirim
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.NotContains(t, result, "token")
+		assert.NotContains(t, result, "refresh_token")
+		created := mockRepo.Calls[1].Arguments.Get(0).(*model.Users)
+		assert.Equal(t, model.UserStatusUnverified, created.Status)
+		mockAuthMailer.AssertCalled(t, "Send", mock.MatchedBy(func(msg mailer.Message) bool {
+			return msg.To == payload.Email
+		}))
 	})
 
 	t.Run("Fail - Email Already Exists", func(t *testing.T) {
@@ -107,7 +302,7 @@ func TestRegisterHandler(t *testing.T) {
 
 		payload := model.Register{
 			Email:    "exist@example.com",
-			Password: "password123",
+			Password: "kebun-mangga-2024",
 			Role:     "user",
 		}
 		body, _ := json.Marshal(payload)
@@ -120,8 +315,78 @@ func TestRegisterHandler(t *testing.T) {
 
 		resp, err := app.Test(req)
 
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("Email Dinormalisasi Sebelum Dicek Dan Disimpan", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		payload := model.Register{
+			Email:    "  Alice@Example.COM ",
+			Password: "kebun-mangga-2024",
+			Role:     "user",
+		}
+		body, _ := json.Marshal(payload)
+
+		mockRepo.On("FindByEmail", "alice@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(u *model.Users) bool {
+			return u.Email == "alice@example.com"
+		})).Return(nil).Once()
+
+		req := httptest.NewRequest("POST", "/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Concurrent Register Hits Unique Index", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		payload := model.Register{
+			Email:    "race@example.com",
+			Password: "kebun-mangga-2024",
+			Role:     "user",
+		}
+		body, _ := json.Marshal(payload)
+
+		mockRepo.On("FindByEmail", payload.Email).Return(nil, nil).Once()
+		mockRepo.On("Create", mock.AnythingOfType("*model.Users")).Return(duplicateKeyError()).Once()
+
+		req := httptest.NewRequest("POST", "/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, "email sudah terdaftar", result["error"])
+	})
+
+	t.Run("Fail - Self-assigned Admin Role", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		payload := model.Register{
+			Email:    "sneaky@example.com",
+			Password: "kebun-mangga-2024",
+			Role:     "admin",
+		}
+		body, _ := json.Marshal(payload)
+
+		req := httptest.NewRequest("POST", "/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
 		assert.NoError(t, err)
 		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
 	})
 
 	t.Run("Fail - Invalid Role", func(t *testing.T) {
@@ -129,7 +394,7 @@ func TestRegisterHandler(t *testing.T) {
 
 		payload := model.Register{
 			Email:    "test@example.com",
-			Password: "password123",
+			Password: "kebun-mangga-2024",
 			Role:     "superadmin",
 		}
 		body, _ := json.Marshal(payload)
@@ -176,6 +441,13 @@ func TestLoginHandler(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		user, _ := result["user"].(map[string]interface{})
+		assert.Equal(t, "user@example.com", user["email"])
+		assert.NotContains(t, user, "password")
+		assert.NotEmpty(t, mockUserDB.Password)
 	})
 
 	t.Run("Fail - Wrong Password", func(t *testing.T) {
@@ -218,6 +490,29 @@ func TestLoginHandler(t *testing.T) {
 		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
 	})
 
+	t.Run("Fail - Email Not Verified", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		unverified := &model.Users{
+			ID:       primitive.NewObjectID(),
+			Email:    "pending@example.com",
+			Password: hashedPassword,
+			Role:     "user",
+			Status:   model.UserStatusUnverified,
+		}
+		mockRepo.On("FindByEmail", unverified.Email).Return(unverified, nil).Once()
+
+		body, _ := json.Marshal(model.Login{Email: unverified.Email, Password: rawPassword})
+		req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		mockRefreshRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
 	t.Run("Fail - Database Error", func(t *testing.T) {
 		app, mockRepo := setupTestApp()
 
@@ -237,4 +532,168 @@ func TestLoginHandler(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 	})
-}
\ No newline at end of file
+}
+
+func TestRefreshHandler(t *testing.T) {
+	user := &model.Users{
+		ID:       primitive.NewObjectID(),
+		Email:    "user@example.com",
+		Role:     "user",
+		Username: "testuser",
+	}
+
+	t.Run("Success Rotate", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		stored := &model.RefreshToken{
+			ID:        primitive.NewObjectID(),
+			UserID:    user.ID,
+			FamilyID:  "family-1",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		mockRefreshRepo.On("FindByHash", hashForTest("old-token")).Return(stored, nil).Once()
+		mockRefreshRepo.On("Rotate", stored.ID, mock.AnythingOfType("string")).Return(true, nil).Once()
+		mockRepo.On("FindByID", user.ID).Return(user, nil).Once()
+
+		body, _ := json.Marshal(model.RefreshRequest{RefreshToken: "old-token"})
+		req := httptest.NewRequest("POST", "/token/refresh", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.NotEmpty(t, result["token"])
+		assert.NotEqual(t, "old-token", result["refresh_token"])
+		mockRefreshRepo.AssertCalled(t, "Create", mock.MatchedBy(func(rt *model.RefreshToken) bool {
+			return rt.FamilyID == "family-1"
+		}))
+	})
+
+	t.Run("Fail - Reuse Revokes Family", func(t *testing.T) {
+		app, _ := setupTestApp()
+
+		revokedAt := time.Now().Add(-time.Minute)
+		stored := &model.RefreshToken{
+			ID:        primitive.NewObjectID(),
+			UserID:    user.ID,
+			FamilyID:  "family-2",
+			ExpiresAt: time.Now().Add(time.Hour),
+			RevokedAt: &revokedAt,
+		}
+		mockRefreshRepo.On("FindByHash", hashForTest("reused-token")).Return(stored, nil).Once()
+		mockRefreshRepo.On("RevokeFamily", "family-2").Return(nil).Once()
+
+		body, _ := json.Marshal(model.RefreshRequest{RefreshToken: "reused-token"})
+		req := httptest.NewRequest("POST", "/token/refresh", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		mockRefreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Unknown Token", func(t *testing.T) {
+		app, _ := setupTestApp()
+
+		mockRefreshRepo.On("FindByHash", hashForTest("ghost")).Return(nil, nil).Once()
+
+		body, _ := json.Marshal(model.RefreshRequest{RefreshToken: "ghost"})
+		req := httptest.NewRequest("POST", "/token/refresh", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestLogoutHandler(t *testing.T) {
+	app, _ := setupTestApp()
+
+	stored := &model.RefreshToken{ID: primitive.NewObjectID(), FamilyID: "family-3"}
+	mockRefreshRepo.On("FindByHash", hashForTest("logout-token")).Return(stored, nil).Once()
+	mockRefreshRepo.On("RevokeFamily", "family-3").Return(nil).Once()
+
+	body, _ := json.Marshal(model.RefreshRequest{RefreshToken: "logout-token"})
+	req := httptest.NewRequest("POST", "/logout", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockRefreshRepo.AssertExpectations(t)
+}
+
+func TestJWTAuthPrincipal(t *testing.T) {
+	app, mockRepo := setupTestApp()
+
+	rawPassword := "secret123"
+	hashedBytes, _ := bcrypt.GenerateFromPassword([]byte(rawPassword), bcrypt.MinCost)
+	user := &model.Users{
+		ID:       primitive.NewObjectID(),
+		Email:    "alumni@example.com",
+		Password: string(hashedBytes),
+		Role:     "user",
+		Username: "alumni",
+	}
+	mockRepo.On("FindByEmail", user.Email).Return(user, nil).Once()
+	mockRepo.On("FindByID", user.ID).Return(user, nil)
+
+	// Override default: user ini terhubung ke NIM 2019001
+	mockAuthAlumniRepo.ExpectedCalls = nil
+	mockAuthAlumniRepo.On("FindByUserID", user.ID).Return(&model.Alumni{NIM: "2019001", UserID: user.ID}, nil)
+
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("FindByName", "user").Return(&model.Role{Name: "user", Permissions: []string{model.PermAlumniRead}}, nil)
+
+	auth := &middleware.AuthDeps{Users: mockRepo, Roles: roleRepo}
+	app.Get("/whoami", middleware.JWTAuth(auth), func(c *fiber.Ctx) error {
+		p, ok := middleware.GetPrincipal(c)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		return c.JSON(p)
+	})
+
+	body, _ := json.Marshal(model.Login{Email: user.Email, Password: rawPassword})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var login map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&login)
+	token, _ := login["token"].(string)
+	assert.NotEmpty(t, token)
+
+	req = httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var principal model.Principal
+	json.NewDecoder(resp.Body).Decode(&principal)
+	assert.Equal(t, user.ID, principal.UserID)
+	assert.Equal(t, "user", principal.Role)
+	assert.Equal(t, "alumni", principal.Username)
+	assert.Equal(t, "2019001", principal.NIM)
+	assert.Equal(t, []string{model.PermAlumniRead}, principal.Permissions)
+
+	// Token lama tidak berlaku lagi jika akun kembali belum terverifikasi
+	user.Status = model.UserStatusUnverified
+	req = httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// cursorOf membuat cursor Mongo dari dokumen di memori.
+func cursorOf(t *testing.T, docs ...interface{}) model.Cursor {
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	assert.NoError(t, err)
+	return cursor
+}
+
+func setupAlumniExportApp(principal *model.Principal) (*fiber.App, *MockAlumniRepository) {
+	alumniRepo := new(MockAlumniRepository)
+	masterRepo := new(MockMasterDataRepository)
+	masterRepo.On("FindAll", model.MasterFakultas, (*int)(nil)).Return([]model.MasterData{{ID: 3, Nama: "Teknik"}}, nil)
+	masterRepo.On("FindAll", model.MasterProdi, (*int)(nil)).Return([]model.MasterData{{ID: 12, Nama: "Informatika"}}, nil)
+	masterRepo.On("FindAll", model.MasterSumber, (*int)(nil)).Return([]model.MasterData{{ID: 1, Nama: "SNMPTN"}}, nil)
+
+	svc := service.NewAlumniService(alumniRepo, masterRepo)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, principal)
+		return c.Next()
+	})
+	app.Get("/alumni/export", svc.ExportAlumniService)
+	return app, alumniRepo
+}
+
+func exportAlumni() []interface{} {
+	sumber := "SNMPTN"
+	return []interface{}{
+		model.Alumni{NIM: "1001", Nama: "Budi", TahunLulus: intPtr(2020), IDFakultas: intPtr(3), IDProdi: intPtr(12), IDSumber: intPtr(1), Sumber: &sumber},
+		model.Alumni{NIM: "1002", Nama: "Siti", IDProdi: intPtr(77)},
+	}
+}
+
+func getExport(app *fiber.App, path string) (int, string, []byte) {
+	resp, _ := app.Test(httptest.NewRequest("GET", path, nil))
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), body
+}
+
+func TestExportAlumniCSV(t *testing.T) {
+	app, alumniRepo := setupAlumniExportApp(adminActor)
+	from := 2019
+	filter := model.AlumniFilter{TahunLulusFrom: &from, IDProdi: []int{12, 77}}
+	alumniRepo.On("OpenAlumniCursor", filter, "nama", "desc").Return(cursorOf(t, exportAlumni()...), nil)
+
+	status, contentType, body := getExport(app, "/alumni/export?id_prodi=12,77&tahun_lulus_from=2019&sortBy=nama&order=desc")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Contains(t, contentType, "text/csv")
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"NIM", "Nama", "Angkatan", "Tahun Lulus", "Fakultas", "Prodi", "Sumber"},
+		{"1001", "Budi", "", "2020", "Teknik", "Informatika", "SNMPTN"},
+		{"1002", "Siti", "", "", "", "77", ""},
+	}, records)
+}
+
+func TestExportCSVNeutralisesFormulas(t *testing.T) {
+	app, alumniRepo := setupAlumniExportApp(adminActor)
+	alumniRepo.On("OpenAlumniCursor", model.AlumniFilter{}, "nim", "asc").Return(cursorOf(t,
+		model.Alumni{NIM: "1001", Nama: "=HYPERLINK(\"http://contoh.test\",\"klik\")", TahunLulus: intPtr(2020)},
+		model.Alumni{NIM: "+1002", Nama: "@SUM(A1:A2)"},
+		model.Alumni{NIM: "1003", Nama: "-2+3"},
+		model.Alumni{NIM: "1004", Nama: "\t=1+1"},
+	), nil)
+
+	status, _, body := getExport(app, "/alumni/export")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 5) {
+		assert.Equal(t, []string{"1001", "'=HYPERLINK(\"http://contoh.test\",\"klik\")"}, records[1][:2])
+		// Angka tidak ikut diberi petik
+		assert.Equal(t, "2020", records[1][3])
+		assert.Equal(t, []string{"'+1002", "'@SUM(A1:A2)"}, records[2][:2])
+		assert.Equal(t, "'-2+3", records[3][1])
+		assert.Equal(t, "'\t=1+1", records[4][1])
+	}
+}
+
+func TestExportAlumniXLSXAndPDF(t *testing.T) {
+	reader := &model.Principal{Role: "user", Permissions: []string{model.PermAlumniRead}}
+
+	t.Run("XLSX Tanpa Kolom Sumber", func(t *testing.T) {
+		app, alumniRepo := setupAlumniExportApp(reader)
+		alumniRepo.On("OpenAlumniCursor", model.AlumniFilter{}, "nim", "asc").Return(cursorOf(t, exportAlumni()...), nil)
+
+		status, _, body := getExport(app, "/alumni/export?format=xlsx")
+
+		assert.Equal(t, fiber.StatusOK, status)
+		book, err := excelize.OpenReader(bytes.NewReader(body))
+		if assert.NoError(t, err) {
+			rows, _ := book.GetRows(book.GetSheetName(0))
+			assert.Len(t, rows, 3)
+			assert.Equal(t, []string{"NIM", "Nama", "Angkatan", "Tahun Lulus", "Fakultas", "Prodi"}, rows[0])
+			assert.Equal(t, "Informatika", rows[1][5])
+		}
+	})
+
+	t.Run("PDF", func(t *testing.T) {
+		app, alumniRepo := setupAlumniExportApp(reader)
+		alumniRepo.On("OpenAlumniCursor", model.AlumniFilter{}, "nim", "asc").Return(cursorOf(t, exportAlumni()...), nil)
+
+		status, contentType, body := getExport(app, "/alumni/export?format=pdf")
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "application/pdf", contentType)
+		assert.True(t, bytes.HasPrefix(body, []byte("%PDF")))
+	})
+
+	t.Run("Format Atau Filter Tidak Valid", func(t *testing.T) {
+		app, alumniRepo := setupAlumniExportApp(reader)
+
+		status, _, _ := getExport(app, "/alumni/export?format=docx")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		status, _, _ = getExport(app, "/alumni/export?angkatan=abc")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		alumniRepo.AssertNotCalled(t, "OpenAlumniCursor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestExportPekerjaanCSV(t *testing.T) {
+	app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+	pekerjaanRepo.On("OpenCursor").Return(cursorOf(t,
+		model.PekerjaanAlumni{NimAlumni: "1001", StatusKerja: "bekerja", Jabatan: "Engineer", Gaji: 9000000, LamaBekerja: 12},
+		model.PekerjaanAlumni{NimAlumni: "2002", StatusKerja: "bekerja", Jabatan: "Analis", Gaji: 7000000, LamaBekerja: 6},
+	), nil)
+
+	status, _, body := getExport(app, "/api/pekerjaan/export")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 3) {
+		// Gaji alumni lain tidak ikut terexport
+		assert.Equal(t, "9000000", records[1][5])
+		assert.Equal(t, "", records[2][5])
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupImpersonationApp() (*fiber.App, *userAdminMocks, *MockAlumniRepository) {
+	m := &userAdminMocks{
+		users: new(MockUserRepository),
+		roles: new(MockRoleRepository),
+		audit: new(MockAuditRepository),
+	}
+	alumniRepo := new(MockAlumniRepository)
+	alumniRepo.On("FindByUserID", mock.Anything).Return(nil, nil).Maybe()
+	m.audit.On("Create", mock.AnythingOfType("*model.AuditLog")).Return(nil).Maybe()
+	m.roles.On("FindByName", "user").Return(&model.Role{Name: "user", Permissions: []string{model.PermAlumniRead}}, nil).Maybe()
+	m.roles.On("FindByName", "admin").Return(&model.Role{Name: "admin", Permissions: []string{model.PermAll}}, nil).Maybe()
+
+	svc := service.NewImpersonationService(m.users, m.roles, alumniRepo, m.audit)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, adminActor)
+		return c.Next()
+	})
+	app.Post("/admin/users/:id/impersonate", svc.StartImpersonationHandler())
+
+	return app, m, alumniRepo
+}
+
+func TestStartImpersonationHandler(t *testing.T) {
+	t.Run("Sukses Dengan Klaim Act", func(t *testing.T) {
+		app, m, _ := setupImpersonationApp()
+		target := &model.Users{ID: primitive.NewObjectID(), Username: "alumni", Role: "user", Status: model.UserStatusActive}
+		m.users.On("FindByID", target.ID).Return(target, nil)
+
+		status, result := adminRequest(app, "POST", "/admin/users/"+target.ID.Hex()+"/impersonate", model.StartImpersonation{Reason: "tiket #42"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, true, result["read_only"])
+
+		claims := new(model.Claims)
+		keys := config.GetKeySet()
+		_, err := jwt.ParseWithClaims(result["token"].(string), claims, keys.Keyfunc, jwt.WithValidMethods(keys.ValidMethods()))
+		assert.NoError(t, err)
+		assert.Equal(t, target.ID.Hex(), claims.Subject)
+		assert.Empty(t, claims.SessionID)
+		if assert.NotNil(t, claims.Actor) {
+			assert.Equal(t, adminActor.UserID.Hex(), claims.Actor.Subject)
+		}
+		assert.True(t, auditedAction(m.audit, model.AuditImpersonationStarted))
+	})
+
+	t.Run("Tidak Boleh Akun Sendiri", func(t *testing.T) {
+		app, m, _ := setupImpersonationApp()
+
+		status, _ := adminRequest(app, "POST", "/admin/users/"+adminActor.UserID.Hex()+"/impersonate", nil)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		m.audit.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Tidak Boleh Sesama Admin", func(t *testing.T) {
+		app, m, _ := setupImpersonationApp()
+		target := &model.Users{ID: primitive.NewObjectID(), Role: "admin", Status: model.UserStatusActive}
+		m.users.On("FindByID", target.ID).Return(target, nil)
+
+		status, _ := adminRequest(app, "POST", "/admin/users/"+target.ID.Hex()+"/impersonate", nil)
+
+		assert.Equal(t, fiber.StatusForbidden, status)
+		m.audit.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestJWTAuthWithImpersonationToken(t *testing.T) {
+	users := new(MockUserRepository)
+	roles := new(MockRoleRepository)
+	audit := new(MockAuditRepository)
+	audit.On("Create", mock.AnythingOfType("*model.AuditLog")).Return(nil)
+	roles.On("FindByName", "user").Return(&model.Role{Name: "user", Permissions: []string{model.PermAlumniRead, model.PermPekerjaanDelete}}, nil)
+	roles.On("FindByName", "admin").Return(&model.Role{Name: "admin", Permissions: []string{model.PermAll}}, nil)
+
+	target := &model.Users{ID: primitive.NewObjectID(), Username: "alumni", Role: "user", Status: model.UserStatusActive}
+	admin := &model.Users{ID: primitive.NewObjectID(), Username: "admin", Role: "admin", Status: model.UserStatusActive}
+	demoted := &model.Users{ID: primitive.NewObjectID(), Username: "mantan-admin", Role: "user", Status: model.UserStatusActive}
+	users.On("FindByID", target.ID).Return(target, nil)
+	users.On("FindByID", admin.ID).Return(admin, nil)
+	users.On("FindByID", demoted.ID).Return(demoted, nil)
+
+	// Sesi tidak dicek untuk token impersonasi karena tidak memiliki sid
+	deps := &middleware.AuthDeps{Users: users, Roles: roles, Sessions: newLenientSessionRepo(), Audit: audit}
+	app := fiber.New()
+	app.Get("/pekerjaan", middleware.JWTAuth(deps), func(c *fiber.Ctx) error {
+		principal, _ := middleware.GetPrincipal(c)
+		assert.True(t, principal.IsImpersonated())
+		assert.Equal(t, target.ID, principal.UserID)
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Delete("/pekerjaan/:id", middleware.JWTAuth(deps), middleware.RequirePermission(model.PermPekerjaanDelete), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	call := func(method, path string, actor *model.Users) int {
+		claims := testClaims(target.ID)
+		claims.Actor = &model.ActorClaim{Subject: actor.ID.Hex(), Username: actor.Username}
+		token, err := config.GetKeySet().Sign(claims)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, call("GET", "/pekerjaan", admin))
+	assert.Equal(t, fiber.StatusForbidden, call("DELETE", "/pekerjaan/1", admin))
+	assert.Equal(t, fiber.StatusUnauthorized, call("GET", "/pekerjaan", demoted))
+
+	var allowed, refused int
+	for _, c := range audit.Calls {
+		entry := c.Arguments.Get(0).(*model.AuditLog)
+		assert.Equal(t, model.AuditImpersonatedRequest, entry.Action)
+		assert.Equal(t, admin.ID, entry.ActorID)
+		assert.Equal(t, target.ID.Hex(), entry.TargetID)
+		if entry.Details["allowed"] == true {
+			allowed++
+		} else {
+			refused++
+		}
+	}
+	assert.Equal(t, 1, allowed)
+	assert.Equal(t, 1, refused)
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockInviteRepository struct {
+	mock.Mock
+}
+
+func (m *MockInviteRepository) Create(invite *model.Invite) error {
+	args := m.Called(invite)
+	return args.Error(0)
+}
+
+func (m *MockInviteRepository) FindByHash(hash string) (*model.Invite, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Invite), args.Error(1)
+}
+
+func (m *MockInviteRepository) FindAll() ([]model.Invite, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Invite), args.Error(1)
+}
+
+func (m *MockInviteRepository) MarkUsed(id, userID primitive.ObjectID) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInviteRepository) Release(id primitive.ObjectID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func setupInviteApp() (*fiber.App, *MockInviteRepository, *MockUserRepository) {
+	inviteRepo := new(MockInviteRepository)
+	userRepo := new(MockUserRepository)
+	svc := service.NewInviteService(inviteRepo, userRepo)
+
+	app := fiber.New()
+	app.Post("/admin/invites", func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, &model.Principal{UserID: primitive.NewObjectID(), Role: "admin"})
+		return c.Next()
+	}, svc.CreateInviteHandler())
+	app.Post("/register/invite", svc.RedeemInviteHandler())
+
+	return app, inviteRepo, userRepo
+}
+
+func TestCreateInviteHandler(t *testing.T) {
+	app, inviteRepo, _ := setupInviteApp()
+
+	inviteRepo.On("Create", mock.MatchedBy(func(inv *model.Invite) bool {
+		return inv.Role == "admin" && inv.TokenHash != "" && inv.ExpiresAt.After(time.Now())
+	})).Return(nil).Once()
+
+	req := httptest.NewRequest("POST", "/admin/invites", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.NotEmpty(t, result["token"])
+	inviteRepo.AssertExpectations(t)
+}
+
+func TestRedeemInviteHandler(t *testing.T) {
+	t.Run("Success - Creates Admin", func(t *testing.T) {
+		app, inviteRepo, userRepo := setupInviteApp()
+
+		invite := &model.Invite{ID: primitive.NewObjectID(), Role: "admin", ExpiresAt: time.Now().Add(time.Hour)}
+		inviteRepo.On("FindByHash", hashForTest("good-invite")).Return(invite, nil).Once()
+		inviteRepo.On("MarkUsed", invite.ID, mock.AnythingOfType("primitive.ObjectID")).Return(true, nil).Once()
+		userRepo.On("FindByEmail", "boss@example.com").Return(nil, nil).Once()
+		userRepo.On("Create", mock.MatchedBy(func(u *model.Users) bool {
+			return u.Role == "admin"
+		})).Return(nil).Once()
+
+		body, _ := json.Marshal(model.RedeemInvite{Token: "good-invite", Email: "boss@example.com", Username: "boss", Password: "kebun-mangga-2024"})
+		req := httptest.NewRequest("POST", "/register/invite", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		inviteRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Expired Invite", func(t *testing.T) {
+		app, inviteRepo, userRepo := setupInviteApp()
+
+		invite := &model.Invite{ID: primitive.NewObjectID(), Role: "admin", ExpiresAt: time.Now().Add(-time.Hour)}
+		inviteRepo.On("FindByHash", hashForTest("old-invite")).Return(invite, nil).Once()
+
+		body, _ := json.Marshal(model.RedeemInvite{Token: "old-invite", Email: "late@example.com", Password: "kebun-mangga-2024"})
+		req := httptest.NewRequest("POST", "/register/invite", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Fail - Already Used", func(t *testing.T) {
+		app, inviteRepo, userRepo := setupInviteApp()
+
+		invite := &model.Invite{ID: primitive.NewObjectID(), Role: "admin", ExpiresAt: time.Now().Add(time.Hour)}
+		inviteRepo.On("FindByHash", hashForTest("race-invite")).Return(invite, nil).Once()
+		inviteRepo.On("MarkUsed", invite.ID, mock.AnythingOfType("primitive.ObjectID")).Return(false, nil).Once()
+		userRepo.On("FindByEmail", "race@example.com").Return(nil, nil).Once()
+
+		body, _ := json.Marshal(model.RedeemInvite{Token: "race-invite", Email: "race@example.com", Password: "kebun-mangga-2024"})
+		req := httptest.NewRequest("POST", "/register/invite", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+// setupRotatedKeys memasang key set RS256 aktif ("current") dan kunci
+// Ed25519 lama ("previous") yang masih diterima untuk verifikasi.
+func setupRotatedKeys(t *testing.T) (*config.KeySet, ed25519.PrivateKey) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	privDER, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+
+	oldPub, oldPriv, _ := ed25519.GenerateKey(rand.Reader)
+	oldPubDER, _ := x509.MarshalPKIXPublicKey(oldPub)
+
+	t.Setenv("JWT_SIGNING_KEY_FILE", writePEM(t, "current.pem", "PRIVATE KEY", privDER))
+	t.Setenv("JWT_SIGNING_KEY_ID", "current")
+	t.Setenv("JWT_VERIFICATION_KEYS", "previous="+writePEM(t, "previous.pem", "PUBLIC KEY", oldPubDER))
+
+	ks, err := config.LoadKeySet()
+	assert.NoError(t, err)
+
+	config.SetKeySet(ks)
+	t.Cleanup(func() { config.SetKeySet(nil) })
+
+	return ks, oldPriv
+}
+
+func testClaims(userID primitive.ObjectID) model.Claims {
+	return model.Claims{
+		Role:     "user",
+		Username: "alumni",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.Hex(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestAsymmetricJWT(t *testing.T) {
+	ks, oldPriv := setupRotatedKeys(t)
+
+	user := &model.Users{ID: primitive.NewObjectID(), Role: "user", Username: "alumni"}
+	userRepo := new(MockUserRepository)
+	userRepo.On("FindByID", user.ID).Return(user, nil)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("FindByName", "user").Return(&model.Role{Name: "user"}, nil)
+
+	app := fiber.New()
+	app.Get("/whoami", middleware.JWTAuth(&middleware.AuthDeps{Users: userRepo, Roles: roleRepo}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	call := func(token string) int {
+		req := httptest.NewRequest("GET", "/whoami", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	t.Run("Token Dari Kunci Aktif Memakai RS256 Dan kid", func(t *testing.T) {
+		signed, err := ks.Sign(testClaims(user.ID))
+		assert.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(signed, &model.Claims{})
+		assert.NoError(t, err)
+		assert.Equal(t, "RS256", parsed.Method.Alg())
+		assert.Equal(t, "current", parsed.Header["kid"])
+		assert.Equal(t, fiber.StatusOK, call(signed))
+	})
+
+	t.Run("Token Dari Kunci Lama Masih Diterima", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims(user.ID))
+		token.Header["kid"] = "previous"
+		signed, err := token.SignedString(oldPriv)
+		assert.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusOK, call(signed))
+	})
+
+	t.Run("kid Tidak Dikenal Ditolak", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims(user.ID))
+		token.Header["kid"] = "unknown"
+		signed, _ := token.SignedString(oldPriv)
+
+		assert.Equal(t, fiber.StatusUnauthorized, call(signed))
+	})
+
+	t.Run("HS256 Dengan Secret Ditolak", func(t *testing.T) {
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(user.ID)).SignedString([]byte(config.GetJWTSecret()))
+
+		assert.Equal(t, fiber.StatusUnauthorized, call(signed))
+	})
+}
+
+func TestLoadKeySetRequiresKeyOrSecret(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY_FILE", "")
+	t.Setenv("JWT_SECRET", "")
+
+	ks, err := config.LoadKeySet()
+	assert.Error(t, err)
+	assert.Nil(t, ks)
+
+	// KeySet cadangan tanpa secret tidak boleh menandatangani token
+	_, err = config.GetKeySet().Sign(testClaims(primitive.NewObjectID()))
+	assert.Error(t, err)
+
+	t.Setenv("JWT_SECRET", "rahasia")
+	ks, err = config.LoadKeySet()
+	assert.NoError(t, err)
+	assert.NotNil(t, ks)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", service.JWKSHandler)
+
+	fetch := func() config.JWKS {
+		resp, err := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var set config.JWKS
+		json.NewDecoder(resp.Body).Decode(&set)
+		return set
+	}
+
+	t.Run("Mode HS256 Tidak Mempublikasikan Kunci", func(t *testing.T) {
+		assert.Empty(t, fetch().Keys)
+	})
+
+	t.Run("Semua Kunci Verifikasi Dipublikasikan", func(t *testing.T) {
+		setupRotatedKeys(t)
+
+		set := fetch()
+		assert.Len(t, set.Keys, 2)
+		assert.Equal(t, "current", set.Keys[0].Kid)
+		assert.Equal(t, "RSA", set.Keys[0].Kty)
+		assert.Equal(t, "RS256", set.Keys[0].Alg)
+		assert.NotEmpty(t, set.Keys[0].N)
+		assert.Equal(t, "previous", set.Keys[1].Kid)
+		assert.Equal(t, "OKP", set.Keys[1].Kty)
+		assert.Equal(t, "Ed25519", set.Keys[1].Crv)
+	})
+}
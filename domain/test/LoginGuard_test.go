@@ -0,0 +1,150 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func loginRequest(app *fiber.App, email, password string) (int, string, map[string]interface{}) {
+	body, _ := json.Marshal(model.Login{Email: email, Password: password})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, -1)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter), result
+}
+
+func TestLoginThrottling(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+
+	t.Run("Pesan Gagal Seragam", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+		mockRepo.On("FindByEmail", "ghost@example.com").Return(nil, nil)
+		mockRepo.On("FindByEmail", "user@example.com").Return(&model.Users{ID: primitive.NewObjectID(), Email: "user@example.com", Password: string(hashed)}, nil)
+
+		ghostStatus, _, ghost := loginRequest(app, "ghost@example.com", "whatever")
+		wrongStatus, _, wrong := loginRequest(app, "user@example.com", "whatever")
+
+		assert.Equal(t, fiber.StatusUnauthorized, ghostStatus)
+		assert.Equal(t, ghostStatus, wrongStatus)
+		assert.Equal(t, "email atau password salah", ghost["error"])
+		assert.Equal(t, ghost, wrong)
+		mockLoginAttempts.AssertCalled(t, "RecordFailure", "email:ghost@example.com")
+		mockLoginAttempts.AssertCalled(t, "RecordFailure", "email:user@example.com")
+	})
+
+	t.Run("Akun Terkunci Setelah Batas Gagal", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+		mockRepo.On("FindByEmail", "user@example.com").Return(&model.Users{ID: primitive.NewObjectID(), Email: "user@example.com", Password: string(hashed)}, nil)
+
+		mockLoginAttempts.ExpectedCalls = nil
+		mockLoginAttempts.On("Reserve", mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("RecordFailure", "email:user@example.com").Return(&model.LoginAttempt{Failures: testLoginThrottle.MaxAccountFailures, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("RecordFailure", mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("Lock", "email:user@example.com", mock.AnythingOfType("time.Time")).Return(nil)
+
+		status, _, _ := loginRequest(app, "user@example.com", "wrong")
+
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		mockLoginAttempts.AssertCalled(t, "Lock", "email:user@example.com", mock.AnythingOfType("time.Time"))
+	})
+
+	t.Run("Login Ditolak Selama Terkunci", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		mockLoginAttempts.ExpectedCalls = nil
+		mockLoginAttempts.On("Reserve", "email:user@example.com", mock.Anything).Return(&model.LoginAttempt{Key: "email:user@example.com", Failures: 4, LastFailureAt: time.Now(), LockedUntil: &lockedUntil}, nil)
+		mockLoginAttempts.On("Reserve", mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("Release", mock.Anything).Return(nil)
+
+		// Password benar pun ditolak tanpa menyentuh database user
+		status, retryAfter, result := loginRequest(app, "user@example.com", "secret123")
+
+		assert.Equal(t, fiber.StatusTooManyRequests, status)
+		assert.NotEmpty(t, retryAfter)
+		assert.Equal(t, "terlalu banyak percobaan login, coba lagi nanti", result["error"])
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+		// Percobaan yang ditolak tidak ikut dihitung
+		mockLoginAttempts.AssertCalled(t, "Release", "email:user@example.com")
+		mockLoginAttempts.AssertCalled(t, "Release", "ip:0.0.0.0")
+	})
+
+	t.Run("Percobaan Paralel Melewati Batas Ditolak", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		// Hitungan sudah melewati batas karena percobaan lain masih berjalan
+		// dan belum sempat mengunci akun
+		mockLoginAttempts.ExpectedCalls = nil
+		mockLoginAttempts.On("Reserve", "email:user@example.com", mock.Anything).Return(&model.LoginAttempt{Key: "email:user@example.com", Failures: testLoginThrottle.MaxAccountFailures + 1, LastFailureAt: time.Now().Add(-time.Hour)}, nil)
+		mockLoginAttempts.On("Reserve", mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("Lock", "email:user@example.com", mock.AnythingOfType("time.Time")).Return(nil)
+		mockLoginAttempts.On("Release", mock.Anything).Return(nil)
+
+		status, retryAfter, _ := loginRequest(app, "user@example.com", "secret123")
+
+		assert.Equal(t, fiber.StatusTooManyRequests, status)
+		assert.NotEmpty(t, retryAfter)
+		mockLoginAttempts.AssertCalled(t, "Lock", "email:user@example.com", mock.AnythingOfType("time.Time"))
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	t.Run("Jeda Bertahap Setelah Beberapa Kegagalan", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+
+		// Dua kegagalan sebelumnya ditambah percobaan ini
+		mockLoginAttempts.ExpectedCalls = nil
+		mockLoginAttempts.On("Reserve", "email:user@example.com", mock.Anything).Return(&model.LoginAttempt{Key: "email:user@example.com", Failures: 3, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("Reserve", mock.Anything, mock.Anything).Return(&model.LoginAttempt{Failures: 1, LastFailureAt: time.Now()}, nil)
+		mockLoginAttempts.On("Release", mock.Anything).Return(nil)
+
+		status, retryAfter, _ := loginRequest(app, "user@example.com", "secret123")
+
+		assert.Equal(t, fiber.StatusTooManyRequests, status)
+		assert.Equal(t, "1", retryAfter)
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	t.Run("Login Berhasil Menghapus Catatan Akun", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+		mockRepo.On("FindByEmail", "user@example.com").Return(&model.Users{ID: primitive.NewObjectID(), Email: "user@example.com", Password: string(hashed)}, nil)
+
+		status, _, _ := loginRequest(app, "user@example.com", "secret123")
+
+		assert.Equal(t, fiber.StatusOK, status)
+		mockLoginAttempts.AssertCalled(t, "Reset", "email:user@example.com")
+		mockLoginAttempts.AssertCalled(t, "Release", "ip:0.0.0.0")
+	})
+}
+
+func TestUnlockLoginHandler(t *testing.T) {
+	app, _ := setupTestApp()
+
+	body, _ := json.Marshal(model.UnlockLogin{Email: "User@Example.com", IP: "10.0.0.1"})
+	req := httptest.NewRequest("POST", "/admin/login-attempts/unlock", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockLoginAttempts.AssertCalled(t, "Reset", "email:user@example.com")
+	mockLoginAttempts.AssertCalled(t, "Reset", "ip:10.0.0.1")
+
+	req = httptest.NewRequest("POST", "/admin/login-attempts/unlock", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
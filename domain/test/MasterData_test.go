@@ -0,0 +1,256 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockMasterDataRepository struct {
+	mock.Mock
+}
+
+func (m *MockMasterDataRepository) FindAll(kind string, idFakultas *int) ([]model.MasterData, error) {
+	args := m.Called(kind, idFakultas)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.MasterData), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) FindByID(kind string, id int) (*model.MasterData, error) {
+	args := m.Called(kind, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MasterData), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) FindByIDs(kind string, ids []int) ([]model.MasterData, error) {
+	args := m.Called(kind, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.MasterData), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) FindByName(kind, nama string) (*model.MasterData, error) {
+	args := m.Called(kind, nama)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MasterData), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) Create(kind string, item *model.MasterData) error {
+	args := m.Called(kind, item)
+	return args.Error(0)
+}
+
+func (m *MockMasterDataRepository) Update(kind string, item *model.MasterData) (bool, error) {
+	args := m.Called(kind, item)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) Delete(kind string, id int) (bool, error) {
+	args := m.Called(kind, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) CountProdiByFakultas(idFakultas int) (int, error) {
+	args := m.Called(idFakultas)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMasterDataRepository) EnsureIndexes() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func setupMasterDataApp() (*fiber.App, *MockMasterDataRepository, *MockAlumniRepository) {
+	masterRepo := new(MockMasterDataRepository)
+	alumniRepo := new(MockAlumniRepository)
+	svc := service.NewMasterDataService(masterRepo, alumniRepo)
+
+	app := fiber.New()
+	app.Get("/master/:kind", svc.ListMasterDataHandler())
+	app.Post("/admin/master/:kind", svc.CreateMasterDataHandler())
+	app.Put("/admin/master/:kind/:id", svc.UpdateMasterDataHandler())
+	app.Delete("/admin/master/:kind/:id", svc.DeleteMasterDataHandler())
+
+	return app, masterRepo, alumniRepo
+}
+
+func TestCreateMasterDataHandler(t *testing.T) {
+	t.Run("Prodi Wajib Punya Fakultas Yang Ada", func(t *testing.T) {
+		app, masterRepo, _ := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterFakultas, 9).Return(nil, nil)
+
+		status, _ := adminRequest(app, "POST", "/admin/master/prodi", model.SaveMasterData{ID: 12, Nama: "Informatika"})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+
+		status, _ = adminRequest(app, "POST", "/admin/master/prodi", model.SaveMasterData{ID: 12, Nama: "Informatika", IDFakultas: intPtr(9)})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		masterRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Sukses Dan ID Duplikat", func(t *testing.T) {
+		app, masterRepo, _ := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterFakultas, 3).Return(&model.MasterData{ID: 3, Nama: "Teknik"}, nil)
+		masterRepo.On("Create", model.MasterProdi, mock.MatchedBy(func(item *model.MasterData) bool {
+			return item.ID == 12 && item.Nama == "Informatika" && *item.IDFakultas == 3
+		})).Return(nil).Once()
+		masterRepo.On("Create", model.MasterProdi, mock.Anything).Return(mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}).Once()
+
+		payload := model.SaveMasterData{ID: 12, Nama: " Informatika ", IDFakultas: intPtr(3)}
+		status, _ := adminRequest(app, "POST", "/admin/master/prodi", payload)
+		assert.Equal(t, fiber.StatusCreated, status)
+
+		status, _ = adminRequest(app, "POST", "/admin/master/prodi", payload)
+		assert.Equal(t, fiber.StatusConflict, status)
+	})
+
+	t.Run("Jenis Tidak Dikenal", func(t *testing.T) {
+		app, _, _ := setupMasterDataApp()
+
+		status, _ := adminRequest(app, "POST", "/admin/master/jurusan", model.SaveMasterData{ID: 1, Nama: "X"})
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+}
+
+func TestUpdateMasterDataHandler(t *testing.T) {
+	t.Run("Nama Sumber Baru Ikut Ke Data Alumni", func(t *testing.T) {
+		app, masterRepo, alumniRepo := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterSumber, 1).Return(&model.MasterData{ID: 1, Nama: "SNMPTN"}, nil)
+		masterRepo.On("Update", model.MasterSumber, mock.AnythingOfType("*model.MasterData")).Return(true, nil)
+		alumniRepo.On("RenameSumber", 1, "SNBP").Return(nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/master/sumber/1", model.SaveMasterData{Nama: "SNBP"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		alumniRepo.AssertCalled(t, "RenameSumber", 1, "SNBP")
+	})
+
+	t.Run("Nama Tidak Berubah Tidak Menyentuh Alumni", func(t *testing.T) {
+		app, masterRepo, alumniRepo := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterSumber, 1).Return(&model.MasterData{ID: 1, Nama: "SNMPTN"}, nil)
+		masterRepo.On("Update", model.MasterSumber, mock.AnythingOfType("*model.MasterData")).Return(true, nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/master/sumber/1", model.SaveMasterData{Nama: " SNMPTN "})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		alumniRepo.AssertNotCalled(t, "RenameSumber", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteMasterDataHandler(t *testing.T) {
+	t.Run("Fakultas Masih Memiliki Prodi", func(t *testing.T) {
+		app, masterRepo, _ := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterFakultas, 3).Return(&model.MasterData{ID: 3, Nama: "Teknik"}, nil)
+		masterRepo.On("CountProdiByFakultas", 3).Return(2, nil)
+
+		status, _ := adminRequest(app, "DELETE", "/admin/master/fakultas/3", nil)
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		masterRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Sumber Masih Dipakai Alumni", func(t *testing.T) {
+		app, masterRepo, alumniRepo := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterSumber, 1).Return(&model.MasterData{ID: 1, Nama: "SNMPTN"}, nil)
+		alumniRepo.On("CountAlumni", model.AlumniFilter{IDSumber: []int{1}}).Return(40, nil)
+
+		status, _ := adminRequest(app, "DELETE", "/admin/master/sumber/1", nil)
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		masterRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Sukses", func(t *testing.T) {
+		app, masterRepo, alumniRepo := setupMasterDataApp()
+		masterRepo.On("FindByID", model.MasterProdi, 12).Return(&model.MasterData{ID: 12, Nama: "Informatika", IDFakultas: intPtr(3)}, nil)
+		alumniRepo.On("CountAlumni", model.AlumniFilter{IDProdi: []int{12}}).Return(0, nil)
+		masterRepo.On("Delete", model.MasterProdi, 12).Return(true, nil)
+
+		status, _ := adminRequest(app, "DELETE", "/admin/master/prodi/12", nil)
+
+		assert.Equal(t, fiber.StatusOK, status)
+	})
+}
+
+func TestAlumniMasterDataReferences(t *testing.T) {
+	masterRepo := new(MockMasterDataRepository)
+	alumniRepo := new(MockAlumniRepository)
+	masterRepo.On("FindByID", model.MasterProdi, 12).Return(&model.MasterData{ID: 12, Nama: "Informatika", IDFakultas: intPtr(3)}, nil)
+	masterRepo.On("FindByID", model.MasterProdi, 99).Return(nil, nil)
+	masterRepo.On("FindByID", model.MasterFakultas, 3).Return(&model.MasterData{ID: 3, Nama: "Teknik"}, nil)
+	masterRepo.On("FindByName", model.MasterSumber, "snmptn").Return(&model.MasterData{ID: 1, Nama: "SNMPTN"}, nil)
+
+	svc := service.NewAlumniService(alumniRepo, masterRepo)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, adminActor)
+		return c.Next()
+	})
+	app.Post("/alumni", svc.CreateAlumniService)
+	app.Get("/alumni/:nim", svc.CheckAlumniService)
+
+	post := func(alumni model.Alumni) int {
+		body, _ := json.Marshal(alumni)
+		req := httptest.NewRequest("POST", "/alumni", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	t.Run("Prodi Tidak Dikenal Ditolak", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusBadRequest, post(model.Alumni{NIM: "1", IDProdi: intPtr(99)}))
+		assert.Equal(t, fiber.StatusBadRequest, post(model.Alumni{NIM: "1", IDProdi: intPtr(12), IDFakultas: intPtr(4)}))
+		alumniRepo.AssertNotCalled(t, "CreateAlumni", mock.Anything)
+	})
+
+	t.Run("Fakultas Dan Sumber Dilengkapi", func(t *testing.T) {
+		alumniRepo.On("CreateAlumni", mock.MatchedBy(func(a *model.Alumni) bool {
+			return *a.IDFakultas == 3 && *a.IDSumber == 1 && *a.Sumber == "SNMPTN"
+		})).Return(nil).Once()
+
+		sumber := "snmptn"
+		assert.Equal(t, fiber.StatusCreated, post(model.Alumni{NIM: "1", IDProdi: intPtr(12), Sumber: &sumber}))
+		alumniRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expand Nama Master Data", func(t *testing.T) {
+		alumniRepo.On("CheckAlumniByNim", "1").Return(&model.Alumni{NIM: "1", IDFakultas: intPtr(3), IDProdi: intPtr(12)}, nil)
+		masterRepo.On("FindByIDs", model.MasterFakultas, []int{3}).Return([]model.MasterData{{ID: 3, Nama: "Teknik"}}, nil)
+		masterRepo.On("FindByIDs", model.MasterProdi, []int{12}).Return([]model.MasterData{{ID: 12, Nama: "Informatika"}}, nil)
+
+		req := httptest.NewRequest("GET", "/alumni/1?expand=true", nil)
+		resp, _ := app.Test(req)
+
+		var result struct {
+			Alumni model.AdminAlumni `json:"alumni"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, "Teknik", result.Alumni.NamaFakultas)
+		assert.Equal(t, "Informatika", result.Alumni.NamaProdi)
+
+		// Tanpa expand tidak ada query ke master data
+		req = httptest.NewRequest("GET", "/alumni/1", nil)
+		app.Test(req)
+		masterRepo.AssertNumberOfCalls(t, "FindByIDs", 2)
+	})
+}
@@ -0,0 +1,290 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testOIDCClientID = "alumni-api"
+
+type MockOIDCStateRepository struct {
+	mock.Mock
+}
+
+func (m *MockOIDCStateRepository) Create(state *model.OIDCState) error {
+	args := m.Called(state)
+	return args.Error(0)
+}
+
+func (m *MockOIDCStateRepository) Consume(hash string) (*model.OIDCState, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OIDCState), args.Error(1)
+}
+
+// mockOIDCProvider adalah identity provider lokal: discovery, JWKS, dan
+// token endpoint yang memeriksa code_verifier PKCE.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{key: key, codes: map[string]mockAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": "idp-1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		p.mu.Lock()
+		auth, ok := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+		p.mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, auth.claims)
+		token.Header["kid"] = "idp-1"
+		idToken, _ := token.SignedString(key)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "idp-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize meniru user yang berhasil login di identity provider: code
+// diterbitkan untuk challenge dan nonce dari URL redirect.
+func (p *mockOIDCProvider) authorize(authURL *url.URL, code string, claims jwt.MapClaims) {
+	query := authURL.Query()
+	base := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   testOIDCClientID,
+		"sub":   "idp-subject-1",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for k, v := range claims {
+		base[k] = v
+	}
+
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), claims: base}
+	p.mu.Unlock()
+}
+
+func setupOIDCApp(t *testing.T) (*fiber.App, *MockUserRepository, *MockOIDCStateRepository, *mockOIDCProvider) {
+	provider := newMockOIDCProvider(t)
+	states := new(MockOIDCStateRepository)
+	client := service.NewOIDCClient(states, config.OIDCConfig{
+		IssuerURL:   provider.server.URL,
+		ClientID:    testOIDCClientID,
+		RedirectURL: "http://localhost:3000/api/auth/oidc/callback",
+		Scopes:      []string{"openid", "email"},
+		StateExpiry: time.Minute,
+	})
+
+	app, mockRepo := setupAuthApp(testTwoFactorConfig, client)
+	return app, mockRepo, states, provider
+}
+
+// startOIDCLogin memanggil endpoint login dan mengembalikan URL redirect
+// beserta state yang tersimpan.
+func startOIDCLogin(t *testing.T, app *fiber.App, states *MockOIDCStateRepository) (*url.URL, *model.OIDCState) {
+	var stored *model.OIDCState
+	states.On("Create", mock.AnythingOfType("*model.OIDCState")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.OIDCState)
+	}).Return(nil).Once()
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/auth/oidc/login", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusFound, resp.StatusCode)
+
+	authURL, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	states.On("Consume", hashForTest(authURL.Query().Get("state"))).Return(stored, nil).Once()
+	return authURL, stored
+}
+
+func oidcCallback(app *fiber.App, code, state string) (int, map[string]interface{}) {
+	query := url.Values{"code": {code}, "state": {state}}
+	resp, _ := app.Test(httptest.NewRequest("GET", "/auth/oidc/callback?"+query.Encode(), nil), -1)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestOIDCLoginRedirect(t *testing.T) {
+	app, _, states, provider := setupOIDCApp(t)
+	authURL, stored := startOIDCLogin(t, app, states)
+
+	assert.Equal(t, provider.server.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	query := authURL.Query()
+	assert.Equal(t, testOIDCClientID, query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, stored.Nonce, query.Get("nonce"))
+	assert.Equal(t, hashForTest(query.Get("state")), stored.StateHash)
+	// Verifier tidak pernah dikirim ke browser
+	assert.NotContains(t, authURL.String(), stored.CodeVerifier)
+}
+
+func TestOIDCCallback(t *testing.T) {
+	t.Run("Email baru dibuatkan user dengan role user", func(t *testing.T) {
+		app, mockRepo, states, provider := setupOIDCApp(t)
+		authURL, _ := startOIDCLogin(t, app, states)
+		provider.authorize(authURL, "code-1", jwt.MapClaims{"email": "alumni@kampus.ac.id", "email_verified": true, "preferred_username": "alumni01"})
+
+		mockRepo.On("FindByEmail", "alumni@kampus.ac.id").Return(nil, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(u *model.Users) bool {
+			return u.Role == "user" && u.Status == model.UserStatusActive && u.Username == "alumni01" && u.Password != ""
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Users).ID = primitive.NewObjectID()
+		}).Return(nil)
+
+		status, result := oidcCallback(app, "code-1", authURL.Query().Get("state"))
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.NotEmpty(t, result["token"])
+		assert.NotEmpty(t, result["refresh_token"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email terdaftar dipetakan ke user yang ada", func(t *testing.T) {
+		app, mockRepo, states, provider := setupOIDCApp(t)
+		existing := &model.Users{ID: primitive.NewObjectID(), Email: "staf@kampus.ac.id", Role: "admin", Status: model.UserStatusActive}
+		authURL, _ := startOIDCLogin(t, app, states)
+		provider.authorize(authURL, "code-2", jwt.MapClaims{"email": existing.Email, "email_verified": true})
+		mockRepo.On("FindByEmail", existing.Email).Return(existing, nil)
+
+		status, result := oidcCallback(app, "code-2", authURL.Query().Get("state"))
+		assert.Equal(t, fiber.StatusOK, status)
+		user, _ := result["user"].(map[string]interface{})
+		assert.Equal(t, existing.ID.Hex(), user["id"])
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Akun belum terverifikasi diambil alih pemilik email", func(t *testing.T) {
+		app, mockRepo, states, provider := setupOIDCApp(t)
+		// Akun didaftarkan orang lain dengan email korban dan password miliknya
+		attackerHash := "$2a$04$hash-password-milik-pendaftar-lain"
+		existing := &model.Users{ID: primitive.NewObjectID(), Email: "korban@kampus.ac.id", Role: "user", Status: model.UserStatusUnverified, Password: attackerHash}
+		authURL, _ := startOIDCLogin(t, app, states)
+		provider.authorize(authURL, "code-6", jwt.MapClaims{"email": existing.Email, "email_verified": true})
+		mockRepo.On("FindByEmail", existing.Email).Return(existing, nil)
+		mockRepo.On("Update", existing).Return(nil)
+		mockRefreshRepo.On("RevokeAllForUser", existing.ID).Return(nil)
+
+		status, _ := oidcCallback(app, "code-6", authURL.Query().Get("state"))
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, model.UserStatusActive, existing.Status)
+		assert.NotEqual(t, attackerHash, existing.Password)
+		assert.NotEmpty(t, existing.Password)
+		assert.NotNil(t, existing.PasswordChangedAt)
+		mockRefreshRepo.AssertCalled(t, "RevokeAllForUser", existing.ID)
+		mockSessionRepo.AssertCalled(t, "RevokeAllForUser", existing.ID)
+	})
+
+	t.Run("Email belum terverifikasi ditolak", func(t *testing.T) {
+		app, mockRepo, states, provider := setupOIDCApp(t)
+		authURL, _ := startOIDCLogin(t, app, states)
+		provider.authorize(authURL, "code-3", jwt.MapClaims{"email": "palsu@kampus.ac.id", "email_verified": false})
+
+		status, _ := oidcCallback(app, "code-3", authURL.Query().Get("state"))
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	t.Run("Nonce tidak cocok ditolak", func(t *testing.T) {
+		app, _, states, provider := setupOIDCApp(t)
+		authURL, _ := startOIDCLogin(t, app, states)
+		provider.authorize(authURL, "code-4", jwt.MapClaims{"email": "alumni@kampus.ac.id", "email_verified": true, "nonce": "nonce-lain"})
+
+		status, _ := oidcCallback(app, "code-4", authURL.Query().Get("state"))
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+
+	t.Run("State yang sudah dipakai ditolak", func(t *testing.T) {
+		app, _, states, _ := setupOIDCApp(t)
+		states.On("Consume", hashForTest("state-bekas")).Return(nil, nil)
+
+		status, _ := oidcCallback(app, "code-5", "state-bekas")
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	app, _ := setupTestApp()
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/auth/oidc/login", nil))
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
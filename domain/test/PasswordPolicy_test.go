@@ -0,0 +1,84 @@
+package test
+
+import (
+	"testing"
+
+	"Mongo/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func violationCodes(result map[string]interface{}) []string {
+	var codes []string
+	violations, _ := result["violations"].([]interface{})
+	for _, v := range violations {
+		if violation, ok := v.(map[string]interface{}); ok {
+			codes = append(codes, violation["code"].(string))
+		}
+	}
+	return codes
+}
+
+func TestRegisterPasswordPolicy(t *testing.T) {
+	cases := []struct {
+		name     string
+		payload  model.Register
+		expected []string
+	}{
+		{"Password kosong", model.Register{Email: "a@example.com", Username: "alumni"}, []string{model.PasswordRequired}},
+		{"Terlalu pendek", model.Register{Email: "a@example.com", Username: "alumni", Password: "Xy7#"}, []string{model.PasswordTooShort}},
+		{"Password umum", model.Register{Email: "a@example.com", Username: "alumni", Password: "Password123"}, []string{model.PasswordTooCommon}},
+		{"Memuat email", model.Register{Email: "budi.santoso@example.com", Username: "bs", Password: "budi.santoso!!"}, []string{model.PasswordContainsEmail}},
+		{"Memuat username", model.Register{Email: "a@example.com", Username: "budiman", Password: "Budiman-2024"}, []string{model.PasswordContainsUsername}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app, mockRepo := setupTestApp()
+
+			status, result := postAuthJSON(app, "/register", tc.payload)
+			assert.Equal(t, fiber.StatusBadRequest, status)
+			assert.Equal(t, tc.expected, violationCodes(result))
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestLoginRehashesWeakHash(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("kebun-mangga-2024"), bcrypt.MinCost)
+
+	t.Run("Hash dengan cost lebih rendah diperbarui", func(t *testing.T) {
+		t.Setenv("BCRYPT_COST", "5")
+		app, mockRepo := setupTestApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "user@example.com", Password: string(hashed), Role: "user"}
+		mockRepo.On("FindByEmail", user.Email).Return(user, nil)
+		mockRepo.On("UpdatePasswordHash", user.ID, string(hashed), mock.AnythingOfType("string")).Return(nil)
+
+		status, _ := postAuthJSON(app, "/login", model.Login{Email: user.Email, Password: "kebun-mangga-2024"})
+		assert.Equal(t, fiber.StatusOK, status)
+
+		// Hanya hash yang diganti, dan hanya jika password tersimpan masih
+		// hash lama
+		mockRepo.AssertCalled(t, "UpdatePasswordHash", user.ID, string(hashed), mock.MatchedBy(func(h string) bool {
+			cost, err := bcrypt.Cost([]byte(h))
+			return err == nil && cost == 5 && bcrypt.CompareHashAndPassword([]byte(h), []byte("kebun-mangga-2024")) == nil
+		}))
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		// Rehash bukan pergantian password, jadi token lama tetap berlaku
+		assert.Nil(t, user.PasswordChangedAt)
+	})
+
+	t.Run("Hash dengan cost yang sama tidak disentuh", func(t *testing.T) {
+		app, mockRepo := setupTestApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "user@example.com", Password: string(hashed), Role: "user"}
+		mockRepo.On("FindByEmail", user.Email).Return(user, nil)
+
+		status, _ := postAuthJSON(app, "/login", model.Login{Email: user.Email, Password: "kebun-mangga-2024"})
+		assert.Equal(t, fiber.StatusOK, status)
+		mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"Mongo/domain/mailer"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockOneTimeTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockOneTimeTokenRepository) Create(token *model.OneTimeToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockOneTimeTokenRepository) FindByHash(purpose, hash string) (*model.OneTimeToken, error) {
+	args := m.Called(purpose, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OneTimeToken), args.Error(1)
+}
+
+func (m *MockOneTimeTokenRepository) Consume(id primitive.ObjectID) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOneTimeTokenRepository) InvalidateForUser(userID primitive.ObjectID, purpose string) error {
+	args := m.Called(userID, purpose)
+	return args.Error(0)
+}
+
+func setupPasswordApp(t *testing.T) (*fiber.App, *MockUserRepository, *MockOneTimeTokenRepository, *MockRefreshTokenRepository, *mailer.FileOutbox) {
+	userRepo := new(MockUserRepository)
+	tokenRepo := new(MockOneTimeTokenRepository)
+	refreshRepo := new(MockRefreshTokenRepository)
+
+	outbox, err := mailer.NewFileOutbox(t.TempDir(), "no-reply@test")
+	assert.NoError(t, err)
+
+	passwordService := service.NewPasswordService(userRepo, tokenRepo, service.NewSessionManager(newLenientSessionRepo(), refreshRepo), outbox)
+
+	app := fiber.New()
+	app.Post("/password/forgot", passwordService.ForgotPasswordHandler())
+	app.Post("/password/reset", passwordService.ResetPasswordHandler())
+
+	return app, userRepo, tokenRepo, refreshRepo, outbox
+}
+
+func postJSON(app *fiber.App, path string, payload interface{}) (int, map[string]interface{}) {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestForgotPasswordHandler(t *testing.T) {
+	t.Run("Email Tidak Terdaftar - Respons Tetap Sama", func(t *testing.T) {
+		app, userRepo, tokenRepo, _, outbox := setupPasswordApp(t)
+		userRepo.On("FindByEmail", "ghost@test.com").Return(nil, nil)
+
+		status, result := postJSON(app, "/password/forgot", model.ForgotPassword{Email: "ghost@test.com"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "jika email terdaftar, tautan reset password telah dikirim", result["message"])
+		tokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+		files, _ := outbox.Messages()
+		assert.Empty(t, files)
+	})
+
+	t.Run("Email Terdaftar - Token Disimpan Ter-hash Dan Email Dikirim", func(t *testing.T) {
+		app, userRepo, tokenRepo, _, outbox := setupPasswordApp(t)
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "alumni@test.com", Username: "alumni"}
+		userRepo.On("FindByEmail", user.Email).Return(user, nil)
+		tokenRepo.On("InvalidateForUser", user.ID, model.TokenPurposePasswordReset).Return(nil)
+
+		var saved *model.OneTimeToken
+		tokenRepo.On("Create", mock.AnythingOfType("*model.OneTimeToken")).Run(func(args mock.Arguments) {
+			saved = args.Get(0).(*model.OneTimeToken)
+		}).Return(nil)
+
+		status, result := postJSON(app, "/password/forgot", model.ForgotPassword{Email: user.Email})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "jika email terdaftar, tautan reset password telah dikirim", result["message"])
+
+		files, _ := outbox.Messages()
+		assert.Len(t, files, 1)
+		raw, _ := os.ReadFile(files[0])
+		assert.Contains(t, string(raw), "To: alumni@test.com")
+
+		token := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(string(raw))
+		assert.Len(t, token, 2)
+		assert.Equal(t, hashForTest(token[1]), saved.TokenHash)
+		assert.Equal(t, user.ID, saved.UserID)
+		assert.True(t, saved.ExpiresAt.After(time.Now()))
+	})
+}
+
+func TestResetPasswordHandler(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	t.Run("Sukses - Password Diganti Dan Sesi Dicabut", func(t *testing.T) {
+		app, userRepo, tokenRepo, refreshRepo, _ := setupPasswordApp(t)
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(time.Hour)}
+		user := &model.Users{ID: userID, Email: "alumni@test.com", Password: "old-hash"}
+
+		tokenRepo.On("FindByHash", model.TokenPurposePasswordReset, hashForTest("valid-token")).Return(record, nil)
+		tokenRepo.On("Consume", record.ID).Return(true, nil)
+		userRepo.On("FindByID", userID).Return(user, nil)
+		userRepo.On("Update", mock.AnythingOfType("*model.Users")).Return(nil)
+		refreshRepo.On("RevokeAllForUser", userID).Return(nil)
+
+		status, _ := postJSON(app, "/password/reset", model.ResetPassword{Token: "valid-token", Password: "newpassword123"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newpassword123")))
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Gagal - Token Kedaluwarsa", func(t *testing.T) {
+		app, userRepo, tokenRepo, _, _ := setupPasswordApp(t)
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(-time.Minute)}
+		tokenRepo.On("FindByHash", model.TokenPurposePasswordReset, hashForTest("old-token")).Return(record, nil)
+
+		status, _ := postJSON(app, "/password/reset", model.ResetPassword{Token: "old-token", Password: "newpassword123"})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Gagal - Token Sudah Dipakai Request Lain", func(t *testing.T) {
+		app, userRepo, tokenRepo, _, _ := setupPasswordApp(t)
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(time.Hour)}
+		tokenRepo.On("FindByHash", model.TokenPurposePasswordReset, hashForTest("raced-token")).Return(record, nil)
+		tokenRepo.On("Consume", record.ID).Return(false, nil)
+		userRepo.On("FindByID", userID).Return(&model.Users{ID: userID}, nil)
+
+		status, _ := postJSON(app, "/password/reset", model.ResetPassword{Token: "raced-token", Password: "newpassword123"})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Gagal - Token Tidak Dikenal", func(t *testing.T) {
+		app, _, tokenRepo, _, _ := setupPasswordApp(t)
+		tokenRepo.On("FindByHash", model.TokenPurposePasswordReset, hashForTest("unknown")).Return(nil, nil)
+
+		status, _ := postJSON(app, "/password/reset", model.ResetPassword{Token: "unknown", Password: "newpassword123"})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/config"
+	"Mongo/domain/mailer"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type profileMocks struct {
+	users   *MockUserRepository
+	refresh *MockRefreshTokenRepository
+	tokens  *MockOneTimeTokenRepository
+	mailer  *MockMailer
+}
+
+func setupProfileApp(userID primitive.ObjectID) (*fiber.App, *profileMocks) {
+	m := &profileMocks{
+		users:   new(MockUserRepository),
+		refresh: new(MockRefreshTokenRepository),
+		tokens:  new(MockOneTimeTokenRepository),
+		mailer:  new(MockMailer),
+	}
+	verifier := service.NewVerificationService(m.users, m.tokens, m.mailer)
+	svc := service.NewProfileService(m.users, service.NewSessionManager(newLenientSessionRepo(), m.refresh), verifier)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, &model.Principal{UserID: userID, Role: "user"})
+		return c.Next()
+	})
+	app.Get("/me", svc.GetMeHandler())
+	app.Patch("/me", svc.UpdateMeHandler())
+	app.Post("/me/password", svc.ChangePasswordHandler())
+
+	return app, m
+}
+
+func TestGetMeHandler(t *testing.T) {
+	user := &model.Users{ID: primitive.NewObjectID(), Email: "me@example.com", Username: "me", Password: "hash", Role: "user"}
+	app, m := setupProfileApp(user.ID)
+	m.users.On("FindByID", user.ID).Return(user, nil)
+
+	status, result := adminRequest(app, "GET", "/me", nil)
+
+	assert.Equal(t, fiber.StatusOK, status)
+	body, _ := result["user"].(map[string]interface{})
+	assert.Equal(t, "me@example.com", body["email"])
+	assert.NotContains(t, body, "password")
+}
+
+func TestUpdateMeHandler(t *testing.T) {
+	t.Run("Ganti Username", func(t *testing.T) {
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "me@example.com", Username: "me", Status: model.UserStatusActive}
+		app, m := setupProfileApp(user.ID)
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("Update", user).Return(nil)
+
+		status, _ := adminRequest(app, "PATCH", "/me", model.UpdateUser{Username: "renamed"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "renamed", user.Username)
+		assert.Equal(t, model.UserStatusActive, user.Status)
+		m.mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("Ganti Email Menunggu Verifikasi", func(t *testing.T) {
+		now := time.Now()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "me@example.com", Status: model.UserStatusActive, VerifiedAt: &now}
+		app, m := setupProfileApp(user.ID)
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("FindByEmail", "new@example.com").Return(nil, nil)
+		m.users.On("Update", user).Return(nil)
+		m.tokens.On("InvalidateForUser", user.ID, model.TokenPurposeEmailVerification).Return(nil)
+		m.tokens.On("Create", mock.MatchedBy(func(token *model.OneTimeToken) bool {
+			return token.Email == "new@example.com"
+		})).Return(nil)
+		m.mailer.On("Send", mock.Anything).Return(nil)
+
+		status, _ := adminRequest(app, "PATCH", "/me", model.UpdateUser{Email: "new@example.com"})
+
+		// Akun tetap aktif dengan email lama sampai alamat baru diverifikasi
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "me@example.com", user.Email)
+		assert.Equal(t, "new@example.com", user.PendingEmail)
+		assert.Equal(t, model.UserStatusActive, user.Status)
+		assert.NotNil(t, user.VerifiedAt)
+		m.mailer.AssertCalled(t, "Send", mock.MatchedBy(func(msg mailer.Message) bool {
+			return msg.To == "new@example.com"
+		}))
+	})
+
+	t.Run("Email Sudah Dipakai", func(t *testing.T) {
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "me@example.com"}
+		app, m := setupProfileApp(user.ID)
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("FindByEmail", "taken@example.com").Return(&model.Users{ID: primitive.NewObjectID()}, nil)
+
+		status, _ := adminRequest(app, "PATCH", "/me", model.UpdateUser{Email: "taken@example.com"})
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.users.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("oldsecret"), bcrypt.MinCost)
+
+	t.Run("Password Lama Salah", func(t *testing.T) {
+		user := &model.Users{ID: primitive.NewObjectID(), Password: string(hashed)}
+		app, m := setupProfileApp(user.ID)
+		m.users.On("FindByID", user.ID).Return(user, nil)
+
+		status, _ := adminRequest(app, "POST", "/me/password", model.ChangePassword{CurrentPassword: "wrong", NewPassword: "newsecret"})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		m.users.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Sukses Dan Sesi Dicabut", func(t *testing.T) {
+		user := &model.Users{ID: primitive.NewObjectID(), Password: string(hashed)}
+		app, m := setupProfileApp(user.ID)
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("Update", user).Return(nil)
+		m.refresh.On("RevokeAllForUser", user.ID).Return(nil)
+
+		status, _ := adminRequest(app, "POST", "/me/password", model.ChangePassword{CurrentPassword: "oldsecret", NewPassword: "newsecret"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newsecret")))
+		assert.NotNil(t, user.PasswordChangedAt)
+		m.refresh.AssertCalled(t, "RevokeAllForUser", user.ID)
+	})
+}
+
+func TestJWTAuthRejectsTokenBeforePasswordChange(t *testing.T) {
+	changedAt := time.Now()
+	user := &model.Users{ID: primitive.NewObjectID(), Role: "user", PasswordChangedAt: &changedAt}
+	userRepo := new(MockUserRepository)
+	userRepo.On("FindByID", user.ID).Return(user, nil)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("FindByName", "user").Return(&model.Role{Name: "user"}, nil)
+
+	app := fiber.New()
+	app.Get("/me", middleware.JWTAuth(&middleware.AuthDeps{Users: userRepo, Roles: roleRepo}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	call := func(issuedAt time.Time) int {
+		claims := testClaims(user.ID)
+		claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+		signed, _ := config.GetKeySet().Sign(claims)
+
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusUnauthorized, call(changedAt.Add(-time.Minute)))
+	assert.Equal(t, fiber.StatusOK, call(changedAt))
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) FindByName(name string) (*model.Role, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) FindAll() ([]model.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) Create(role *model.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Update(role *model.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Delete(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) EnsureDefaults(roles []model.Role) error {
+	args := m.Called(roles)
+	return args.Error(0)
+}
+
+func TestRequirePermission(t *testing.T) {
+	newApp := func(p *model.Principal) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			if p != nil {
+				middleware.SetPrincipal(c, p)
+			}
+			return c.Next()
+		})
+		app.Get("/restore", middleware.RequirePermission(model.PermPekerjaanRestore), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+		return app
+	}
+
+	t.Run("Allowed - Custom Role With Permission", func(t *testing.T) {
+		app := newApp(&model.Principal{UserID: primitive.NewObjectID(), Role: "staff prodi", Permissions: []string{model.PermPekerjaanRestore}})
+		resp, _ := app.Test(httptest.NewRequest("GET", "/restore", nil))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Allowed - Wildcard", func(t *testing.T) {
+		app := newApp(&model.Principal{UserID: primitive.NewObjectID(), Role: "admin", Permissions: []string{model.PermAll}})
+		resp, _ := app.Test(httptest.NewRequest("GET", "/restore", nil))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Forbidden - Missing Permission", func(t *testing.T) {
+		app := newApp(&model.Principal{UserID: primitive.NewObjectID(), Role: "user", Permissions: []string{model.PermPekerjaanRead}})
+		resp, _ := app.Test(httptest.NewRequest("GET", "/restore", nil))
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Unauthorized - No Principal", func(t *testing.T) {
+		app := newApp(nil)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/restore", nil))
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestCreateRoleHandler(t *testing.T) {
+	setupAs := func(actor *model.Principal) (*fiber.App, *MockRoleRepository) {
+		roleRepo := new(MockRoleRepository)
+		svc := service.NewRoleService(roleRepo, new(MockUserRepository))
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			middleware.SetPrincipal(c, actor)
+			return c.Next()
+		})
+		app.Post("/admin/roles", svc.CreateRoleHandler())
+		app.Put("/admin/roles/:name", svc.UpdateRoleHandler())
+		return app, roleRepo
+	}
+	setup := func() (*fiber.App, *MockRoleRepository) {
+		return setupAs(adminActor)
+	}
+	send := func(app *fiber.App, method, path string, payload model.RoleRequest) int {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		app, roleRepo := setup()
+		roleRepo.On("FindByName", "staff prodi").Return(nil, nil).Once()
+		roleRepo.On("Create", mock.AnythingOfType("*model.Role")).Return(nil).Once()
+
+		body, _ := json.Marshal(model.RoleRequest{Name: "Staff Prodi", Permissions: []string{model.PermAlumniRead, model.PermPekerjaanRestore}})
+		req := httptest.NewRequest("POST", "/admin/roles", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		roleRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail - Unknown Permission", func(t *testing.T) {
+		app, roleRepo := setup()
+
+		body, _ := json.Marshal(model.RoleRequest{Name: "hacker", Permissions: []string{"alumni:everything"}})
+		req := httptest.NewRequest("POST", "/admin/roles", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		roleRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Fail - Wildcard", func(t *testing.T) {
+		app, roleRepo := setup()
+
+		status := send(app, "POST", "/admin/roles", model.RoleRequest{Name: "super", Permissions: []string{model.PermAll}})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		roleRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Fail - Permission Melebihi Pemanggil", func(t *testing.T) {
+		// Pemegang roles:manage saja tidak bisa membuat role admin tiruan
+		manager := &model.Principal{UserID: primitive.NewObjectID(), Role: "operator", Permissions: []string{model.PermRolesManage, model.PermAlumniRead}}
+		app, roleRepo := setupAs(manager)
+		roleRepo.On("FindByName", "pembaca").Return(nil, nil)
+		roleRepo.On("Create", mock.AnythingOfType("*model.Role")).Return(nil)
+
+		status := send(app, "POST", "/admin/roles", model.RoleRequest{Name: "operator2", Permissions: []string{model.PermRolesManage, model.PermUsersManage}})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status = send(app, "PUT", "/admin/roles/user", model.RoleRequest{Permissions: []string{model.PermInvitesManage}})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		roleRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+		status = send(app, "POST", "/admin/roles", model.RoleRequest{Name: "pembaca", Permissions: []string{model.PermAlumniRead}})
+		assert.Equal(t, fiber.StatusCreated, status)
+	})
+}
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(session *model.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByID(id string) (*model.Session, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActiveByUser(userID primitive.ObjectID) ([]model.Session, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Touch(id string, seenAt time.Time, ip string) error {
+	args := m.Called(id, seenAt, ip)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Revoke(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAllForUser(userID primitive.ObjectID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// newLenientSessionRepo mengembalikan repo sesi yang menerima semua
+// panggilan tulis, untuk test yang tidak sedang menguji sesi.
+func newLenientSessionRepo() *MockSessionRepository {
+	repo := new(MockSessionRepository)
+	repo.On("Create", mock.AnythingOfType("*model.Session")).Return(nil).Maybe()
+	repo.On("FindByID", mock.Anything).Return(nil, nil).Maybe()
+	repo.On("Touch", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("Revoke", mock.Anything).Return(nil).Maybe()
+	repo.On("RevokeAllForUser", mock.Anything).Return(nil).Maybe()
+	return repo
+}
+
+func setupSessionApp(principal *model.Principal) (*fiber.App, *MockSessionRepository, *MockRefreshTokenRepository) {
+	sessionRepo := new(MockSessionRepository)
+	refreshRepo := new(MockRefreshTokenRepository)
+	svc := service.NewSessionService(service.NewSessionManager(sessionRepo, refreshRepo))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, principal)
+		return c.Next()
+	})
+	app.Get("/me/sessions", svc.ListMySessionsHandler())
+	app.Delete("/me/sessions/:id", svc.RevokeMySessionHandler())
+
+	return app, sessionRepo, refreshRepo
+}
+
+func TestListMySessions(t *testing.T) {
+	principal := &model.Principal{UserID: primitive.NewObjectID(), Role: "user", SessionID: "sess-current"}
+	app, sessionRepo, _ := setupSessionApp(principal)
+	sessionRepo.On("ListActiveByUser", principal.UserID).Return([]model.Session{
+		{ID: "sess-current", UserID: principal.UserID, UserAgent: "Firefox"},
+		{ID: "sess-other", UserID: principal.UserID, UserAgent: "curl"},
+	}, nil)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/me/sessions", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Sessions []map[string]interface{} `json:"sessions"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result.Sessions, 2)
+	assert.Equal(t, true, result.Sessions[0]["current"])
+	assert.Equal(t, false, result.Sessions[1]["current"])
+	assert.NotContains(t, result.Sessions[0], "user_id")
+}
+
+func TestRevokeMySession(t *testing.T) {
+	principal := &model.Principal{UserID: primitive.NewObjectID(), Role: "user", SessionID: "sess-current"}
+
+	t.Run("Sesi sendiri dicabut bersama refresh token", func(t *testing.T) {
+		app, sessionRepo, refreshRepo := setupSessionApp(principal)
+		sessionRepo.On("FindByID", "sess-laptop").Return(&model.Session{ID: "sess-laptop", UserID: principal.UserID}, nil)
+		refreshRepo.On("RevokeFamily", "sess-laptop").Return(nil)
+		sessionRepo.On("Revoke", "sess-laptop").Return(nil)
+
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/me/sessions/sess-laptop", nil))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		refreshRepo.AssertCalled(t, "RevokeFamily", "sess-laptop")
+		sessionRepo.AssertCalled(t, "Revoke", "sess-laptop")
+	})
+
+	t.Run("Sesi milik user lain tidak ditemukan", func(t *testing.T) {
+		app, sessionRepo, refreshRepo := setupSessionApp(principal)
+		sessionRepo.On("FindByID", "sess-foreign").Return(&model.Session{ID: "sess-foreign", UserID: primitive.NewObjectID()}, nil)
+
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/me/sessions/sess-foreign", nil))
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		refreshRepo.AssertNotCalled(t, "RevokeFamily", mock.Anything)
+		sessionRepo.AssertNotCalled(t, "Revoke", mock.Anything)
+	})
+}
+
+func TestJWTAuthRejectsRevokedSession(t *testing.T) {
+	user := &model.Users{ID: primitive.NewObjectID(), Role: "user"}
+	userRepo := new(MockUserRepository)
+	userRepo.On("FindByID", user.ID).Return(user, nil)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("FindByName", "user").Return(&model.Role{Name: "user"}, nil)
+
+	revokedAt := time.Now()
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("FindByID", "sess-active").Return(&model.Session{ID: "sess-active", UserID: user.ID, LastSeenAt: time.Now()}, nil)
+	sessionRepo.On("FindByID", "sess-revoked").Return(&model.Session{ID: "sess-revoked", UserID: user.ID, RevokedAt: &revokedAt}, nil)
+
+	app := fiber.New()
+	deps := &middleware.AuthDeps{Users: userRepo, Roles: roleRepo, Sessions: sessionRepo}
+	app.Get("/me", middleware.JWTAuth(deps), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	call := func(sessionID string) int {
+		claims := testClaims(user.ID)
+		claims.SessionID = sessionID
+		signed, _ := config.GetKeySet().Sign(claims)
+
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, call("sess-active"))
+	assert.Equal(t, fiber.StatusUnauthorized, call("sess-revoked"))
+}
+
+func TestAdminRevokeUserSessions(t *testing.T) {
+	app, m := setupUserAdminApp()
+	user := &model.Users{ID: primitive.NewObjectID(), Role: "user"}
+	m.users.On("FindByID", user.ID).Return(user, nil)
+	m.refresh.On("RevokeAllForUser", user.ID).Return(nil)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/admin/users/"+user.ID.Hex()+"/sessions", nil))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	m.sessions.AssertCalled(t, "RevokeAllForUser", user.ID)
+	m.audit.AssertCalled(t, "Create", mock.MatchedBy(func(entry *model.AuditLog) bool {
+		return entry.Action == model.AuditUserSessionsRevoked
+	}))
+}
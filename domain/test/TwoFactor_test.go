@@ -0,0 +1,188 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/config"
+	"Mongo/domain/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) FindByUserID(userID primitive.ObjectID) (*model.TwoFactor, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Save(tf *model.TwoFactor) error {
+	args := m.Called(tf)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Delete(userID primitive.ObjectID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) MarkStepUsed(userID primitive.ObjectID, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(userID primitive.ObjectID, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func postAuthJSON(app *fiber.App, path string, payload interface{}) (int, map[string]interface{}) {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, -1)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// expectChallenge mendaftarkan tiket mfa_token yang dikembalikan langkah
+// pertama login agar bisa ditemukan lagi oleh /login/2fa.
+func expectChallenge(userID primitive.ObjectID, mfaToken string) *model.OneTimeToken {
+	record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposeMFA, ExpiresAt: time.Now().Add(time.Minute)}
+	mockAuthTokenRepo.On("FindByHash", model.TokenPurposeMFA, hashForTest(mfaToken)).Return(record, nil)
+	mockAuthTokenRepo.On("Consume", record.ID).Return(true, nil).Once()
+	mockAuthTokenRepo.On("Consume", record.ID).Return(false, nil)
+	return record
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	secret := "JBSWY3DPEHPK3PXP"
+
+	setup := func() (*fiber.App, *model.Users) {
+		app, mockRepo := setupTestApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "user@example.com", Password: string(hashed), Role: "user"}
+		mockRepo.On("FindByEmail", user.Email).Return(user, nil)
+		mockRepo.On("FindByID", user.ID).Return(user, nil)
+
+		mockTwoFactorRepo.ExpectedCalls = nil
+		mockTwoFactorRepo.On("FindByUserID", user.ID).Return(&model.TwoFactor{UserID: user.ID, Secret: secret, Enabled: true}, nil)
+		return app, user
+	}
+
+	t.Run("Password Saja Tidak Menghasilkan Token", func(t *testing.T) {
+		app, _ := setup()
+
+		status, result := postAuthJSON(app, "/login", model.Login{Email: "user@example.com", Password: "secret123"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, true, result["mfa_required"])
+		assert.NotEmpty(t, result["mfa_token"])
+		assert.NotContains(t, result, "token")
+	})
+
+	t.Run("Kode TOTP Menyelesaikan Login Sekali Saja", func(t *testing.T) {
+		app, user := setup()
+		_, first := postAuthJSON(app, "/login", model.Login{Email: user.Email, Password: "secret123"})
+		mfaToken, _ := first["mfa_token"].(string)
+		expectChallenge(user.ID, mfaToken)
+		mockTwoFactorRepo.On("MarkStepUsed", user.ID, mock.AnythingOfType("int64")).Return(true, nil)
+
+		code, _ := totp.GenerateCode(secret, time.Now())
+		status, result := postAuthJSON(app, "/login/2fa", model.LoginTwoFactor{MFAToken: mfaToken, Code: code})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.NotEmpty(t, result["token"])
+
+		status, _ = postAuthJSON(app, "/login/2fa", model.LoginTwoFactor{MFAToken: mfaToken, Code: code})
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+
+	t.Run("Kode Salah Dicatat Sebagai Kegagalan", func(t *testing.T) {
+		app, user := setup()
+		_, first := postAuthJSON(app, "/login", model.Login{Email: user.Email, Password: "secret123"})
+		mfaToken, _ := first["mfa_token"].(string)
+		expectChallenge(user.ID, mfaToken)
+
+		status, result := postAuthJSON(app, "/login/2fa", model.LoginTwoFactor{MFAToken: mfaToken, Code: "000000x"})
+
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		assert.Equal(t, "kode 2FA salah", result["error"])
+		mockLoginAttempts.AssertCalled(t, "RecordFailure", "email:user@example.com")
+	})
+
+	t.Run("Kode Pemulihan", func(t *testing.T) {
+		app, user := setup()
+		_, first := postAuthJSON(app, "/login", model.Login{Email: user.Email, Password: "secret123"})
+		mfaToken, _ := first["mfa_token"].(string)
+		expectChallenge(user.ID, mfaToken)
+		mockTwoFactorRepo.On("UseRecoveryCode", user.ID, hashForTest("abcdefgh")).Return(true, nil)
+
+		status, result := postAuthJSON(app, "/login/2fa", model.LoginTwoFactor{MFAToken: mfaToken, RecoveryCode: "ABCD-EFGH"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.NotEmpty(t, result["token"])
+	})
+}
+
+func TestTwoFactorRequiredForAdmin(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	cfg := testTwoFactorConfig
+	cfg.RequiredForAdmin = true
+
+	app, mockRepo := setupTestAppWithTwoFactor(cfg)
+	admin := &model.Users{ID: primitive.NewObjectID(), Email: "admin@example.com", Password: string(hashed), Role: "admin"}
+	mockRepo.On("FindByEmail", admin.Email).Return(admin, nil)
+	mockRepo.On("FindByID", admin.ID).Return(admin, nil)
+
+	// Belum terdaftar sampai setup disimpan; setelah itu setup tertunda dibaca ulang
+	pending := &model.TwoFactor{}
+	mockTwoFactorRepo.ExpectedCalls = nil
+	mockTwoFactorRepo.On("FindByUserID", admin.ID).Return(nil, nil).Times(2)
+	mockTwoFactorRepo.On("FindByUserID", admin.ID).Return(pending, nil)
+	mockTwoFactorRepo.On("Save", mock.AnythingOfType("*model.TwoFactor")).Run(func(args mock.Arguments) {
+		*pending = *args.Get(0).(*model.TwoFactor)
+	}).Return(nil)
+
+	status, first := postAuthJSON(app, "/login", model.Login{Email: admin.Email, Password: "secret123"})
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, true, first["mfa_setup_required"])
+	assert.NotContains(t, first, "token")
+	mfaToken, _ := first["mfa_token"].(string)
+	expectChallenge(admin.ID, mfaToken)
+
+	status, setup := postAuthJSON(app, "/login/2fa/setup", model.LoginTwoFactor{MFAToken: mfaToken})
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Contains(t, setup["otpauth_uri"], "otpauth://totp/")
+	secret, _ := setup["secret"].(string)
+
+	code, _ := totp.GenerateCode(secret, time.Now())
+	status, result := postAuthJSON(app, "/login/2fa", model.LoginTwoFactor{MFAToken: mfaToken, Code: code})
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.NotEmpty(t, result["token"])
+	assert.Len(t, result["recovery_codes"], 10)
+	assert.True(t, pending.Enabled)
+	assert.Len(t, pending.RecoveryCodes, 10)
+}
+
+func TestTwoFactorConfigFromEnv(t *testing.T) {
+	t.Setenv("TWO_FACTOR_REQUIRED_FOR_ADMIN", "true")
+	assert.True(t, config.GetTwoFactorConfig().RequiredForAdmin)
+}
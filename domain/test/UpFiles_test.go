@@ -1,6 +1,7 @@
 package test
 
 import (
+	"Mongo/domain/middleware"
 	"Mongo/domain/model"
 	"Mongo/domain/service"
 	"bytes"
@@ -34,6 +35,11 @@ func (m *MockUploadsRepository) FindAll() ([]model.Uploads, error) {
 	return args.Get(0).([]model.Uploads), args.Error(1)
 }
 
+func (m *MockUploadsRepository) FindByUploader(userID primitive.ObjectID) ([]model.Uploads, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.Uploads), args.Error(1)
+}
+
 func (m *MockUploadsRepository) FindByID(id string) (*model.Uploads, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -47,8 +53,20 @@ func (m *MockUploadsRepository) Delete(id string) error {
 	return args.Error(0)
 }
 
+// fileOwner adalah user biasa yang mengunggah file di test ini.
+var fileOwner = &model.Principal{UserID: primitive.NewObjectID(), Role: "user", Permissions: []string{model.PermFilesRead, model.PermFilesWrite}}
+
 func createTestApp() *fiber.App {
-	return fiber.New()
+	return createTestAppAs(fileOwner)
+}
+
+func createTestAppAs(principal *model.Principal) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, principal)
+		return c.Next()
+	})
+	return app
 }
 
 func TestUploadFile_Success(t *testing.T) {
@@ -59,14 +77,16 @@ func TestUploadFile_Success(t *testing.T) {
 
 	app.Post("/upload", service.UploadFile)
 
-	mockRepo.On("Create", mock.AnythingOfType("*model.Uploads")).Return(nil)
+	mockRepo.On("Create", mock.MatchedBy(func(u *model.Uploads) bool {
+		return u.UploadedBy == fileOwner.UserID
+	})).Return(nil)
 
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", "test.jpg")
 	h := make(textproto.MIMEHeader)
 	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, "test.jpg"))
 	h.Set("Content-Type", "image/jpeg")
+	part, _ := writer.CreatePart(h)
 	part.Write([]byte("dummy image content"))
 	writer.WriteField("other_field", "some_value")
 	writer.Close()
@@ -135,7 +155,7 @@ func TestGetAllFiles_Success(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindAll").Return(dummyFiles, nil)
+	mockRepo.On("FindByUploader", fileOwner.UserID).Return(dummyFiles, nil)
 
 	req := httptest.NewRequest("GET", "/files", nil)
 	resp, err := app.Test(req)
@@ -160,6 +180,7 @@ func TestGetFileByID_Success(t *testing.T) {
 	dummyFile := &model.Uploads{
 		ID:           objID,
 		OriginalName: "found.jpg",
+		UploadedBy:   fileOwner.UserID,
 	}
 
 	mockRepo.On("FindByID", objID.Hex()).Return(dummyFile, nil)
@@ -201,6 +222,7 @@ func TestDeleteFile_Success(t *testing.T) {
 	dummyFile := &model.Uploads{
 		ID:          objID,
 		UploadsPath: filePath,
+		UploadedBy:  fileOwner.UserID,
 	}
 
 	mockRepo.On("FindByID", objID.Hex()).Return(dummyFile, nil)
@@ -216,3 +238,55 @@ func TestDeleteFile_Success(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestFileAccessLimitedToOwnerAndReviewer(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "ijazah.pdf")
+	os.WriteFile(filePath, []byte("isi ijazah"), 0644)
+
+	objID := primitive.NewObjectID()
+	ijazah := &model.Uploads{ID: objID, OriginalName: "ijazah.pdf", UploadsPath: filePath, UploadedBy: fileOwner.UserID}
+	mockRepo := new(MockUploadsRepository)
+	mockRepo.On("FindByID", objID.Hex()).Return(ijazah, nil)
+	mockRepo.On("FindAll").Return([]model.Uploads{*ijazah}, nil)
+	svc := service.NewUploadsService(mockRepo, tempDir)
+
+	get := func(principal *model.Principal, path string) (int, string) {
+		app := createTestAppAs(principal)
+		app.Get("/files", svc.GetAllFiles)
+		app.Get("/files/:id", svc.GetFileByID)
+		app.Get("/files/:id/download", svc.DownloadFile)
+		resp, _ := app.Test(httptest.NewRequest("GET", path, nil))
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("User lain tidak bisa membaca atau mengunduh", func(t *testing.T) {
+		stranger := &model.Principal{UserID: primitive.NewObjectID(), Role: "user", Permissions: []string{model.PermFilesRead}}
+		mockRepo.On("FindByUploader", stranger.UserID).Return([]model.Uploads{}, nil)
+
+		status, _ := get(stranger, "/files/"+objID.Hex())
+		assert.Equal(t, fiber.StatusNotFound, status)
+		status, body := get(stranger, "/files/"+objID.Hex()+"/download")
+		assert.Equal(t, fiber.StatusNotFound, status)
+		assert.NotContains(t, body, "isi ijazah")
+		_, body = get(stranger, "/files")
+		assert.NotContains(t, body, "ijazah.pdf")
+		mockRepo.AssertNotCalled(t, "FindAll")
+	})
+
+	t.Run("Pemilik bisa mengunduh", func(t *testing.T) {
+		status, body := get(fileOwner, "/files/"+objID.Hex()+"/download")
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "isi ijazah", body)
+	})
+
+	t.Run("Peninjau klaim melihat semua file", func(t *testing.T) {
+		reviewer := &model.Principal{UserID: primitive.NewObjectID(), Role: "staf", Permissions: []string{model.PermFilesRead, model.PermAlumniClaimsReview}}
+
+		status, _ := get(reviewer, "/files/"+objID.Hex())
+		assert.Equal(t, fiber.StatusOK, status)
+		_, body := get(reviewer, "/files")
+		assert.Contains(t, body, "ijazah.pdf")
+	})
+}
@@ -0,0 +1,386 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(entry *model.AuditLog) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+type userAdminMocks struct {
+	users    *MockUserRepository
+	roles    *MockRoleRepository
+	refresh  *MockRefreshTokenRepository
+	sessions *MockSessionRepository
+	tokens   *MockOneTimeTokenRepository
+	audit    *MockAuditRepository
+	alumni   *MockAlumniRepository
+}
+
+var adminActor = &model.Principal{UserID: primitive.NewObjectID(), Role: "admin", Permissions: []string{model.PermAll}}
+
+func setupUserAdminApp() (*fiber.App, *userAdminMocks) {
+	return setupUserAdminAppAs(adminActor)
+}
+
+// setupUserAdminAppAs sama dengan setupUserAdminApp tetapi dengan pemanggil
+// tertentu, misalnya pengelola user tanpa wildcard.
+func setupUserAdminAppAs(actor *model.Principal) (*fiber.App, *userAdminMocks) {
+	m := &userAdminMocks{
+		users:    new(MockUserRepository),
+		roles:    new(MockRoleRepository),
+		refresh:  new(MockRefreshTokenRepository),
+		sessions: new(MockSessionRepository),
+		tokens:   new(MockOneTimeTokenRepository),
+		audit:    new(MockAuditRepository),
+		alumni:   new(MockAlumniRepository),
+	}
+	m.tokens.On("InvalidateForUser", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.sessions.On("RevokeAllForUser", mock.Anything).Return(nil).Maybe()
+	m.audit.On("Create", mock.AnythingOfType("*model.AuditLog")).Return(nil).Maybe()
+	m.users.On("LockAdminChanges").Return(func() {}, nil).Maybe()
+
+	svc := service.NewUserAdminService(m.users, m.roles, service.NewSessionManager(m.sessions, m.refresh), m.tokens, m.audit, m.alumni)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		middleware.SetPrincipal(c, actor)
+		return c.Next()
+	})
+	app.Get("/admin/users/:id", svc.GetUserHandler())
+	app.Put("/admin/users/:id", svc.UpdateUserHandler())
+	app.Put("/admin/users/:id/role", svc.ChangeRoleHandler())
+	app.Post("/admin/users/:id/suspend", svc.SuspendUserHandler())
+	app.Post("/admin/users/:id/reactivate", svc.ReactivateUserHandler())
+	app.Delete("/admin/users/:id", svc.DeleteUserHandler())
+	app.Get("/admin/users/:id/sessions", svc.ListSessionsHandler())
+	app.Delete("/admin/users/:id/sessions", svc.RevokeSessionsHandler())
+
+	return app, m
+}
+
+func adminRequest(app *fiber.App, method, path string, payload interface{}) (int, map[string]interface{}) {
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func auditedAction(m *MockAuditRepository, action string) bool {
+	for _, call := range m.Calls {
+		if entry, ok := call.Arguments.Get(0).(*model.AuditLog); ok && entry.Action == action && entry.ActorID == adminActor.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetUserHandler(t *testing.T) {
+	app, m := setupUserAdminApp()
+	user := &model.Users{ID: primitive.NewObjectID(), Email: "a@example.com", Password: "hash", Role: "user"}
+	m.users.On("FindByID", user.ID).Return(user, nil)
+
+	status, result := adminRequest(app, "GET", "/admin/users/"+user.ID.Hex(), nil)
+
+	assert.Equal(t, fiber.StatusOK, status)
+	body, _ := result["user"].(map[string]interface{})
+	assert.Equal(t, "a@example.com", body["email"])
+	assert.NotContains(t, body, "password")
+
+	status, _ = adminRequest(app, "GET", "/admin/users/not-an-id", nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestUpdateUserHandler(t *testing.T) {
+	t.Run("Email Sudah Dipakai", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "a@example.com", Username: "a"}
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("FindByEmail", "b@example.com").Return(&model.Users{ID: primitive.NewObjectID()}, nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex(), model.UpdateUser{Email: "b@example.com"})
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.users.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Email Ganda Dari Unique Index", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "a@example.com", Username: "a"}
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("FindByEmail", "b@example.com").Return(nil, nil)
+		m.users.On("Update", mock.Anything).Return(duplicateKeyError())
+
+		status, result := adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex(), model.UpdateUser{Email: "b@example.com"})
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		assert.Equal(t, "email sudah terdaftar", result["error"])
+	})
+
+	t.Run("Sukses Dan Tercatat Di Audit", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "a@example.com", Username: "a"}
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.users.On("Update", user).Return(nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex(), model.UpdateUser{Username: "renamed"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "renamed", user.Username)
+		assert.True(t, auditedAction(m.audit, model.AuditUserUpdated))
+	})
+}
+
+func TestChangeRoleHandler(t *testing.T) {
+	t.Run("Menolak Menurunkan Admin Terakhir", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		admin := &model.Users{ID: primitive.NewObjectID(), Role: "admin"}
+		m.users.On("FindByID", admin.ID).Return(admin, nil)
+		m.roles.On("FindByName", "user").Return(&model.Role{Name: "user"}, nil)
+		m.users.On("CountActiveByRole", "admin").Return(1, nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+admin.ID.Hex()+"/role", model.ChangeRole{Role: "user"})
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.users.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Role Tidak Dikenal", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Role: "user"}
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.roles.On("FindByName", "ghost").Return(nil, nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex()+"/role", model.ChangeRole{Role: "ghost"})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("Sukses Menaikkan User Menjadi Admin", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Role: "user"}
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.roles.On("FindByName", "admin").Return(&model.Role{Name: "admin"}, nil)
+		m.users.On("UpdateRole", user.ID, "admin").Return(nil)
+		m.refresh.On("RevokeAllForUser", user.ID).Return(nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex()+"/role", model.ChangeRole{Role: "Admin"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "admin", user.Role)
+		assert.True(t, auditedAction(m.audit, model.AuditUserRoleChanged))
+		m.refresh.AssertCalled(t, "RevokeAllForUser", user.ID)
+	})
+
+	t.Run("Menolak Role Dengan Permission Melebihi Pemanggil", func(t *testing.T) {
+		manager := &model.Principal{UserID: primitive.NewObjectID(), Role: "operator", Permissions: []string{model.PermUsersManage, model.PermAlumniRead}}
+		app, m := setupUserAdminAppAs(manager)
+		user := &model.Users{ID: primitive.NewObjectID(), Role: "user"}
+		m.users.On("FindByID", user.ID).Return(user, nil)
+		m.roles.On("FindByName", "admin").Return(&model.Role{Name: "admin", Permissions: []string{model.PermAll}}, nil)
+		m.roles.On("FindByName", "pembaca").Return(&model.Role{Name: "pembaca", Permissions: []string{model.PermAlumniRead}}, nil)
+		m.users.On("UpdateRole", user.ID, "pembaca").Return(nil)
+		m.refresh.On("RevokeAllForUser", user.ID).Return(nil)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex()+"/role", model.ChangeRole{Role: "admin"})
+		assert.Equal(t, fiber.StatusForbidden, status)
+		m.users.AssertNotCalled(t, "UpdateRole", user.ID, "admin")
+
+		// Role yang permission-nya dimiliki pemanggil tetap boleh diberikan
+		status, _ = adminRequest(app, "PUT", "/admin/users/"+user.ID.Hex()+"/role", model.ChangeRole{Role: "pembaca"})
+		assert.Equal(t, fiber.StatusOK, status)
+	})
+
+	t.Run("Menolak Saat Perubahan Admin Lain Berjalan", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		admin := &model.Users{ID: primitive.NewObjectID(), Role: "admin"}
+		m.users.On("FindByID", admin.ID).Return(admin, nil)
+		m.roles.On("FindByName", "user").Return(&model.Role{Name: "user"}, nil)
+		m.users.ExpectedCalls = filterCalls(m.users.ExpectedCalls, "LockAdminChanges")
+		m.users.On("LockAdminChanges").Return(nil, model.ErrAdminChangeBusy)
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+admin.ID.Hex()+"/role", model.ChangeRole{Role: "user"})
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.users.AssertNotCalled(t, "CountActiveByRole", mock.Anything)
+		m.users.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Kunci Dipegang Sampai Perubahan Tersimpan", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		admin := &model.Users{ID: primitive.NewObjectID(), Role: "admin"}
+		m.users.On("FindByID", admin.ID).Return(admin, nil)
+		m.roles.On("FindByName", "user").Return(&model.Role{Name: "user"}, nil)
+		m.users.On("CountActiveByRole", "admin").Return(2, nil)
+		m.refresh.On("RevokeAllForUser", admin.ID).Return(nil)
+
+		var events []string
+		m.users.ExpectedCalls = filterCalls(m.users.ExpectedCalls, "LockAdminChanges")
+		m.users.On("LockAdminChanges").Return(func() { events = append(events, "unlock") }, nil)
+		m.users.On("UpdateRole", admin.ID, "user").Return(nil).Run(func(mock.Arguments) { events = append(events, "update") })
+
+		status, _ := adminRequest(app, "PUT", "/admin/users/"+admin.ID.Hex()+"/role", model.ChangeRole{Role: "user"})
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, []string{"update", "unlock"}, events)
+	})
+}
+
+// filterCalls membuang ekspektasi bawaan untuk method tertentu agar subtest
+// bisa memasang perilakunya sendiri.
+func filterCalls(calls []*mock.Call, method string) []*mock.Call {
+	var kept []*mock.Call
+	for _, call := range calls {
+		if call.Method != method {
+			kept = append(kept, call)
+		}
+	}
+	return kept
+}
+
+func TestDemotedAdminTokenLosesPermissions(t *testing.T) {
+	app, m := setupUserAdminApp()
+	admin := &model.Users{ID: primitive.NewObjectID(), Username: "admin-lama", Role: "admin", Status: model.UserStatusActive}
+	m.users.On("FindByID", admin.ID).Return(admin, nil)
+	m.users.On("CountActiveByRole", "admin").Return(2, nil)
+	m.users.On("UpdateRole", admin.ID, "user").Return(nil)
+	m.refresh.On("RevokeAllForUser", admin.ID).Return(nil)
+	m.roles.On("FindByName", "user").Return(&model.Role{Name: "user", Permissions: []string{model.PermAlumniRead}}, nil)
+	m.roles.On("FindByName", "admin").Return(&model.Role{Name: "admin", Permissions: []string{model.PermAll}}, nil)
+
+	// Token diterbitkan saat user masih admin
+	claims := testClaims(admin.ID)
+	claims.Role = "admin"
+	token, err := config.GetKeySet().Sign(claims)
+	assert.NoError(t, err)
+
+	deps := &middleware.AuthDeps{Users: m.users, Roles: m.roles}
+	protected := fiber.New()
+	protected.Get("/admin/users", middleware.JWTAuth(deps), middleware.RequirePermission(model.PermUsersManage), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	call := func() int {
+		req := httptest.NewRequest("GET", "/admin/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, _ := protected.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, call())
+
+	status, _ := adminRequest(app, "PUT", "/admin/users/"+admin.ID.Hex()+"/role", model.ChangeRole{Role: "user"})
+	assert.Equal(t, fiber.StatusOK, status)
+	m.refresh.AssertCalled(t, "RevokeAllForUser", admin.ID)
+
+	// Token lama membawa klaim role admin, tapi permission dibaca dari role di database
+	assert.Equal(t, fiber.StatusForbidden, call())
+}
+
+func TestSuspendAndReactivateUser(t *testing.T) {
+	app, m := setupUserAdminApp()
+	verifiedAt := time.Now()
+	user := &model.Users{ID: primitive.NewObjectID(), Role: "user", Status: model.UserStatusActive, VerifiedAt: &verifiedAt}
+	m.users.On("FindByID", user.ID).Return(user, nil)
+	m.users.On("UpdateStatus", user.ID, model.UserStatusSuspended).Return(nil)
+	m.users.On("UpdateStatus", user.ID, model.UserStatusActive).Return(nil)
+	m.refresh.On("RevokeAllForUser", user.ID).Return(nil)
+
+	status, _ := adminRequest(app, "POST", "/admin/users/"+user.ID.Hex()+"/suspend", nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, user.IsSuspended())
+	m.refresh.AssertCalled(t, "RevokeAllForUser", user.ID)
+	m.tokens.AssertCalled(t, "InvalidateForUser", user.ID, model.TokenPurposeEmailVerification)
+	assert.True(t, auditedAction(m.audit, model.AuditUserSuspended))
+
+	status, _ = adminRequest(app, "POST", "/admin/users/"+user.ID.Hex()+"/reactivate", nil)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, model.UserStatusActive, user.Status)
+	assert.True(t, auditedAction(m.audit, model.AuditUserReactivated))
+
+	status, _ = adminRequest(app, "POST", "/admin/users/"+user.ID.Hex()+"/reactivate", nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	// Hanya status yang ditulis, bukan seluruh dokumen user
+	m.users.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestReactivateUnverifiedUser(t *testing.T) {
+	app, m := setupUserAdminApp()
+	user := &model.Users{ID: primitive.NewObjectID(), Role: "user", Status: model.UserStatusSuspended}
+	m.users.On("FindByID", user.ID).Return(user, nil)
+	m.users.On("UpdateStatus", user.ID, model.UserStatusUnverified).Return(nil)
+
+	status, _ := adminRequest(app, "POST", "/admin/users/"+user.ID.Hex()+"/reactivate", nil)
+
+	// Email yang belum pernah diverifikasi tetap harus diverifikasi
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, model.UserStatusUnverified, user.Status)
+	m.users.AssertNotCalled(t, "UpdateStatus", user.ID, model.UserStatusActive)
+}
+
+func TestDeleteUserHandler(t *testing.T) {
+	t.Run("Menolak Menghapus Admin Terakhir", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		admin := &model.Users{ID: primitive.NewObjectID(), Role: "admin"}
+		m.users.On("FindByID", admin.ID).Return(admin, nil)
+		m.users.On("CountActiveByRole", "admin").Return(1, nil)
+
+		status, _ := adminRequest(app, "DELETE", "/admin/users/"+admin.ID.Hex(), nil)
+
+		assert.Equal(t, fiber.StatusConflict, status)
+		m.users.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("Menolak Menghapus Akun Sendiri", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		self := &model.Users{ID: adminActor.UserID, Role: "admin"}
+		m.users.On("FindByID", self.ID).Return(self, nil)
+
+		status, _ := adminRequest(app, "DELETE", "/admin/users/"+self.ID.Hex(), nil)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("Sukses Menghapus Admin Jika Masih Ada Admin Lain", func(t *testing.T) {
+		app, m := setupUserAdminApp()
+		admin := &model.Users{ID: primitive.NewObjectID(), Role: "admin"}
+		m.users.On("FindByID", admin.ID).Return(admin, nil)
+		m.users.On("CountActiveByRole", "admin").Return(2, nil)
+		m.users.On("Delete", admin.ID).Return(nil)
+		m.refresh.On("RevokeAllForUser", admin.ID).Return(nil)
+		m.alumni.On("UnlinkUser", admin.ID).Return(nil)
+
+		status, _ := adminRequest(app, "DELETE", "/admin/users/"+admin.ID.Hex(), nil)
+
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.True(t, auditedAction(m.audit, model.AuditUserDeleted))
+		// NIM yang tertaut ke akun ini bisa diklaim lagi
+		m.alumni.AssertCalled(t, "UnlinkUser", admin.ID)
+	})
+}
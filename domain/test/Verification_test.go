@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Mongo/domain/mailer"
+	"Mongo/domain/model"
+	"Mongo/domain/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupVerificationApp() (*fiber.App, *MockUserRepository, *MockOneTimeTokenRepository, *MockMailer) {
+	userRepo := new(MockUserRepository)
+	tokenRepo := new(MockOneTimeTokenRepository)
+	m := new(MockMailer)
+
+	verificationService := service.NewVerificationService(userRepo, tokenRepo, m)
+
+	app := fiber.New()
+	app.Get("/email/verify", verificationService.VerifyEmailHandler())
+	app.Post("/email/verify", verificationService.VerifyEmailHandler())
+	app.Post("/admin/users/:id/verification", verificationService.ResendVerificationHandler())
+	app.Post("/admin/users/:id/verify", verificationService.VerifyUserHandler())
+
+	return app, userRepo, tokenRepo, m
+}
+
+func TestVerifyEmailHandler(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	t.Run("Sukses - Tautan Dari Email", func(t *testing.T) {
+		app, userRepo, tokenRepo, _ := setupVerificationApp()
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposeEmailVerification, ExpiresAt: time.Now().Add(time.Hour)}
+		user := &model.Users{ID: userID, Email: "new@example.com", Status: model.UserStatusUnverified}
+
+		tokenRepo.On("FindByHash", model.TokenPurposeEmailVerification, hashForTest("verify-me")).Return(record, nil)
+		tokenRepo.On("Consume", record.ID).Return(true, nil)
+		userRepo.On("FindByID", userID).Return(user, nil)
+		userRepo.On("Update", user).Return(nil)
+
+		req := httptest.NewRequest("GET", "/email/verify?token=verify-me", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, model.UserStatusActive, user.Status)
+		assert.NotNil(t, user.VerifiedAt)
+	})
+
+	t.Run("Sukses - Email Baru Menggantikan Email Lama", func(t *testing.T) {
+		app, userRepo, tokenRepo, _ := setupVerificationApp()
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposeEmailVerification, Email: "new@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+		user := &model.Users{ID: userID, Email: "old@example.com", PendingEmail: "new@example.com", Status: model.UserStatusActive}
+
+		tokenRepo.On("FindByHash", model.TokenPurposeEmailVerification, hashForTest("change-me")).Return(record, nil)
+		tokenRepo.On("Consume", record.ID).Return(true, nil)
+		userRepo.On("FindByID", userID).Return(user, nil)
+		userRepo.On("FindByEmail", "new@example.com").Return(nil, nil)
+		userRepo.On("Update", user).Return(nil)
+
+		req := httptest.NewRequest("GET", "/email/verify?token=change-me", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.Empty(t, user.PendingEmail)
+		assert.Equal(t, model.UserStatusActive, user.Status)
+	})
+
+	t.Run("Gagal - Perubahan Email Sudah Dibatalkan", func(t *testing.T) {
+		app, userRepo, tokenRepo, _ := setupVerificationApp()
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposeEmailVerification, Email: "typo@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+		user := &model.Users{ID: userID, Email: "old@example.com", Status: model.UserStatusActive}
+
+		tokenRepo.On("FindByHash", model.TokenPurposeEmailVerification, hashForTest("cancelled")).Return(record, nil)
+		userRepo.On("FindByID", userID).Return(user, nil)
+
+		req := httptest.NewRequest("GET", "/email/verify?token=cancelled", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "old@example.com", user.Email)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Gagal - Akun Dinonaktifkan", func(t *testing.T) {
+		app, userRepo, tokenRepo, _ := setupVerificationApp()
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposeEmailVerification, ExpiresAt: time.Now().Add(time.Hour)}
+		user := &model.Users{ID: userID, Email: "new@example.com", Status: model.UserStatusSuspended}
+
+		tokenRepo.On("FindByHash", model.TokenPurposeEmailVerification, hashForTest("old-link")).Return(record, nil)
+		userRepo.On("FindByID", userID).Return(user, nil)
+
+		req := httptest.NewRequest("GET", "/email/verify?token=old-link", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, model.UserStatusSuspended, user.Status)
+		tokenRepo.AssertNotCalled(t, "Consume", mock.Anything)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Gagal - Token Kedaluwarsa", func(t *testing.T) {
+		app, userRepo, tokenRepo, _ := setupVerificationApp()
+		record := &model.OneTimeToken{ID: primitive.NewObjectID(), UserID: userID, Purpose: model.TokenPurposeEmailVerification, ExpiresAt: time.Now().Add(-time.Minute)}
+		tokenRepo.On("FindByHash", model.TokenPurposeEmailVerification, hashForTest("stale")).Return(record, nil)
+
+		body, _ := json.Marshal(model.VerifyEmail{Token: "stale"})
+		req := httptest.NewRequest("POST", "/email/verify", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestAdminVerificationHandlers(t *testing.T) {
+	t.Run("Kirim Ulang - User Belum Terverifikasi", func(t *testing.T) {
+		app, userRepo, tokenRepo, m := setupVerificationApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Email: "pending@example.com", Status: model.UserStatusUnverified}
+		userRepo.On("FindByID", user.ID).Return(user, nil)
+		tokenRepo.On("InvalidateForUser", user.ID, model.TokenPurposeEmailVerification).Return(nil)
+		tokenRepo.On("Create", mock.AnythingOfType("*model.OneTimeToken")).Return(nil)
+		m.On("Send", mock.MatchedBy(func(msg mailer.Message) bool { return msg.To == user.Email })).Return(nil)
+
+		req := httptest.NewRequest("POST", "/admin/users/"+user.ID.Hex()+"/verification", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Kirim Ulang - User Sudah Terverifikasi", func(t *testing.T) {
+		app, userRepo, _, m := setupVerificationApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Status: model.UserStatusActive}
+		userRepo.On("FindByID", user.ID).Return(user, nil)
+
+		req := httptest.NewRequest("POST", "/admin/users/"+user.ID.Hex()+"/verification", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		m.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("Verifikasi Manual", func(t *testing.T) {
+		app, userRepo, tokenRepo, _ := setupVerificationApp()
+		user := &model.Users{ID: primitive.NewObjectID(), Status: model.UserStatusUnverified}
+		userRepo.On("FindByID", user.ID).Return(user, nil)
+		userRepo.On("Update", user).Return(nil)
+		tokenRepo.On("InvalidateForUser", user.ID, model.TokenPurposeEmailVerification).Return(nil)
+
+		req := httptest.NewRequest("POST", "/admin/users/"+user.ID.Hex()+"/verify", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.True(t, user.IsVerified())
+	})
+
+	t.Run("User Tidak Ditemukan", func(t *testing.T) {
+		app, userRepo, _, _ := setupVerificationApp()
+		id := primitive.NewObjectID()
+		userRepo.On("FindByID", id).Return(nil, nil)
+
+		req := httptest.NewRequest("POST", "/admin/users/"+id.Hex()+"/verify", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
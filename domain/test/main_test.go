@@ -0,0 +1,32 @@
+package test
+
+import (
+	"Mongo/domain/config"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestMain memasang klien MongoDB yang tidak pernah terhubung, sehingga
+// handler yang masih memakai config.DB mendapat error biasa, bukan panic.
+// Cost bcrypt disamakan dengan hash bcrypt.MinCost di fixture agar login
+// tidak memicu rehash. JWT_SECRET diisi karena tidak ada secret bawaan.
+func TestMain(m *testing.M) {
+	os.Setenv("BCRYPT_COST", "4")
+	os.Setenv("JWT_SECRET", "secret-khusus-test")
+
+	opts := options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(100 * time.Millisecond)
+
+	client, err := mongo.Connect(context.Background(), opts)
+	if err == nil {
+		config.DB = client
+	}
+
+	os.Exit(m.Run())
+}
@@ -1,7 +1,10 @@
 package test
 
 import (
+	"Mongo/domain/config"
+	"Mongo/domain/middleware"
 	"Mongo/domain/model"
+	"Mongo/domain/routes"
 	"Mongo/domain/service"
 	"bytes"
 	"encoding/json"
@@ -10,15 +13,107 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
-func setupApp() *fiber.App {
-    app := fiber.New()
-    return app
+
+type MockPekerjaanAlumniRepository struct {
+	mock.Mock
+}
+
+func (m *MockPekerjaanAlumniRepository) FindByID(id string) (*model.PekerjaanAlumni, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PekerjaanAlumni), args.Error(1)
+}
+
+func (m *MockPekerjaanAlumniRepository) Create(pekerjaan *model.PekerjaanAlumni) error {
+	args := m.Called(pekerjaan)
+	return args.Error(0)
+}
+
+func (m *MockPekerjaanAlumniRepository) Update(id string, pekerjaan *model.PekerjaanAlumni) error {
+	args := m.Called(id, pekerjaan)
+	return args.Error(0)
+}
+
+func (m *MockPekerjaanAlumniRepository) FindAll() ([]model.PekerjaanAlumni, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PekerjaanAlumni), args.Error(1)
+}
+
+func (m *MockPekerjaanAlumniRepository) OpenCursor() (model.Cursor, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(model.Cursor), args.Error(1)
+}
+
+func (m *MockPekerjaanAlumniRepository) SoftDeleteByNIM(nim string) error {
+	args := m.Called(nim)
+	return args.Error(0)
+}
+
+func (m *MockPekerjaanAlumniRepository) FindTrash(nim string) ([]*model.Trash, error) {
+	args := m.Called(nim)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Trash), args.Error(1)
+}
+
+func (m *MockPekerjaanAlumniRepository) RestoreByNIM(nim string) error {
+	args := m.Called(nim)
+	return args.Error(0)
+}
+
+func (m *MockPekerjaanAlumniRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+var (
+	userPrincipal  = &model.Principal{UserID: primitive.NewObjectID(), Role: "user"}
+	adminPrincipal = &model.Principal{UserID: primitive.NewObjectID(), Role: "admin", Permissions: []string{model.PermAll}}
+)
+
+// setupPekerjaanApp memasang principal (jika ada) lalu mendaftarkan route
+// pekerjaan dengan repository tiruan. User biasa ditautkan ke NIM 1001.
+func setupPekerjaanApp(principal *model.Principal) (*fiber.App, *MockPekerjaanAlumniRepository, *MockAlumniRepository) {
+	pekerjaanRepo := new(MockPekerjaanAlumniRepository)
+	alumniRepo := new(MockAlumniRepository)
+	alumniRepo.On("FindByUserID", userPrincipal.UserID).Return(&model.Alumni{NIM: "1001", UserID: userPrincipal.UserID}, nil).Maybe()
+
+	svc := service.NewPekerjaanAlumniService(pekerjaanRepo, service.NewOwnershipPolicy(alumniRepo))
+
+	app := fiber.New()
+	if principal != nil {
+		app.Use(func(c *fiber.Ctx) error {
+			middleware.SetPrincipal(c, principal)
+			return c.Next()
+		})
+	}
+	app.Post("/api/pekerjaan", svc.CreatepekerjaanAlumniService)
+	app.Get("/api/pekerjaan/export", svc.ExportPekerjaanAlumniService)
+	app.Get("/api/pekerjaan/:id", svc.CheckpekerjaanAlumniService)
+	app.Put("/api/pekerjaan/:id", svc.UpdatepekerjaanAlumniService)
+	app.Put("/api/softdeleted/:id", svc.SoftDeleteBynimService)
+	app.Put("/api/restore/:id", svc.RestoreBynimService)
+	app.Get("/api/trash", svc.GetAllTrashService)
+	app.Delete("/api/pekerjaan/:id/purge", svc.DeletePekerjaanAlumniService)
+
+	return app, pekerjaanRepo, alumniRepo
 }
 
 func TestCreatepekerjaanAlumniService_ValidationError(t *testing.T) {
-    app := setupApp()
-    app.Post("/api/pekerjaan", service.CreatepekerjaanAlumniService)
+    app, _, _ := setupPekerjaanApp(userPrincipal)
 
     t.Run("Body Kosong/Invalid", func(t *testing.T) {
         req := httptest.NewRequest("POST", "/api/pekerjaan", nil)
@@ -35,29 +130,55 @@ func TestCreatepekerjaanAlumniService_ValidationError(t *testing.T) {
         body, _ := json.Marshal(payload)
         req := httptest.NewRequest("POST", "/api/pekerjaan", bytes.NewReader(body))
         req.Header.Set("Content-Type", "application/json")
-        
+
         resp, _ := app.Test(req)
-        
+
         assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
     })
+
+    t.Run("Gagal - Membuat Pekerjaan Untuk NIM Lain", func(t *testing.T) {
+        body, _ := json.Marshal(model.PekerjaanAlumni{NimAlumni: "2002", StatusKerja: "bekerja"})
+        req := httptest.NewRequest("POST", "/api/pekerjaan", bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+
+        resp, _ := app.Test(req)
+
+        assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+    })
 }
 
 func TestCheckpekerjaanAlumniService_ParamValidation(t *testing.T) {
-    app := setupApp()
-    app.Get("/api/pekerjaan/:id", service.CheckpekerjaanAlumniService)
+    app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+    pekerjaanRepo.On("FindByID", "12345").Return(nil, mongo.ErrNoDocuments)
 
     req := httptest.NewRequest("GET", "/api/pekerjaan/12345", nil)
     resp, _ := app.Test(req)
-    
+
     assert.NotEqual(t, fiber.StatusNotFound, resp.StatusCode)
 }
 
+func TestCheckpekerjaanAlumniService_GajiHiddenFromOthers(t *testing.T) {
+	lookup := func(principal *model.Principal, nim string) map[string]interface{} {
+		app, pekerjaanRepo, _ := setupPekerjaanApp(principal)
+		pekerjaanRepo.On("FindByID", "job").Return(&model.PekerjaanAlumni{ID: primitive.NewObjectID(), NimAlumni: nim, Gaji: 9000000}, nil)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/api/pekerjaan/job", nil))
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		body, _ := result["pekerjaan"].(map[string]interface{})
+		return body
+	}
+
+	assert.NotContains(t, lookup(userPrincipal, "2002"), "gaji")
+	assert.Contains(t, lookup(userPrincipal, "1001"), "gaji")
+	assert.Contains(t, lookup(adminPrincipal, "2002"), "gaji")
+}
+
 func TestSoftDeleteBynimService_Authorization(t *testing.T) {
-    
+
     t.Run("Gagal - Unauthorized (Data Locals Kosong)", func(t *testing.T) {
-        app := fiber.New()
-        app.Put("/api/softdeleted/:id", service.SoftDeleteBynimService)
-        
+        app, _, _ := setupPekerjaanApp(nil)
+
         req := httptest.NewRequest("PUT", "/api/softdeleted/123", nil)
         resp, _ := app.Test(req)
 
@@ -65,51 +186,174 @@ func TestSoftDeleteBynimService_Authorization(t *testing.T) {
     })
 
     t.Run("Gagal - User Mencoba Hapus Punya Orang Lain", func(t *testing.T) {
-        app := fiber.New()
-        
-        app.Use(func(c *fiber.Ctx) error {
-            c.Locals("role", "user")
-            c.Locals("id", 1001)
-            return c.Next()
-        })
-        app.Put("/api/softdeleted/:id", service.SoftDeleteBynimService)
+        app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
 
         req := httptest.NewRequest("PUT", "/api/softdeleted/2002", nil)
         resp, _ := app.Test(req)
 
+        assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+        pekerjaanRepo.AssertNotCalled(t, "SoftDeleteByNIM", mock.Anything)
+    })
+
+    t.Run("Gagal - Akun Belum Tertaut Ke Alumni", func(t *testing.T) {
+        stranger := &model.Principal{UserID: primitive.NewObjectID(), Role: "user"}
+        app, _, alumniRepo := setupPekerjaanApp(stranger)
+        alumniRepo.On("FindByUserID", stranger.UserID).Return(nil, nil)
+
+        req := httptest.NewRequest("PUT", "/api/softdeleted/1001", nil)
+        resp, _ := app.Test(req)
+
         assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
     })
 
     t.Run("Sukses Logic Auth - User Hapus Punya Sendiri", func(t *testing.T) {
-        app := fiber.New()
-        
-        app.Use(func(c *fiber.Ctx) error {
-            c.Locals("role", "user")
-            c.Locals("id", 1001) 
-            return c.Next()
-        })
-        app.Put("/api/softdeleted/:id", service.SoftDeleteBynimService)
+        app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+        pekerjaanRepo.On("SoftDeleteByNIM", "1001").Return(nil)
 
         req := httptest.NewRequest("PUT", "/api/softdeleted/1001", nil)
         resp, _ := app.Test(req)
 
-        assert.NotEqual(t, fiber.StatusForbidden, resp.StatusCode)
-        assert.NotEqual(t, fiber.StatusUnauthorized, resp.StatusCode)
+        assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+        pekerjaanRepo.AssertExpectations(t)
     })
-    
+
     t.Run("Sukses Logic Auth - Admin Bebas Hapus", func(t *testing.T) {
-        app := fiber.New()
-        
-        app.Use(func(c *fiber.Ctx) error {
-            c.Locals("role", "admin")
-            c.Locals("id", 999) 
-            return c.Next()
-        })
-        app.Put("/api/softdeleted/:id", service.SoftDeleteBynimService)
+        app, pekerjaanRepo, alumniRepo := setupPekerjaanApp(adminPrincipal)
+        pekerjaanRepo.On("SoftDeleteByNIM", "randomID").Return(nil)
 
         req := httptest.NewRequest("PUT", "/api/softdeleted/randomID", nil)
         resp, _ := app.Test(req)
 
-        assert.NotEqual(t, fiber.StatusForbidden, resp.StatusCode)
+        assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+        alumniRepo.AssertNotCalled(t, "FindByUserID", mock.Anything)
+    })
+}
+
+func TestRestoreBynimService_Ownership(t *testing.T) {
+    app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+    pekerjaanRepo.On("RestoreByNIM", "1001").Return(nil)
+
+    req := httptest.NewRequest("PUT", "/api/restore/2002", nil)
+    resp, _ := app.Test(req)
+    assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+    req = httptest.NewRequest("PUT", "/api/restore/1001", nil)
+    resp, _ = app.Test(req)
+    assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestDeletePekerjaanAlumniService_Ownership(t *testing.T) {
+    ownID := primitive.NewObjectID()
+    otherID := primitive.NewObjectID()
+
+    app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+    pekerjaanRepo.On("FindByID", ownID.Hex()).Return(&model.PekerjaanAlumni{ID: ownID, NimAlumni: "1001"}, nil)
+    pekerjaanRepo.On("FindByID", otherID.Hex()).Return(&model.PekerjaanAlumni{ID: otherID, NimAlumni: "2002"}, nil)
+    pekerjaanRepo.On("FindByID", "missing").Return(nil, mongo.ErrNoDocuments)
+    pekerjaanRepo.On("Delete", ownID.Hex()).Return(nil)
+
+    t.Run("Gagal - Data Milik Alumni Lain", func(t *testing.T) {
+        req := httptest.NewRequest("DELETE", "/api/pekerjaan/"+otherID.Hex()+"/purge", nil)
+        resp, _ := app.Test(req)
+
+        assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+        pekerjaanRepo.AssertNotCalled(t, "Delete", otherID.Hex())
+    })
+
+    t.Run("Gagal - Data Tidak Ditemukan", func(t *testing.T) {
+        req := httptest.NewRequest("DELETE", "/api/pekerjaan/missing/purge", nil)
+        resp, _ := app.Test(req)
+
+        assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+    })
+
+    t.Run("Sukses - Data Milik Sendiri", func(t *testing.T) {
+        req := httptest.NewRequest("DELETE", "/api/pekerjaan/"+ownID.Hex()+"/purge", nil)
+        resp, _ := app.Test(req)
+
+        assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+    })
+}
+
+// Route purge pekerjaan dan route hapus file dipasang di router yang sama;
+// keduanya harus sampai ke handler masing-masing.
+func TestPurgePekerjaanRoute_NotShadowedByFileRoutes(t *testing.T) {
+    admin := &model.Users{ID: primitive.NewObjectID(), Username: "admin", Role: "admin", Status: model.UserStatusActive}
+    userRepo := new(MockUserRepository)
+    userRepo.On("FindByID", admin.ID).Return(admin, nil)
+    roleRepo := new(MockRoleRepository)
+    roleRepo.On("FindByName", "admin").Return(&model.Role{Name: "admin", Permissions: []string{model.PermAll}}, nil)
+    auth := &middleware.AuthDeps{Users: userRepo, Roles: roleRepo}
+
+    pekerjaanID := primitive.NewObjectID()
+    pekerjaanRepo := new(MockPekerjaanAlumniRepository)
+    pekerjaanRepo.On("FindByID", pekerjaanID.Hex()).Return(&model.PekerjaanAlumni{ID: pekerjaanID, NimAlumni: "1001"}, nil)
+    pekerjaanRepo.On("Delete", pekerjaanID.Hex()).Return(nil)
+    uploadsRepo := new(MockUploadsRepository)
+    uploadsRepo.On("FindByID", "file-1").Return(nil, mongo.ErrNoDocuments)
+
+    app := fiber.New()
+    api := app.Group("/api")
+    routes.SetupFileRoutes(api, auth, service.NewUploadsService(uploadsRepo, t.TempDir()))
+    routes.PekerjaanAlumni(api, auth, service.NewPekerjaanAlumniService(pekerjaanRepo, service.NewOwnershipPolicy(new(MockAlumniRepository))))
+
+    claims := testClaims(admin.ID)
+    claims.Role = "admin"
+    token, err := config.GetKeySet().Sign(claims)
+    assert.NoError(t, err)
+    call := func(path string) int {
+        req := httptest.NewRequest("DELETE", path, nil)
+        req.Header.Set("Authorization", "Bearer "+token)
+        resp, _ := app.Test(req)
+        return resp.StatusCode
+    }
+
+    assert.Equal(t, fiber.StatusOK, call("/api/pekerjaan/"+pekerjaanID.Hex()+"/purge"))
+    pekerjaanRepo.AssertCalled(t, "Delete", pekerjaanID.Hex())
+    uploadsRepo.AssertNotCalled(t, "FindByID", pekerjaanID.Hex())
+
+    call("/api/deleted/file-1")
+    uploadsRepo.AssertCalled(t, "FindByID", "file-1")
+}
+
+func TestUpdatepekerjaanAlumniService_Ownership(t *testing.T) {
+    ownID := primitive.NewObjectID()
+    otherID := primitive.NewObjectID()
+
+    app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+    pekerjaanRepo.On("FindByID", ownID.Hex()).Return(&model.PekerjaanAlumni{ID: ownID, NimAlumni: "1001"}, nil)
+    pekerjaanRepo.On("FindByID", otherID.Hex()).Return(&model.PekerjaanAlumni{ID: otherID, NimAlumni: "2002"}, nil)
+    pekerjaanRepo.On("Update", ownID.Hex(), mock.Anything).Return(nil)
+
+    send := func(id string, payload model.PekerjaanAlumni) int {
+        body, _ := json.Marshal(payload)
+        req := httptest.NewRequest("PUT", "/api/pekerjaan/"+id, bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        resp, _ := app.Test(req)
+        return resp.StatusCode
+    }
+
+    t.Run("Gagal - Data Milik Alumni Lain", func(t *testing.T) {
+        assert.Equal(t, fiber.StatusForbidden, send(otherID.Hex(), model.PekerjaanAlumni{StatusKerja: "bekerja"}))
+    })
+
+    t.Run("Gagal - Memindahkan Ke NIM Lain", func(t *testing.T) {
+        assert.Equal(t, fiber.StatusForbidden, send(ownID.Hex(), model.PekerjaanAlumni{NimAlumni: "2002", StatusKerja: "bekerja"}))
+    })
+
+    t.Run("Sukses - Data Milik Sendiri", func(t *testing.T) {
+        assert.Equal(t, fiber.StatusOK, send(ownID.Hex(), model.PekerjaanAlumni{StatusKerja: "bekerja"}))
+        pekerjaanRepo.AssertCalled(t, "Update", ownID.Hex(), mock.Anything)
     })
-}
\ No newline at end of file
+}
+
+func TestGetAllTrashService_ScopedToCaller(t *testing.T) {
+    app, pekerjaanRepo, _ := setupPekerjaanApp(userPrincipal)
+    pekerjaanRepo.On("FindTrash", "1001").Return([]*model.Trash{}, nil)
+
+    req := httptest.NewRequest("GET", "/api/trash", nil)
+    resp, _ := app.Test(req)
+
+    assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+    pekerjaanRepo.AssertExpectations(t)
+}
@@ -10,6 +10,9 @@ package main
 
 import (
 	. "Mongo/domain/config"
+	"Mongo/domain/mailer"
+	"Mongo/domain/middleware"
+	"Mongo/domain/model"
 	"Mongo/domain/repository"
 	"Mongo/domain/routes"
 	"Mongo/domain/service"
@@ -35,6 +38,14 @@ func main() {
 		BodyLimit: 10 * 1024 * 1024,
 	})
 
+	keys, err := LoadKeySet()
+	if err != nil {
+		log.Fatalf("Gagal memuat kunci JWT: %v", err)
+	}
+	SetKeySet(keys)
+
+	routes.WellKnownRoutes(app)
+
 	api := app.Group("/api")
 
 	api.Get("/swagger/*", fiberSwagger.WrapHandler)
@@ -42,20 +53,81 @@ func main() {
 	api.Use(cors.New())
 	api.Use(logger.New())
 
-	api.Static("/uploads", "./uploads")
+	// File unggahan tidak disajikan statis; unduh lewat /api/Files/:id/download
+	// agar hanya pemilik dan peninjau klaim yang bisa membacanya
 
 	userRepo := repository.NewUserRepository(client)
-	authService := service.NewAuthService(userRepo)
+	if err := userRepo.EnsureIndexes(); err != nil {
+		log.Fatalf("Gagal menyiapkan index users (cek email ganda): %v", err)
+	}
+	refreshRepo := repository.NewRefreshTokenRepository(client)
+	alumniRepo := repository.NewAlumniRepository()
+	if err := alumniRepo.EnsureIndexes(); err != nil {
+		log.Fatalf("Gagal menyiapkan index alumni (cek NIM ganda): %v", err)
+	}
+	masterDataRepo := repository.NewMasterDataRepository(client)
+	if err := masterDataRepo.EnsureIndexes(); err != nil {
+		log.Fatalf("Gagal menyiapkan index master data: %v", err)
+	}
+	oneTimeTokenRepo := repository.NewOneTimeTokenRepository(client)
+	sessionRepo := repository.NewSessionRepository(client)
+	sessionManager := service.NewSessionManager(sessionRepo, refreshRepo)
+
+	mail, err := mailer.New(GetMailConfig())
+	if err != nil {
+		log.Fatalf("Gagal menyiapkan mailer: %v", err)
+	}
+	verificationService := service.NewVerificationService(userRepo, oneTimeTokenRepo, mail)
+	passwordService := service.NewPasswordService(userRepo, oneTimeTokenRepo, sessionManager, mail)
+
+	loginGuard := service.NewLoginGuard(repository.NewLoginAttemptRepository(client), GetLoginThrottle())
+	oidcClient := service.NewOIDCClient(repository.NewOIDCStateRepository(client), GetOIDCConfig())
+	twoFactorGuard := service.NewTwoFactorGuard(repository.NewTwoFactorRepository(client), oneTimeTokenRepo, GetTwoFactorConfig())
+	authService := service.NewAuthService(userRepo, refreshRepo, alumniRepo, verificationService, loginGuard, twoFactorGuard, sessionManager, oidcClient)
+	inviteService := service.NewInviteService(repository.NewInviteRepository(client), userRepo)
+
+	roleRepo := repository.NewRoleRepository(client)
+	if err := roleRepo.EnsureDefaults(model.DefaultRoles()); err != nil {
+		log.Fatalf("Gagal menyiapkan role bawaan: %v", err)
+	}
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	auditRepo := repository.NewAuditRepository(client)
+	userAdminService := service.NewUserAdminService(userRepo, roleRepo, sessionManager, oneTimeTokenRepo, auditRepo, alumniRepo)
+	profileService := service.NewProfileService(userRepo, sessionManager, verificationService)
+	sessionService := service.NewSessionService(sessionManager)
+	apiKeyRepo := repository.NewAPIKeyRepository(client)
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorGuard)
+
+	authDeps := &middleware.AuthDeps{
+		Users:    userRepo,
+		Roles:    roleRepo,
+		Sessions: sessionRepo,
+		APIKeys:  apiKeyRepo,
+		Audit:    auditRepo,
+	}
 
 	mongoDatabase := DB.Database("alumni_management_db")
 	fileRepo := repository.NewUploadsRepository(mongoDatabase)
 	UploadsService := service.NewUploadsService(fileRepo, "./uploads")
 
-	routes.SetupFileRoutes(api, UploadsService)
+	routes.SetupFileRoutes(api, authDeps, UploadsService)
 	routes.AuthRoutes(api, authService)
-	routes.Alumni(api, &userRepo, &service.AlumniService{})
-	routes.PekerjaanAlumni(api, &userRepo)
-	routes.UserRoutes(api)
+	routes.LoginAttemptRoutes(api, authDeps, authService)
+	routes.PasswordRoutes(api, passwordService)
+	routes.VerificationRoutes(api, authDeps, verificationService)
+	routes.InviteRoutes(api, authDeps, inviteService)
+	routes.RoleRoutes(api, authDeps, roleService)
+	routes.UserAdminRoutes(api, authDeps, userAdminService)
+	routes.ImpersonationRoutes(api, authDeps, service.NewImpersonationService(userRepo, roleRepo, alumniRepo, auditRepo))
+	routes.APIKeyRoutes(api, authDeps, service.NewAPIKeyService(apiKeyRepo))
+	routes.ProfileRoutes(api, authDeps, profileService, twoFactorService, sessionService)
+	routes.Alumni(api, authDeps, service.NewAlumniService(alumniRepo, masterDataRepo))
+	routes.AlumniImportRoutes(api, authDeps, service.NewAlumniImportService(alumniRepo, masterDataRepo))
+	routes.MasterDataRoutes(api, authDeps, service.NewMasterDataService(masterDataRepo, alumniRepo))
+	routes.AlumniClaimRoutes(api, authDeps, service.NewAlumniClaimService(repository.NewAlumniClaimRepository(client), alumniRepo, userRepo, fileRepo))
+	pekerjaanService := service.NewPekerjaanAlumniService(repository.NewPekerjaanAlumniRepository(), service.NewOwnershipPolicy(alumniRepo))
+	routes.PekerjaanAlumni(api, authDeps, pekerjaanService)
+	routes.UserRoutes(api, authDeps)
 
 	port := "3000"
 	log.Printf("🚀 Server running on http://localhost:%s", port)